@@ -1,6 +1,7 @@
 package cluster
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -21,21 +22,39 @@ const (
 
 	retainSnapshotCount = 2
 	raftTimeout         = 10 * time.Second
+
+	// The delay before closing a group replaced by one with different
+	// servers, which is long enough for the in-flight requests to complete.
+	closeReplacedGroupDelay = time.Minute
 )
 
 var (
 	// ErrNotLeader is returned when a node attempts to execute a leader-only
 	// operation.
 	ErrNotLeader = errors.New("not leader")
+
+	// ErrNoLeader is returned when there is no known leader.
+	ErrNoLeader = errors.New("no leader")
 )
 
 type command struct {
-	Op          string    `json:"op,omitempty"`
-	GroupID     int       `json:"group_id,omitempty"`
-	Servers     []Server  `json:"servers,omitempty"`
-	StartSlotID int       `json:"start_slot_id,omitempty"`
-	StopSlotID  int       `json:"stop_slot_id,omitempty"`
-	SlotState   SlotState `json:"slot_state,omitempty"`
+	Op          string        `json:"op,omitempty"`
+	GroupID     int           `json:"group_id,omitempty"`
+	Servers     []Server      `json:"servers,omitempty"`
+	Options     *GroupOptions `json:"options,omitempty"`
+	StartSlotID int           `json:"start_slot_id,omitempty"`
+	StopSlotID  int           `json:"stop_slot_id,omitempty"`
+	SlotState   SlotState     `json:"slot_state,omitempty"`
+	NodeAddr    string        `json:"node_addr,omitempty"`
+	APIAddr     string        `json:"api_addr,omitempty"`
+
+	// The fields of the migration jobs.
+	JobID    string         `json:"job_id,omitempty"`
+	Phase    MigrationPhase `json:"phase,omitempty"`
+	Error    string         `json:"error,omitempty"`
+	Time     int64          `json:"time,omitempty"`
+	After    string         `json:"after,omitempty"`
+	Throttle time.Duration  `json:"throttle,omitempty"`
 }
 
 // Cluster is a cluster metadata manager, which manages the cluster
@@ -49,14 +68,28 @@ type Cluster struct {
 	name  string
 	slots map[int]*Slot
 
-	newGroup NewGroup
-	mu       sync.RWMutex
-	groups   map[int]Group
+	// The lock held by the FSM while applying a command or restoring a
+	// snapshot, under whose read lock a consistent view of the metadata
+	// (e.g. both the groups and the slots) can be taken.
+	applyMu sync.RWMutex
+
+	newGroup   NewGroup
+	mu         sync.RWMutex
+	groups     map[int]Group
+	apiAddrs   map[string]string     // The API addresses by the Raft addresses
+	migrations map[string]*migration // The migration jobs
+
+	runningMu sync.Mutex
+	running   map[string]bool // The migration jobs and backfills running on this node
 
 	// The consensus mechanism
 	raft     *raft.Raft
 	raftBind string
 	raftDir  string
+	raftAddr string // The advertised Raft address
+	apiAddr  string
+	stopC    chan struct{}
+	stopOnce sync.Once
 }
 
 // NewCluster creates a Cluster with the given configurations.
@@ -66,15 +99,27 @@ func NewCluster(name string, newGroup NewGroup, raftBind, raftDir string) *Clust
 		slots[i] = NewSlot(i, SlotStateOffline, nil, nil)
 	}
 	return &Cluster{
-		name:     name,
-		slots:    slots,
-		newGroup: newGroup,
-		groups:   make(map[int]Group),
-		raftBind: raftBind,
-		raftDir:  raftDir,
+		name:       name,
+		slots:      slots,
+		newGroup:   newGroup,
+		groups:     make(map[int]Group),
+		apiAddrs:   make(map[string]string),
+		migrations: make(map[string]*migration),
+		running:    make(map[string]bool),
+		raftBind:   raftBind,
+		raftDir:    raftDir,
+		stopC:      make(chan struct{}),
 	}
 }
 
+// SetAPIAddr sets the address of the API (e.g. the proxy), which serves
+// the commands on this node. The address is registered in the cluster
+// whenever this node becomes the leader, to let the other nodes forward
+// the commands to it (see LeaderAPIAddr). It must be called before Open.
+func (c *Cluster) SetAPIAddr(addr string) {
+	c.apiAddr = addr
+}
+
 // Open opens the cluster. If enableSingle is set, and there are no existing peers,
 // then this node becomes the first node, and therefore leader, of the cluster.
 // localID should be the server identifier for this node.
@@ -111,6 +156,9 @@ func (c *Cluster) Open(enableSingle bool, localID string) error {
 		return fmt.Errorf("new raft: %s", err)
 	}
 	c.raft = ra
+	c.raftAddr = string(transport.LocalAddr())
+
+	go c.watchLeadership()
 
 	if enableSingle {
 		configuration := raft.Configuration{
@@ -129,6 +177,7 @@ func (c *Cluster) Open(enableSingle bool, localID string) error {
 
 // Close closes the cluster. If wait is true, waits for a graceful shutdown.
 func (c *Cluster) Close(wait bool) error {
+	c.stopOnce.Do(func() { close(c.stopC) })
 	f := c.raft.Shutdown()
 	if wait {
 		if err := f.Error(); err != nil {
@@ -156,6 +205,129 @@ func (c *Cluster) Join(nodeID, addr string) error {
 	return nil
 }
 
+// AddNonVoter adds a node, identified by nodeID and located at addr, to
+// this cluster as a non-voter, which receives the log entries but does not
+// vote. The node must be ready to respond to Raft communications at that
+// address.
+func (c *Cluster) AddNonVoter(nodeID, addr string) error {
+	log.Printf("received request to add non-voter node %s at %s", nodeID, addr)
+	if c.raft.State() != raft.Leader {
+		return ErrNotLeader
+	}
+
+	f := c.raft.AddNonvoter(raft.ServerID(nodeID), raft.ServerAddress(addr), 0, 0)
+	if err := f.Error(); err != nil {
+		return err
+	}
+	log.Printf("non-voter node %s at %s added successfully", nodeID, addr)
+
+	return nil
+}
+
+// RemoveNode removes the node identified by nodeID from this cluster.
+func (c *Cluster) RemoveNode(nodeID string) error {
+	log.Printf("received request to remove node %s", nodeID)
+	if c.raft.State() != raft.Leader {
+		return ErrNotLeader
+	}
+
+	f := c.raft.RemoveServer(raft.ServerID(nodeID), 0, 0)
+	if err := f.Error(); err != nil {
+		return err
+	}
+	log.Printf("node %s removed successfully", nodeID)
+
+	return nil
+}
+
+// Node is a node of the cluster.
+type Node struct {
+	ID    string
+	Addr  string
+	Voter bool
+	// Leader indicates that the node is the leader known by this node.
+	Leader bool
+}
+
+// Nodes returns all the nodes in the latest configuration of this cluster.
+func (c *Cluster) Nodes() ([]Node, error) {
+	f := c.raft.GetConfiguration()
+	if err := f.Error(); err != nil {
+		return nil, err
+	}
+
+	leader := c.raft.Leader()
+	servers := f.Configuration().Servers
+	nodes := make([]Node, len(servers))
+	for i, s := range servers {
+		nodes[i] = Node{
+			ID:     string(s.ID),
+			Addr:   string(s.Address),
+			Voter:  s.Suffrage == raft.Voter,
+			Leader: s.Address == leader,
+		}
+	}
+	return nodes, nil
+}
+
+// watchLeadership takes over the duties of the leader every time this node
+// becomes the leader, which registers the API address of this node, and
+// resumes the unfinished migration jobs.
+func (c *Cluster) watchLeadership() {
+	for {
+		select {
+		case isLeader := <-c.raft.LeaderCh():
+			if !isLeader {
+				continue
+			}
+			if c.apiAddr != "" {
+				c.registerAPIAddr()
+			}
+			c.resumeMigrations()
+			c.resumeBackfills()
+		case <-c.stopC:
+			return
+		}
+	}
+}
+
+// registerAPIAddr registers the API address of this node.
+func (c *Cluster) registerAPIAddr() {
+	err := c.apply(
+		&command{
+			Op:       "set_api_addr",
+			NodeAddr: c.raftAddr,
+			APIAddr:  c.apiAddr,
+		},
+		false,
+	)
+	if err != nil {
+		log.Printf("failed to register API address %s: %v", c.apiAddr, err)
+	}
+}
+
+// LeaderAPIAddr returns the API address of the current leader, to which
+// the leader-only commands can be forwarded.
+func (c *Cluster) LeaderAPIAddr() (string, error) {
+	leader := string(c.raft.Leader())
+	if leader == "" {
+		return "", ErrNoLeader
+	}
+
+	c.mu.RLock()
+	addr, ok := c.apiAddrs[leader]
+	c.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("API address of leader %s not found", leader)
+	}
+	return addr, nil
+}
+
+// IsLeader reports whether this node is the leader.
+func (c *Cluster) IsLeader() bool {
+	return c.raft.State() == raft.Leader
+}
+
 func (c *Cluster) Name() string { return c.name }
 
 // Slots returns the slots with the given slot ids. If no id is given,
@@ -177,13 +349,18 @@ func (c *Cluster) Slots(ids ...int) map[int]*Slot {
 // Groups returns the groups with the given group ids. If no id is given,
 // it will return all existing groups in the cluster.
 //
-// The groups are returned as a map, callers must not modify the map.
+// The groups are returned as a new map, which is a snapshot of the groups
+// and thus can be iterated while the cluster is changing.
 func (c *Cluster) Groups(ids ...int) map[int]Group {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	if len(ids) == 0 {
-		return c.groups
+		groups := make(map[int]Group, len(c.groups))
+		for id, g := range c.groups {
+			groups[id] = g
+		}
+		return groups
 	}
 
 	parts := make(map[int]Group, len(ids))
@@ -204,10 +381,16 @@ func (c *Cluster) apply(cmd *command, wait bool) error {
 	}
 
 	af := c.raft.Apply(b, raftTimeout)
-	err = af.Error()
-	if err != nil || !wait {
+	if err := af.Error(); err != nil {
+		return err
+	}
+	// The command has been rejected by the FSM.
+	if err, ok := af.Response().(error); ok {
 		return err
 	}
+	if !wait {
+		return nil
+	}
 
 	// Waits until the command has been applied to the FSM of all nodes.
 	f := c.raft.Barrier(raftTimeout)
@@ -215,11 +398,34 @@ func (c *Cluster) apply(cmd *command, wait bool) error {
 }
 
 func (c *Cluster) AddGroup(groupID int, servers ...Server) error {
+	return c.AddGroupWithOptions(groupID, GroupOptions{}, servers...)
+}
+
+// AddGroupWithOptions adds a group, whose settings are opts, and which
+// keeps the settings when its servers change. The group is built once
+// beforehand, so that the one that cannot be built, or cannot meet its
+// write quorum, is rejected before the command is committed.
+func (c *Cluster) AddGroupWithOptions(groupID int, opts GroupOptions, servers ...Server) error {
+	if opts.HedgePercentile < 0 || opts.HedgePercentile > 100 {
+		return fmt.Errorf("invalid hedge percentile: %v", opts.HedgePercentile)
+	}
+
+	g, err := c.newGroup(groupID, servers, nil, opts)
+	if err != nil {
+		return fmt.Errorf("invalid group %d: %s", groupID, err)
+	}
+	quorum := g.WriteQuorum()
+	closeGroup(g)
+	if len(servers) == 0 || len(servers) < quorum {
+		return fmt.Errorf("cannot add group %d with %d servers, whose write quorum is %d", groupID, len(servers), quorum)
+	}
+
 	return c.apply(
 		&command{
 			Op:      "add_group",
 			GroupID: groupID,
 			Servers: servers,
+			Options: &opts,
 		},
 		false,
 	)
@@ -235,8 +441,129 @@ func (c *Cluster) DelGroup(groupID int) error {
 	)
 }
 
+// AddServerToGroup adds server to the group identified by groupID, without
+// taking any slot offline. The server joins the group as a joining server,
+// which receives the writes but does not count toward the write quorum,
+// until all the slots of the group have been backfilled to it from the
+// other servers.
+//
+// The backfill runs in the background on the leader, and AddServerToGroup
+// returns once the server has joined. The server is promoted once the
+// backfill is done, which can be observed by Groups. If the backfill fails,
+// the server is left joining, and calling this again resumes the backfill,
+// as does the new leader if the leader changes.
+func (c *Cluster) AddServerToGroup(groupID int, server Server) error {
+	err := c.apply(
+		&command{
+			Op:      "add_server_to_group",
+			GroupID: groupID,
+			Servers: []Server{server},
+		},
+		true,
+	)
+	if err != nil {
+		return err
+	}
+
+	c.backfillAsync(groupID, server)
+	return nil
+}
+
+// resumeBackfills resumes the backfills of all the joining servers, which
+// is called once this node becomes the leader.
+func (c *Cluster) resumeBackfills() {
+	for groupID, g := range c.Groups() {
+		for _, server := range g.Joining() {
+			log.Printf("resuming backfill of server %s in group %d", server, groupID)
+			c.backfillAsync(groupID, server)
+		}
+	}
+}
+
+// backfillAsync runs the backfill of the joining server in the background,
+// unless it is already running on this node.
+func (c *Cluster) backfillAsync(groupID int, server Server) {
+	key := fmt.Sprintf("backfill/%d/%s", groupID, server)
+
+	c.runningMu.Lock()
+	defer c.runningMu.Unlock()
+
+	if c.running[key] {
+		return
+	}
+	c.running[key] = true
+
+	go func() {
+		if err := c.backfill(groupID, server); err != nil {
+			log.Printf("failed to backfill server %s in group %d: %v", server, groupID, err)
+		}
+
+		c.runningMu.Lock()
+		delete(c.running, key)
+		c.runningMu.Unlock()
+	}()
+}
+
+// backfill backfills all the slots of the group identified by groupID to
+// the joining server, and then promotes the server. It stops once this
+// node is no longer the leader, or the server is no longer joining.
+func (c *Cluster) backfill(groupID int, server Server) error {
+	for slotID := 0; slotID < SlotNum; slotID++ {
+		select {
+		case <-c.stopC:
+			return fmt.Errorf("cluster closed")
+		default:
+		}
+		if !c.IsLeader() {
+			return ErrNotLeader
+		}
+
+		// Get the group each time, which may have been replaced (e.g. by
+		// adding another server) during the backfill.
+		g := c.Groups(groupID)[groupID]
+		if g == nil {
+			return fmt.Errorf("group %d not found", groupID)
+		}
+		if !containsServer(g.Joining(), server) {
+			return fmt.Errorf("server %s is not joining group %d", server, groupID)
+		}
+
+		if sg := c.slots[slotID].Group(); sg == nil || sg.ID() != groupID {
+			continue
+		}
+		if err := g.Backfill(slotID, server); err != nil {
+			return fmt.Errorf("failed to backfill slot %d: %s", slotID, err)
+		}
+	}
+
+	// Now all the slots have been backfilled, let the server count toward
+	// the write quorum, and blocks until this operation has been applied to
+	// the FSM.
+	return c.apply(
+		&command{
+			Op:      "promote_server_in_group",
+			GroupID: groupID,
+			Servers: []Server{server},
+		},
+		true,
+	)
+}
+
+// RemoveServerFromGroup removes server from the group identified by groupID,
+// without taking any slot offline. The data on the server is left as is.
+func (c *Cluster) RemoveServerFromGroup(groupID int, server Server) error {
+	return c.apply(
+		&command{
+			Op:      "remove_server_from_group",
+			GroupID: groupID,
+			Servers: []Server{server},
+		},
+		true,
+	)
+}
+
 func (c *Cluster) validateSlotID(slotID int) error {
-	if slotID < 0 && slotID >= SlotNum {
+	if slotID < 0 || slotID >= SlotNum {
 		return fmt.Errorf("slot id %d is not in [0, %d)", slotID, SlotNum)
 	}
 	return nil
@@ -262,7 +589,7 @@ func (c *Cluster) AssignSlots(toGroupID, startSlotID, stopSlotID int) error {
 	)
 }
 
-func (c *Cluster) MigrateSlots(toGroupID, startSlotID, stopSlotID int) (err error) {
+func (c *Cluster) MigrateSlots(toGroupID, startSlotID, stopSlotID int) error {
 	if err := c.validateSlotID(startSlotID); err != nil {
 		return err
 	}
@@ -272,70 +599,103 @@ func (c *Cluster) MigrateSlots(toGroupID, startSlotID, stopSlotID int) (err erro
 	}
 
 	for slotID := startSlotID; slotID <= stopSlotID; slotID++ {
-		slot := c.slots[slotID]
-
-		switch slot.State() {
-		case SlotStateOffline:
-			return fmt.Errorf("slot %d is offline", slotID)
-		case SlotStateInMigration:
-			return fmt.Errorf("slot %d is in migration", slotID)
-		case SlotStateOnline:
-			if slot.Group().ID() == toGroupID {
-				// Nothing happens if the slot already belongs to toGroupID.
-				continue
-			}
+		if _, err := c.migrateSlot(toGroupID, slotID, ""); err != nil {
+			return err
+		}
+	}
 
-			// Change the slot state to pre-migration, and blocks until
-			// this operation has been applied to the FSM.
-			err = c.apply(
-				&command{
-					Op:          "change_slot_state",
-					GroupID:     toGroupID,
-					StartSlotID: slotID,
-					SlotState:   SlotStatePreMigration,
-				},
-				true,
-			)
-			if err != nil {
-				return err
-			}
-			// Now the slot state (within each node) is pre-migration, and
-			// blocks until this operation has been applied to the FSM.
-			err = c.apply(
-				&command{
-					Op:          "change_slot_state",
-					GroupID:     toGroupID,
-					StartSlotID: slotID,
-					SlotState:   SlotStateInMigration,
-				},
-				true,
-			)
-			if err != nil {
-				return err
-			}
+	return nil
+}
 
-			from := slot.FromGroup()
-			to := slot.Group()
-			from.MigrateSlot(to, slotID)
-
-			// Now the slot has been migrated, change the slot state to online,
-			// and blocks until this operation has been applied to the FSM.
-			err = c.apply(
-				&command{
-					Op:          "change_slot_state",
-					GroupID:     toGroupID,
-					StartSlotID: slotID,
-					SlotState:   SlotStateOnline,
-				},
-				true,
-			)
-			if err != nil {
-				return err
-			}
+// migrateSlot migrates the slot identified by slotID to the group identified
+// by toGroupID, and reports whether the slot already belongs to toGroupID,
+// in which case nothing happens. The state changes are recorded in the
+// migration job identified by jobID, if it is not empty.
+//
+// A slot in migration to toGroupID, which may be left by a failed
+// migration, is resumed from its current state.
+func (c *Cluster) migrateSlot(toGroupID, slotID int, jobID string) (skipped bool, err error) {
+	slot := c.slots[slotID]
+
+	switch slot.State() {
+	case SlotStateOffline:
+		return false, fmt.Errorf("slot %d is offline", slotID)
+	case SlotStateOnline:
+		if slot.Group().ID() == toGroupID {
+			// Nothing happens if the slot already belongs to toGroupID.
+			return true, nil
+		}
+
+		// Change the slot state to pre-migration, and blocks until
+		// this operation has been applied to the FSM.
+		err = c.apply(
+			&command{
+				Op:          "change_slot_state",
+				GroupID:     toGroupID,
+				StartSlotID: slotID,
+				SlotState:   SlotStatePreMigration,
+				JobID:       jobID,
+			},
+			true,
+		)
+		if err != nil {
+			return false, err
+		}
+		// Go to the pre-migration case.
+		fallthrough
+	case SlotStatePreMigration:
+		if g := slot.Group(); g.ID() != toGroupID {
+			return false, fmt.Errorf("slot %d is in migration to group %d", slotID, g.ID())
+		}
+
+		// Now the slot state (within each node) is pre-migration, and
+		// blocks until this operation has been applied to the FSM.
+		err = c.apply(
+			&command{
+				Op:          "change_slot_state",
+				GroupID:     toGroupID,
+				StartSlotID: slotID,
+				SlotState:   SlotStateInMigration,
+				JobID:       jobID,
+			},
+			true,
+		)
+		if err != nil {
+			return false, err
+		}
+		// Go to the in-migration case.
+		fallthrough
+	case SlotStateInMigration:
+		if g := slot.Group(); g.ID() != toGroupID {
+			return false, fmt.Errorf("slot %d is in migration to group %d", slotID, g.ID())
+		}
+
+		from := slot.FromGroup()
+		to := slot.Group()
+		// If the data movement fails, the slot is left in migration,
+		// within which the keys are still migrated on demand.
+		if err := from.MigrateSlot(to, slotID); err != nil {
+			return false, fmt.Errorf("failed to migrate slot %d: %s", slotID, err)
+		}
+
+		// Now the slot has been migrated, change the slot state to online,
+		// and blocks until this operation has been applied to the FSM.
+		err = c.apply(
+			&command{
+				Op:          "change_slot_state",
+				GroupID:     toGroupID,
+				StartSlotID: slotID,
+				SlotState:   SlotStateOnline,
+				JobID:       jobID,
+			},
+			true,
+		)
+		if err != nil {
+			return false, err
 		}
 	}
 
-	return nil
+	return false, nil
 }
 
 // getSlotID returns the slot id to which the given key belongs.
@@ -343,12 +703,13 @@ func (c *Cluster) getSlotID(key string) int {
 	return int(crc32.ChecksumIEEE([]byte(key)) % SlotNum)
 }
 
-// MapToSlot maps the given key to a slot, to which the key belongs.
-func (c *Cluster) MapToSlot(key string) (*Slot, error) {
+// MapToSlot maps the given key to a slot, to which the key belongs. The
+// wait for the slot in pre-migration and the key migration are bound to ctx.
+func (c *Cluster) MapToSlot(ctx context.Context, key string) (*Slot, error) {
 	slotID := c.getSlotID(key)
 	slot := c.slots[slotID]
 
-	g, from, err := slot.GetWorkingGroups()
+	g, from, err := slot.GetWorkingGroups(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -357,7 +718,7 @@ func (c *Cluster) MapToSlot(key string) (*Slot, error) {
 	// trigger a key migration first to ensure that the key has been
 	// migrated from the group from to the group g.
 	if from != nil {
-		if err := from.MigrateKeys(g, slotID, key); err != nil {
+		if err := from.MigrateKeys(ctx, g, slotID, key); err != nil {
 			return nil, err
 		}
 	}
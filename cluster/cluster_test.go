@@ -1,10 +1,13 @@
 package cluster_test
 
 import (
+	"context"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"reflect"
 	"strconv"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -15,16 +18,46 @@ import (
 type group struct {
 	id      int
 	servers []cluster.Server
+	joining []cluster.Server
+	opts    cluster.GroupOptions
+	closed  int32
 }
 
-func newGroup(id int, servers []cluster.Server) cluster.Group {
-	return &group{id: id, servers: servers}
+func newGroup(id int, servers, joining []cluster.Server, opts cluster.GroupOptions) (cluster.Group, error) {
+	for _, s := range servers {
+		if s == "invalid" {
+			return nil, fmt.Errorf("%s is invalid", s)
+		}
+	}
+	return &group{id: id, servers: servers, joining: joining, opts: opts}, nil
 }
 
-func (g *group) ID() int                                                        { return g.id }
-func (g *group) Servers() []cluster.Server                                      { return g.servers }
-func (g *group) MigrateKeys(to cluster.Group, slotID int, keys ...string) error { return nil }
-func (g *group) MigrateSlot(to cluster.Group, slotID int) error                 { return nil }
+func (g *group) ID() int                       { return g.id }
+func (g *group) Servers() []cluster.Server     { return g.servers }
+func (g *group) Joining() []cluster.Server     { return g.joining }
+func (g *group) WriteQuorum() int              { return 2 }
+func (g *group) Options() cluster.GroupOptions { return g.opts }
+func (g *group) MigrateKeys(ctx context.Context, to cluster.Group, slotID int, keys ...string) error {
+	return nil
+}
+func (g *group) MigrateSlot(to cluster.Group, slotID int) error {
+	for _, s := range to.Servers() {
+		switch s {
+		case "broken":
+			return fmt.Errorf("%s is broken", s)
+		case "slow":
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+	return nil
+}
+func (g *group) Backfill(slotID int, server cluster.Server) error {
+	if server == "broken" {
+		return fmt.Errorf("%s is broken", server)
+	}
+	return nil
+}
+func (g *group) Close() error { atomic.StoreInt32(&g.closed, 1); return nil }
 
 func newAndOpenClusters(t *testing.T, num int) ([]*cluster.Cluster, func()) {
 	if num <= 0 {
@@ -38,6 +71,7 @@ func newAndOpenClusters(t *testing.T, num int) ([]*cluster.Cluster, func()) {
 		iStr := strconv.Itoa(i)
 		tmpDirs[i], _ = ioutil.TempDir("", "store"+iStr)
 		clusters[i] = cluster.NewCluster("test"+iStr, newGroup, "127.0.0.1:1200"+iStr, tmpDirs[i])
+		clusters[i].SetAPIAddr("127.0.0.1:5005" + iStr)
 		if err := clusters[i].Open(i == 0, "node"+iStr); err != nil {
 			t.Fatalf("failed to open cluster: %s", err)
 		}
@@ -65,6 +99,81 @@ func newAndOpenClusters(t *testing.T, num int) ([]*cluster.Cluster, func()) {
 	return clusters, cleanup
 }
 
+func TestCluster_Nodes(t *testing.T) {
+	clusters, cleanup := newAndOpenClusters(t, 2)
+	defer cleanup()
+	c1 := clusters[0]
+
+	tmpDir, _ := ioutil.TempDir("", "store2")
+	defer os.RemoveAll(tmpDir)
+	c3 := cluster.NewCluster("test2", newGroup, "127.0.0.1:12002", tmpDir)
+	if err := c3.Open(false, "node2"); err != nil {
+		t.Fatalf("failed to open cluster: %s", err)
+	}
+	defer c3.Close(true)
+
+	cases := []struct {
+		action  func() error
+		wantErr error
+		want    []cluster.Node
+	}{
+		{
+			action: func() error { return c1.AddNonVoter("node2", "127.0.0.1:12002") },
+			want: []cluster.Node{
+				{ID: "node0", Addr: "127.0.0.1:12000", Voter: true, Leader: true},
+				{ID: "node1", Addr: "127.0.0.1:12001", Voter: true},
+				{ID: "node2", Addr: "127.0.0.1:12002"},
+			},
+		},
+		{
+			action: func() error { return c1.RemoveNode("node1") },
+			want: []cluster.Node{
+				{ID: "node0", Addr: "127.0.0.1:12000", Voter: true, Leader: true},
+				{ID: "node2", Addr: "127.0.0.1:12002"},
+			},
+		},
+		{
+			action:  func() error { return c3.RemoveNode("node0") },
+			wantErr: cluster.ErrNotLeader,
+			want: []cluster.Node{
+				{ID: "node0", Addr: "127.0.0.1:12000", Voter: true, Leader: true},
+				{ID: "node2", Addr: "127.0.0.1:12002"},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run("", func(t *testing.T) {
+			if err := c.action(); err != c.wantErr {
+				t.Fatalf("err: got(%+v) != want(%+v)", err, c.wantErr)
+			}
+			nodes, err := c1.Nodes()
+			if err != nil {
+				t.Fatalf("err: got(%+v) != want(<nil>)", err)
+			}
+			if !reflect.DeepEqual(nodes, c.want) {
+				t.Errorf("nodes: got(%+v) != want(%+v)", nodes, c.want)
+			}
+		})
+	}
+}
+
+func TestCluster_LeaderAPIAddr(t *testing.T) {
+	clusters, cleanup := newAndOpenClusters(t, 2)
+	defer cleanup()
+	// Wait for the API address of the leader to be registered.
+	time.Sleep(500 * time.Millisecond)
+
+	for _, c := range clusters {
+		addr, err := c.LeaderAPIAddr()
+		if err != nil {
+			t.Fatalf("err: got(%+v) != want(<nil>)", err)
+		}
+		if want := "127.0.0.1:50050"; addr != want {
+			t.Errorf("addr: got(%+v) != want(%+v)", addr, want)
+		}
+	}
+}
+
 func TestEmptyCluster(t *testing.T) {
 	clusters, cleanup := newAndOpenClusters(t, 1)
 	defer cleanup()
@@ -89,12 +198,24 @@ func TestCluster_AddGroup(t *testing.T) {
 	c1 := clusters[0]
 	c2 := clusters[1]
 
+	// The groups that cannot be built, or cannot meet the write quorum,
+	// are rejected before being committed.
+	for _, servers := range [][]cluster.Server{
+		{"server1", "invalid"},
+		{"server1"},
+		nil,
+	} {
+		if err := c1.AddGroup(1, servers...); err == nil {
+			t.Errorf("err: got(%+v) != want(<error>)", err)
+		}
+	}
+
 	c1.AddGroup(1, "server1", "server2")
 	// Wait for committed log entry to be applied.
 	time.Sleep(500 * time.Millisecond)
 
 	g1 := c1.Groups()
-	if len(g1) == 0 {
+	if len(g1) != 1 {
 		t.Errorf("g1(%v) is not added", g1)
 	}
 
@@ -104,6 +225,38 @@ func TestCluster_AddGroup(t *testing.T) {
 	}
 }
 
+func TestCluster_AddGroupWithOptions(t *testing.T) {
+	clusters, cleanup := newAndOpenClusters(t, 2)
+	defer cleanup()
+	c1 := clusters[0]
+	c2 := clusters[1]
+
+	if err := c1.AddGroupWithOptions(1, cluster.GroupOptions{HedgePercentile: 101}, "server1"); err == nil {
+		t.Errorf("err: got(%+v) != want(<error>)", err)
+	}
+
+	opts := cluster.GroupOptions{HedgePercentile: 95}
+	if err := c1.AddGroupWithOptions(1, opts, "server1", "server2"); err != nil {
+		t.Fatalf("err: got(%+v) != want(<nil>)", err)
+	}
+	c1.AssignSlots(1, 0, cluster.SlotNum-1)
+	// The options are kept when the servers change.
+	c1.AddServerToGroup(1, "server3")
+	// Wait for committed log entry to be applied.
+	time.Sleep(500 * time.Millisecond)
+
+	for _, c := range []*cluster.Cluster{c1, c2} {
+		g := c.Groups(1)[1]
+		want := []cluster.Server{"server1", "server2", "server3"}
+		if !reflect.DeepEqual(g.Servers(), want) {
+			t.Errorf("Servers: got(%+v) != want(%+v)", g.Servers(), want)
+		}
+		if g.Options() != opts {
+			t.Errorf("Options: got(%+v) != want(%+v)", g.Options(), opts)
+		}
+	}
+}
+
 func TestCluster_DelGroup(t *testing.T) {
 	clusters, cleanup := newAndOpenClusters(t, 2)
 	defer cleanup()
@@ -111,6 +264,10 @@ func TestCluster_DelGroup(t *testing.T) {
 	c2 := clusters[1]
 
 	c1.AddGroup(1, "server1", "server2")
+	// Wait for committed log entry to be applied.
+	time.Sleep(500 * time.Millisecond)
+	g := c1.Groups(1)[1].(*group)
+
 	c1.DelGroup(1)
 	// Wait for committed log entry to be applied.
 	time.Sleep(500 * time.Millisecond)
@@ -119,6 +276,9 @@ func TestCluster_DelGroup(t *testing.T) {
 	if len(g1) != 0 {
 		t.Errorf("g1(%v) is not deleted", g1)
 	}
+	if atomic.LoadInt32(&g.closed) == 0 {
+		t.Errorf("group %d is not closed", g.id)
+	}
 
 	g2 := c2.Groups()
 	if !reflect.DeepEqual(g1, g2) {
@@ -126,6 +286,172 @@ func TestCluster_DelGroup(t *testing.T) {
 	}
 }
 
+func TestCluster_AddServerToGroup(t *testing.T) {
+	clusters, cleanup := newAndOpenClusters(t, 2)
+	defer cleanup()
+	c1 := clusters[0]
+	c2 := clusters[1]
+
+	c1.AddGroup(1, "server1", "server2")
+	c1.AssignSlots(1, 0, cluster.SlotNum-1)
+	// Wait for committed log entry to be applied.
+	time.Sleep(500 * time.Millisecond)
+
+	cases := []struct {
+		server      cluster.Server
+		wantErr     bool
+		wantServers []cluster.Server
+		wantJoining []cluster.Server
+	}{
+		{
+			server:      "server3",
+			wantServers: []cluster.Server{"server1", "server2", "server3"},
+		},
+		{
+			server:      "server3",
+			wantErr:     true,
+			wantServers: []cluster.Server{"server1", "server2", "server3"},
+		},
+		{
+			// The backfill fails in the background.
+			server:      "broken",
+			wantServers: []cluster.Server{"server1", "server2", "server3", "broken"},
+			wantJoining: []cluster.Server{"broken"},
+		},
+	}
+	for _, c := range cases {
+		t.Run("", func(t *testing.T) {
+			err := c1.AddServerToGroup(1, c.server)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("err(%v) != wantErr(%v)", err, c.wantErr)
+			}
+			// Wait for committed log entry to be applied.
+			time.Sleep(500 * time.Millisecond)
+
+			for _, cl := range []*cluster.Cluster{c1, c2} {
+				g := cl.Groups(1)[1]
+				if !reflect.DeepEqual(g.Servers(), c.wantServers) {
+					t.Errorf("Servers: got(%+v) != want(%+v)", g.Servers(), c.wantServers)
+				}
+				if !reflect.DeepEqual(g.Joining(), c.wantJoining) {
+					t.Errorf("Joining: got(%+v) != want(%+v)", g.Joining(), c.wantJoining)
+				}
+				for _, s := range cl.Slots() {
+					if s.Group() != g {
+						t.Fatalf("slot %d does not belong to the new group", s.ID)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCluster_ResumeBackfill(t *testing.T) {
+	clusters, cleanup := newAndOpenClusters(t, 3)
+	defer cleanup()
+	c1 := clusters[0]
+
+	c1.AddGroup(1, "server1", "server2")
+	c1.AssignSlots(1, 0, cluster.SlotNum-1)
+	// The backfill fails, which leaves the server joining.
+	c1.AddServerToGroup(1, "broken")
+	// Wait for committed log entry to be applied.
+	time.Sleep(500 * time.Millisecond)
+
+	// Let the new leader resume the backfill, while adding groups to it.
+	c1.Close(true)
+	var leader *cluster.Cluster
+	for i := 0; leader == nil && i < 100; i++ {
+		time.Sleep(100 * time.Millisecond)
+		for _, c := range clusters[1:] {
+			if c.IsLeader() {
+				leader = c
+			}
+		}
+	}
+	if leader == nil {
+		t.Fatal("no new leader")
+	}
+	for id := 2; id <= 5; id++ {
+		if err := leader.AddGroup(id, "server1", "server2"); err != nil {
+			t.Fatalf("err: got(%+v) != want(<nil>)", err)
+		}
+	}
+	// Wait for the backfill to be resumed.
+	time.Sleep(500 * time.Millisecond)
+
+	groups := leader.Groups()
+	if len(groups) != 5 {
+		t.Errorf("groups: got(%+v) != want(%+v)", len(groups), 5)
+	}
+	want := []cluster.Server{"broken"}
+	if got := groups[1].Joining(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Joining: got(%+v) != want(%+v)", got, want)
+	}
+}
+
+func TestCluster_RemoveServerFromGroup(t *testing.T) {
+	clusters, cleanup := newAndOpenClusters(t, 2)
+	defer cleanup()
+	c1 := clusters[0]
+	c2 := clusters[1]
+
+	c1.AddGroup(1, "server1", "server2", "server3")
+	c1.AssignSlots(1, 0, cluster.SlotNum-1)
+	// Wait for committed log entry to be applied.
+	time.Sleep(500 * time.Millisecond)
+	old := c1.Groups(1)[1]
+
+	cases := []struct {
+		server      cluster.Server
+		wantErr     bool
+		wantServers []cluster.Server
+	}{
+		{
+			server:      "server1",
+			wantServers: []cluster.Server{"server2", "server3"},
+		},
+		{
+			server:      "server4",
+			wantErr:     true,
+			wantServers: []cluster.Server{"server2", "server3"},
+		},
+		{
+			// Fewer serving servers than the write quorum.
+			server:      "server2",
+			wantErr:     true,
+			wantServers: []cluster.Server{"server2", "server3"},
+		},
+	}
+	for _, c := range cases {
+		t.Run("", func(t *testing.T) {
+			err := c1.RemoveServerFromGroup(1, c.server)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("err(%v) != wantErr(%v)", err, c.wantErr)
+			}
+			// Wait for committed log entry to be applied.
+			time.Sleep(500 * time.Millisecond)
+
+			for _, cl := range []*cluster.Cluster{c1, c2} {
+				g := cl.Groups(1)[1]
+				if !reflect.DeepEqual(g.Servers(), c.wantServers) {
+					t.Errorf("Servers: got(%+v) != want(%+v)", g.Servers(), c.wantServers)
+				}
+				for _, s := range cl.Slots() {
+					if s.State() != cluster.SlotStateOnline || s.Group() != g {
+						t.Fatalf("slot %d is not online in the new group", s.ID)
+					}
+				}
+			}
+		})
+	}
+
+	// The replaced group is closed later, after the in-flight requests.
+	if atomic.LoadInt32(&old.(*group).closed) != 0 {
+		t.Errorf("group %d is closed too early", old.ID())
+	}
+}
+
 func TestCluster_AssignSlots(t *testing.T) {
 	clusters, cleanup := newAndOpenClusters(t, 2)
 	defer cleanup()
@@ -160,6 +486,16 @@ func TestCluster_MigrateSlots(t *testing.T) {
 	// Wait for committed log entry to be applied.
 	time.Sleep(500 * time.Millisecond)
 
+	// The slots out of range are rejected.
+	for _, r := range [][2]int{{1020, 1030}, {-1, 10}} {
+		if err := c1.MigrateSlots(2, r[0], r[1]); err == nil {
+			t.Errorf("err(%v) != wantErr(true) for range %v", err, r)
+		}
+		if _, err := c1.StartMigration(2, r[0], r[1]); err == nil {
+			t.Errorf("err(%v) != wantErr(true) for range %v", err, r)
+		}
+	}
+
 	if err := c1.MigrateSlots(2, 0, 10); err != nil {
 		t.Error(err)
 	}
@@ -181,6 +517,187 @@ func TestCluster_MigrateSlots(t *testing.T) {
 	validate(c2.Slots())
 }
 
+func TestCluster_StartMigration(t *testing.T) {
+	clusters, cleanup := newAndOpenClusters(t, 2)
+	defer cleanup()
+	c1 := clusters[0]
+	c2 := clusters[1]
+
+	c1.AddGroup(1, "server1", "server2")
+	c1.AssignSlots(1, 0, cluster.SlotNum-1)
+	c1.AddGroup(2, "server3", "server4")
+	// Wait for committed log entry to be applied.
+	time.Sleep(500 * time.Millisecond)
+
+	if _, err := c2.StartMigration(2, 0, 10); err != cluster.ErrNotLeader {
+		t.Fatalf("err: got(%+v) != want(%+v)", err, cluster.ErrNotLeader)
+	}
+
+	// The invalid slot ranges are rejected.
+	for _, r := range [][2]int{{1020, 1030}, {-1, 10}, {10, 5}} {
+		if _, err := c1.StartMigration(2, r[0], r[1]); err == nil {
+			t.Errorf("err(%v) != wantErr(true) for range %v", err, r)
+		}
+	}
+
+	wait := func(id string) cluster.MigrationStatus {
+		for {
+			status, err := c1.Migration(id)
+			if err != nil {
+				t.Fatalf("err: got(%+v) != want(<nil>)", err)
+			}
+			if status.Done {
+				return status
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+
+	cases := []struct {
+		startSlotID int
+		stopSlotID  int
+		want        map[cluster.MigrationPhase]int
+	}{
+		{
+			startSlotID: 0,
+			stopSlotID:  10,
+			want:        map[cluster.MigrationPhase]int{cluster.MigrationDone: 11},
+		},
+		{
+			startSlotID: 5,
+			stopSlotID:  12,
+			want:        map[cluster.MigrationPhase]int{cluster.MigrationSkipped: 6, cluster.MigrationDone: 2},
+		},
+	}
+	for _, c := range cases {
+		t.Run("", func(t *testing.T) {
+			id, err := c1.StartMigration(2, c.startSlotID, c.stopSlotID)
+			if err != nil {
+				t.Fatalf("err: got(%+v) != want(<nil>)", err)
+			}
+
+			status := wait(id)
+			got := make(map[cluster.MigrationPhase]int)
+			for i, s := range status.Slots {
+				if s.SlotID != c.startSlotID+i {
+					t.Errorf("slot: got(%+v) != want(%+v)", s.SlotID, c.startSlotID+i)
+				}
+				got[s.Phase]++
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("phases: got(%+v) != want(%+v)", got, c.want)
+			}
+
+			// The job is recorded in all the nodes.
+			time.Sleep(500 * time.Millisecond)
+			if status2, err := c2.Migration(id); err != nil || !reflect.DeepEqual(status2, status) {
+				t.Errorf("status: got(%+v, %+v) != want(%+v)", status2, err, status)
+			}
+		})
+	}
+}
+
+func TestCluster_AbortMigration(t *testing.T) {
+	clusters, cleanup := newAndOpenClusters(t, 2)
+	defer cleanup()
+	c1 := clusters[0]
+	c2 := clusters[1]
+
+	c1.AddGroup(1, "server1", "server2")
+	c1.AssignSlots(1, 0, cluster.SlotNum-1)
+	c1.AddGroup(2, "broken", "server3")
+	// Wait for committed log entry to be applied.
+	time.Sleep(500 * time.Millisecond)
+
+	wait := func(id string) cluster.MigrationStatus {
+		for {
+			status, err := c1.Migration(id)
+			if err != nil {
+				t.Fatalf("err: got(%+v) != want(<nil>)", err)
+			}
+			if status.Done {
+				return status
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+
+	id, err := c1.StartMigration(2, 0, 2)
+	if err != nil {
+		t.Fatalf("err: got(%+v) != want(<nil>)", err)
+	}
+	// The slots are left in migration.
+	for _, s := range wait(id).Slots {
+		if s.Phase != cluster.MigrationFailed || c1.Slots(s.SlotID)[s.SlotID].State() != cluster.SlotStateInMigration {
+			t.Errorf("slot %d is not left in migration (phase: %s)", s.SlotID, s.Phase)
+		}
+	}
+
+	if err := c1.AbortMigration(id); err != nil {
+		t.Fatalf("err: got(%+v) != want(<nil>)", err)
+	}
+	status := wait(id)
+	if !status.Aborted {
+		t.Errorf("aborted: got(%+v) != want(%+v)", status.Aborted, true)
+	}
+	for _, s := range status.Slots {
+		if s.Phase != cluster.MigrationAborted {
+			t.Errorf("phase of slot %d: got(%s) != want(%s)", s.SlotID, s.Phase, cluster.MigrationAborted)
+		}
+	}
+	for _, c := range []*cluster.Cluster{c1, c2} {
+		for _, s := range c.Slots(0, 1, 2) {
+			if s.State() != cluster.SlotStateOnline || s.Group().ID() != 1 {
+				t.Errorf("slot %d is not rolled back to group 1", s.ID)
+			}
+		}
+	}
+}
+
+func TestCluster_ResumeMigration(t *testing.T) {
+	clusters, cleanup := newAndOpenClusters(t, 3)
+	defer cleanup()
+	c1 := clusters[0]
+
+	c1.AddGroup(1, "server1", "server2")
+	c1.AssignSlots(1, 0, cluster.SlotNum-1)
+	c1.AddGroup(2, "slow", "server3")
+	// Wait for committed log entry to be applied.
+	time.Sleep(500 * time.Millisecond)
+
+	id, err := c1.StartMigration(2, 0, 19)
+	if err != nil {
+		t.Fatalf("err: got(%+v) != want(<nil>)", err)
+	}
+
+	// The leader crashes in the middle of the migration.
+	time.Sleep(500 * time.Millisecond)
+	c1.Close(true)
+
+	var status cluster.MigrationStatus
+	for deadline := time.Now().Add(20 * time.Second); time.Now().Before(deadline); {
+		if status, err = clusters[1].Migration(id); err == nil && status.Done {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if !status.Done {
+		t.Fatalf("migration job %s is not resumed: %+v", id, status)
+	}
+
+	for _, c := range clusters[1:] {
+		for _, s := range c.Slots() {
+			want := 1
+			if s.ID < 20 {
+				want = 2
+			}
+			if s.State() != cluster.SlotStateOnline || s.Group().ID() != want {
+				t.Errorf("slot %d is not online in group %d", s.ID, want)
+			}
+		}
+	}
+}
+
 func TestCluster_MapToSlot(t *testing.T) {
 	clusters, cleanup := newAndOpenClusters(t, 2)
 	defer cleanup()
@@ -195,7 +712,7 @@ func TestCluster_MapToSlot(t *testing.T) {
 	time.Sleep(500 * time.Millisecond)
 
 	validate := func(c *cluster.Cluster, key string, wantGroupID, wantSlotID int) {
-		slot, err := c.MapToSlot(key)
+		slot, err := c.MapToSlot(context.Background(), key)
 		if err != nil {
 			t.Error(err)
 		}
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	//"log"
+	"log"
+	"sort"
+	"time"
 
 	"github.com/hashicorp/raft"
 )
@@ -17,17 +19,37 @@ func (f *fsm) Apply(l *raft.Log) interface{} {
 	if err := json.Unmarshal(l.Data, &c); err != nil {
 		panic(fmt.Errorf("failed to unmarshal command: %s", err.Error()))
 	}
-	//log.Printf("command: %+v", c)
+
+	f.applyMu.Lock()
+	defer f.applyMu.Unlock()
 
 	switch c.Op {
 	case "add_group":
-		return f.applyAddGroup(c.GroupID, c.Servers)
+		return f.applyAddGroup(c.GroupID, c.Servers, c.Options)
 	case "del_group":
 		return f.applyDelGroup(c.GroupID)
+	case "add_server_to_group":
+		return f.applyAddServerToGroup(c.GroupID, c.Servers)
+	case "promote_server_in_group":
+		return f.applyPromoteServerInGroup(c.GroupID, c.Servers)
+	case "remove_server_from_group":
+		return f.applyRemoveServerFromGroup(c.GroupID, c.Servers)
+	case "set_api_addr":
+		return f.applySetAPIAddr(c.NodeAddr, c.APIAddr)
 	case "assign_slots":
 		return f.applyAssignSlots(c.GroupID, c.StartSlotID, c.StopSlotID)
 	case "change_slot_state":
-		return f.applyChangeSlotState(c.GroupID, c.StartSlotID, c.SlotState)
+		return f.applyChangeSlotState(c.GroupID, c.StartSlotID, c.SlotState, c.JobID)
+	case "rollback_slot":
+		return f.applyRollbackSlot(c.StartSlotID)
+	case "start_migration":
+		return f.applyStartMigration(c.JobID, c.GroupID, c.StartSlotID, c.StopSlotID, c.After, c.Throttle, c.Time)
+	case "set_migration_phase":
+		return f.applySetMigrationPhase(c.JobID, c.StartSlotID, c.Phase, c.Error)
+	case "abort_migration":
+		return f.applyAbortMigration(c.JobID)
+	case "finish_migration":
+		return f.applyFinishMigration(c.JobID, c.Time)
 	default:
 		panic(fmt.Errorf("unrecognized command op: %s", c.Op))
 	}
@@ -51,11 +73,25 @@ func (f *fsm) Snapshot() (raft.FSMSnapshot, error) {
 	for groupID, g := range f.groups {
 		groups[groupID] = groupSnapshot{
 			Servers: g.Servers(),
+			Joining: g.Joining(),
+			Options: g.Options(),
 		}
 	}
+
+	// Clone the API addresses.
+	apiAddrs := make(map[string]string, len(f.apiAddrs))
+	for nodeAddr, apiAddr := range f.apiAddrs {
+		apiAddrs[nodeAddr] = apiAddr
+	}
+
+	// Clone the migration jobs.
+	migrations := make(map[string]*migration, len(f.migrations))
+	for id, m := range f.migrations {
+		migrations[id] = m.clone()
+	}
 	f.mu.RUnlock()
 
-	return &fsmSnapshot{Slots: slots, Groups: groups}, nil
+	return &fsmSnapshot{Slots: slots, Groups: groups, APIAddrs: apiAddrs, Migrations: migrations}, nil
 }
 
 // Restore stores the cluster metadata to a previous state.
@@ -65,21 +101,61 @@ func (f *fsm) Restore(rc io.ReadCloser) error {
 		return err
 	}
 
-	// Set the groups state from the snapshot,
-	// no lock required according to the hashicorp/raft docs.
+	f.applyMu.Lock()
+	defer f.applyMu.Unlock()
+
+	// Set the groups state from the snapshot.
 	groups := make(map[int]Group, len(fs.Groups))
 	for i, s := range fs.Groups {
-		groups[i] = f.newGroup(i, s.Servers)
+		g, err := f.newGroup(i, s.Servers, s.Joining, s.Options)
+		if err != nil {
+			for _, g := range groups {
+				closeGroup(g)
+			}
+			return fmt.Errorf("failed to restore group %d: %s", i, err)
+		}
+		groups[i] = g
 	}
+	f.mu.Lock()
+	old := f.groups
 	f.groups = groups
+	f.mu.Unlock()
 
-	// Set the slots state from the snapshot,
-	// no lock required according to the hashicorp/raft docs.
-	slots := make(map[int]*Slot, len(fs.Slots))
-	for i, s := range fs.Slots {
-		slots[i] = NewSlot(i, s.State, groups[s.GroupID], groups[s.FromGroupID])
+	// Set the slots state from the snapshot. The slots are restored in
+	// place, since they are held by the in-flight requests.
+	for i, slot := range f.slots {
+		s := fs.Slots[i]
+		var g, from Group
+		switch s.State {
+		case SlotStatePreMigration, SlotStateInMigration:
+			from = groups[s.FromGroupID]
+			fallthrough
+		case SlotStateOnline:
+			g = groups[s.GroupID]
+		}
+		slot.restore(s.State, g, from)
+	}
+
+	// Close the old groups after closeReplacedGroupDelay, as replaceGroup
+	// does, to let the in-flight requests on them complete.
+	time.AfterFunc(closeReplacedGroupDelay, func() {
+		for _, g := range old {
+			closeGroup(g)
+		}
+	})
+
+	apiAddrs := fs.APIAddrs
+	if apiAddrs == nil {
+		apiAddrs = make(map[string]string)
 	}
-	f.slots = slots
+	migrations := fs.Migrations
+	if migrations == nil {
+		migrations = make(map[string]*migration)
+	}
+	f.mu.Lock()
+	f.apiAddrs = apiAddrs
+	f.migrations = migrations
+	f.mu.Unlock()
 
 	return nil
 }
@@ -94,10 +170,25 @@ func (f *fsm) getGroup(groupID int) (Group, error) {
 	return g, nil
 }
 
-func (f *fsm) applyAddGroup(groupID int, servers []Server) interface{} {
+func (f *fsm) applyAddGroup(groupID int, servers []Server, opts *GroupOptions) interface{} {
+	// The commands before the options are introduced have none.
+	if opts == nil {
+		opts = &GroupOptions{}
+	}
+
+	g, err := f.newGroup(groupID, servers, nil, *opts)
+	if err != nil {
+		return err
+	}
+
 	f.mu.Lock()
-	f.groups[groupID] = f.newGroup(groupID, servers)
+	old, ok := f.groups[groupID]
+	f.groups[groupID] = g
 	f.mu.Unlock()
+
+	if ok {
+		closeGroup(old)
+	}
 	return nil
 }
 
@@ -119,9 +210,139 @@ func (f *fsm) applyDelGroup(groupID int) error {
 	f.mu.Lock()
 	delete(f.groups, groupID)
 	f.mu.Unlock()
+
+	closeGroup(g)
+	return nil
+}
+
+func (f *fsm) applySetAPIAddr(nodeAddr, apiAddr string) interface{} {
+	f.mu.Lock()
+	f.apiAddrs[nodeAddr] = apiAddr
+	f.mu.Unlock()
 	return nil
 }
 
+func (f *fsm) applyAddServerToGroup(groupID int, servers []Server) interface{} {
+	g, err := f.getGroup(groupID)
+	if err != nil {
+		return err
+	}
+
+	all := append([]Server(nil), g.Servers()...)
+	joining := append([]Server(nil), g.Joining()...)
+	changed := false
+	for _, server := range servers {
+		switch {
+		case containsServer(joining, server):
+			// Nothing happens if the server is already joining, whose
+			// backfill is to be resumed.
+		case containsServer(all, server):
+			return fmt.Errorf("server %s is already in group %d", server, groupID)
+		default:
+			all = append(all, server)
+			joining = append(joining, server)
+			changed = true
+		}
+	}
+
+	if changed {
+		return f.replaceGroup(g, all, joining)
+	}
+	return nil
+}
+
+func (f *fsm) applyPromoteServerInGroup(groupID int, servers []Server) interface{} {
+	g, err := f.getGroup(groupID)
+	if err != nil {
+		return err
+	}
+
+	joining := g.Joining()
+	for _, server := range servers {
+		if !containsServer(joining, server) {
+			return fmt.Errorf("server %s is not joining group %d", server, groupID)
+		}
+		joining = removeServer(joining, server)
+	}
+
+	return f.replaceGroup(g, g.Servers(), joining)
+}
+
+func (f *fsm) applyRemoveServerFromGroup(groupID int, servers []Server) interface{} {
+	g, err := f.getGroup(groupID)
+	if err != nil {
+		return err
+	}
+
+	all := g.Servers()
+	joining := g.Joining()
+	for _, server := range servers {
+		if !containsServer(all, server) {
+			return fmt.Errorf("server %s not found in group %d", server, groupID)
+		}
+		all = removeServer(all, server)
+		joining = removeServer(joining, server)
+	}
+	// Every write would fail if there were fewer serving servers than
+	// the write quorum.
+	if serving := len(all) - len(joining); serving == 0 || serving < g.WriteQuorum() {
+		return fmt.Errorf("cannot leave %d serving servers in group %d, whose write quorum is %d", serving, groupID, g.WriteQuorum())
+	}
+
+	return f.replaceGroup(g, all, joining)
+}
+
+// replaceGroup replaces the group old with a new one, which has the same id
+// and settings but the given servers, both in the groups and in the slots.
+// The old group is closed after closeReplacedGroupDelay, to let the in-flight
+// requests on it complete.
+func (f *fsm) replaceGroup(old Group, servers, joining []Server) error {
+	new, err := f.newGroup(old.ID(), servers, joining, old.Options())
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	f.groups[new.ID()] = new
+	f.mu.Unlock()
+
+	for _, slot := range f.slots {
+		slot.replaceGroup(old, new)
+	}
+
+	time.AfterFunc(closeReplacedGroupDelay, func() {
+		closeGroup(old)
+	})
+	return nil
+}
+
+func containsServer(servers []Server, server Server) bool {
+	for _, s := range servers {
+		if s == server {
+			return true
+		}
+	}
+	return false
+}
+
+// removeServer returns a copy of servers without server.
+func removeServer(servers []Server, server Server) []Server {
+	var result []Server
+	for _, s := range servers {
+		if s != server {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// closeGroup closes the group g, which has been removed from the cluster.
+func closeGroup(g Group) {
+	if err := g.Close(); err != nil {
+		log.Printf("failed to close group %d: %v", g.ID(), err)
+	}
+}
+
 func (f *fsm) applyAssignSlots(toGroupID, startSlotID, stopSlotID int) interface{} {
 	toGroup, err := f.getGroup(toGroupID)
 	if err != nil {
@@ -138,7 +359,7 @@ func (f *fsm) applyAssignSlots(toGroupID, startSlotID, stopSlotID int) interface
 	return nil
 }
 
-func (f *fsm) applyChangeSlotState(toGroupID, slotID int, toState SlotState) interface{} {
+func (f *fsm) applyChangeSlotState(toGroupID, slotID int, toState SlotState, jobID string) interface{} {
 	toGroup, err := f.getGroup(toGroupID)
 	if err != nil {
 		return err
@@ -146,16 +367,160 @@ func (f *fsm) applyChangeSlotState(toGroupID, slotID int, toState SlotState) int
 
 	slot := f.slots[slotID]
 
+	var phase MigrationPhase
 	switch toState {
 	case SlotStatePreMigration:
-		return slot.MarkPreMigration(toGroup)
+		err = slot.MarkPreMigration(toGroup)
+		phase = MigrationPreMigration
 	case SlotStateInMigration:
-		return slot.MarkInMigration()
+		err = slot.MarkInMigration()
+		phase = MigrationInMigration
 	case SlotStateOnline:
-		return slot.MarkOnline(toGroup)
+		err = slot.MarkOnline(toGroup)
+		phase = MigrationDone
 	default:
 		return fmt.Errorf("unrecognized slot state: %d", toState)
 	}
+	if err != nil {
+		return err
+	}
+
+	// Record the phase of the slot in the migration job, if any, along with
+	// the state change.
+	if jobID != "" {
+		return f.applySetMigrationPhase(jobID, slotID, phase, "")
+	}
+	return nil
+}
+
+func (f *fsm) applyRollbackSlot(slotID int) interface{} {
+	return f.slots[slotID].MarkRollback()
+}
+
+func (f *fsm) applyStartMigration(jobID string, toGroupID, startSlotID, stopSlotID int, after string, throttle time.Duration, startTime int64) interface{} {
+	// The range is validated here, rather than only by the callers, since
+	// a job with missing slots in the log would break every leader that
+	// replays it.
+	if err := validateSlotRange(startSlotID, stopSlotID); err != nil {
+		return err
+	}
+
+	if _, err := f.getGroup(toGroupID); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.migrations[jobID]; ok {
+		return fmt.Errorf("migration job %s already exists", jobID)
+	}
+
+	// The slots can only be migrated by one job at a time.
+	var finished []*migration
+	for _, m := range f.migrations {
+		if m.Done {
+			finished = append(finished, m)
+			continue
+		}
+		for slotID := startSlotID; slotID <= stopSlotID; slotID++ {
+			if m.slot(slotID) >= 0 {
+				return fmt.Errorf("slot %d is being migrated by job %s", slotID, m.ID)
+			}
+		}
+	}
+
+	// Remove the oldest finished jobs.
+	sort.Slice(finished, func(i, j int) bool { return finished[i].StartTime < finished[j].StartTime })
+	for i := 0; i <= len(finished)-maxFinishedMigrations; i++ {
+		delete(f.migrations, finished[i].ID)
+	}
+
+	m := &migration{
+		ID:        jobID,
+		ToGroupID: toGroupID,
+		After:     after,
+		Throttle:  throttle,
+		StartTime: startTime,
+	}
+	for slotID := startSlotID; slotID <= stopSlotID; slotID++ {
+		m.Slots = append(m.Slots, slotMigration{SlotID: slotID})
+	}
+	f.migrations[jobID] = m
+
+	return nil
+}
+
+// getMigration returns the migration job identified by jobID, which must be
+// called with f.mu held.
+func (f *fsm) getMigration(jobID string) (*migration, error) {
+	m, ok := f.migrations[jobID]
+	if !ok {
+		return nil, fmt.Errorf("migration job %s not found", jobID)
+	}
+	return m, nil
+}
+
+// validateSlotRange checks that the slots from startSlotID to stopSlotID
+// are all in [0, SlotNum), and the range is not empty.
+func validateSlotRange(startSlotID, stopSlotID int) error {
+	if startSlotID < 0 || stopSlotID >= SlotNum {
+		return fmt.Errorf("slot range [%d, %d] is not in [0, %d)", startSlotID, stopSlotID, SlotNum)
+	}
+	if startSlotID > stopSlotID {
+		return fmt.Errorf("start slot id %d is greater than stop slot id %d", startSlotID, stopSlotID)
+	}
+	return nil
+}
+
+func (f *fsm) applySetMigrationPhase(jobID string, slotID int, phase MigrationPhase, errMsg string) interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	m, err := f.getMigration(jobID)
+	if err != nil {
+		return err
+	}
+
+	i := m.slot(slotID)
+	if i < 0 {
+		return fmt.Errorf("slot %d not found in migration job %s", slotID, jobID)
+	}
+	m.Slots[i] = slotMigration{SlotID: slotID, Phase: phase, Err: errMsg}
+
+	return nil
+}
+
+func (f *fsm) applyAbortMigration(jobID string) interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	m, err := f.getMigration(jobID)
+	if err != nil {
+		return err
+	}
+
+	// The job is unfinished again until it has been rolled back.
+	m.Aborted = true
+	m.Done = false
+	m.EndTime = 0
+
+	return nil
+}
+
+func (f *fsm) applyFinishMigration(jobID string, endTime int64) interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	m, err := f.getMigration(jobID)
+	if err != nil {
+		return err
+	}
+
+	m.Done = true
+	m.EndTime = endTime
+
+	return nil
 }
 
 type slotSnapshot struct {
@@ -165,12 +530,16 @@ type slotSnapshot struct {
 }
 
 type groupSnapshot struct {
-	Servers []Server `json:"servers,omitempty"`
+	Servers []Server     `json:"servers,omitempty"`
+	Joining []Server     `json:"joining,omitempty"`
+	Options GroupOptions `json:"options"`
 }
 
 type fsmSnapshot struct {
-	Slots  map[int]slotSnapshot  `json:"slots,omitempty"`
-	Groups map[int]groupSnapshot `json:"groups,omitempty"`
+	Slots      map[int]slotSnapshot  `json:"slots,omitempty"`
+	Groups     map[int]groupSnapshot `json:"groups,omitempty"`
+	APIAddrs   map[string]string     `json:"api_addrs,omitempty"`
+	Migrations map[string]*migration `json:"migrations,omitempty"`
 }
 
 func (f *fsmSnapshot) Persist(sink raft.SnapshotSink) error {
@@ -1,8 +1,15 @@
 package cluster
 
+import (
+	"context"
+)
+
 type Migrator interface {
-	MigrateKeys(to Group, slotID int, keys ...string) error
+	MigrateKeys(ctx context.Context, to Group, slotID int, keys ...string) error
 	MigrateSlot(to Group, slotID int) error
+	// Backfill copies the slot identified by slotID from the other servers
+	// of the group to the joining server.
+	Backfill(slotID int, server Server) error
 }
 
 type Server string
@@ -11,9 +18,35 @@ type Group interface {
 	Migrator
 
 	ID() int
+	// Servers returns all the servers in the group, including the joining
+	// ones.
 	Servers() []Server
+	// Joining returns the servers that are joining the group, which receive
+	// the writes but do not count toward the write quorum until they have
+	// been backfilled.
+	Joining() []Server
+	// Options returns the settings of the group.
+	Options() GroupOptions
+	// WriteQuorum returns the number of the servers, which are not joining,
+	// that a write must succeed on.
+	WriteQuorum() int
+	// Close releases the resources (e.g. the connections to the servers)
+	// held by the group, which is called once the group is removed from
+	// the cluster.
+	Close() error
+}
+
+// GroupOptions is the settings of a group, which are kept in the cluster
+// along with the servers of the group.
+type GroupOptions struct {
+	// The percentile of the recent latencies of a replica, after which a
+	// read is also sent to the next replica (i.e. hedged). The hedged reads
+	// are disabled if it is 0.
+	HedgePercentile float64 `json:"hedge_percentile,omitempty"`
 }
 
-// NewGroup represent a group constructor that only accepts
-// id and servers as arguments.
-type NewGroup func(id int, servers []Server) Group
+// NewGroup represent a group constructor that only accepts id, servers,
+// the joining ones among servers and the settings as arguments. It returns
+// an error if the group cannot be built from them, which rejects the command
+// instead of crashing the cluster.
+type NewGroup func(id int, servers, joining []Server, opts GroupOptions) (Group, error)
@@ -0,0 +1,473 @@
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/raft"
+)
+
+const (
+	// The maximum number of the finished migration jobs kept in the
+	// cluster, beyond which the oldest ones are removed.
+	maxFinishedMigrations = 100
+
+	// The interval between two checks of the job that a job waits for.
+	waitMigrationInterval = 100 * time.Millisecond
+)
+
+// MigrationPhase is the phase of a slot in a migration job.
+type MigrationPhase int
+
+const (
+	// MigrationPending slots have not been migrated yet.
+	MigrationPending MigrationPhase = iota
+	// MigrationPreMigration slots are blocking their requests, until they
+	// are in migration.
+	MigrationPreMigration
+	// MigrationInMigration slots are being moved to the target group.
+	MigrationInMigration
+	// MigrationDone slots have been migrated to the target group.
+	MigrationDone
+	// MigrationSkipped slots already belong to the target group.
+	MigrationSkipped
+	// MigrationFailed slots have failed to be migrated, which may be left
+	// in migration.
+	MigrationFailed
+	// MigrationAborted slots have been left in (or rolled back to) their
+	// source groups, since the job is aborted.
+	MigrationAborted
+)
+
+func (p MigrationPhase) String() string {
+	switch p {
+	case MigrationPending:
+		return "pending"
+	case MigrationPreMigration:
+		return "pre-migration"
+	case MigrationInMigration:
+		return "in-migration"
+	case MigrationDone:
+		return "done"
+	case MigrationSkipped:
+		return "skipped"
+	case MigrationFailed:
+		return "failed"
+	case MigrationAborted:
+		return "aborted"
+	default:
+		return fmt.Sprintf("phase(%d)", p)
+	}
+}
+
+// SlotMigration is the progress of a slot in a migration job.
+type SlotMigration struct {
+	SlotID int
+	Phase  MigrationPhase
+	// The failure, which is nil unless the phase is MigrationFailed.
+	Err error
+}
+
+// MigrationStatus is the status of a migration job.
+type MigrationStatus struct {
+	ID        string
+	ToGroupID int
+	// The id of the job, after which this job runs, if any.
+	After string
+	// The slots in the order to be migrated.
+	Slots   []SlotMigration
+	Aborted bool
+	Done    bool
+	// The time when the job is started.
+	StartTime time.Time
+	// The time when the job is done, which is zero if it is running.
+	EndTime time.Time
+}
+
+// migration is a migration job recorded in the FSM, which survives the
+// leader changes.
+type migration struct {
+	ID        string          `json:"id"`
+	ToGroupID int             `json:"to_group_id"`
+	Slots     []slotMigration `json:"slots"`
+	After     string          `json:"after,omitempty"`
+	Throttle  time.Duration   `json:"throttle,omitempty"`
+	Aborted   bool            `json:"aborted,omitempty"`
+	Done      bool            `json:"done,omitempty"`
+	StartTime int64           `json:"start_time"`
+	EndTime   int64           `json:"end_time,omitempty"`
+}
+
+type slotMigration struct {
+	SlotID int            `json:"slot_id"`
+	Phase  MigrationPhase `json:"phase,omitempty"`
+	Err    string         `json:"err,omitempty"`
+}
+
+func (m *migration) clone() *migration {
+	c := *m
+	c.Slots = append([]slotMigration(nil), m.Slots...)
+	return &c
+}
+
+// slot returns the index of the slot identified by slotID in m, or -1 if
+// the slot is not in m.
+func (m *migration) slot(slotID int) int {
+	if len(m.Slots) == 0 {
+		return -1
+	}
+	if i := slotID - m.Slots[0].SlotID; i >= 0 && i < len(m.Slots) {
+		return i
+	}
+	return -1
+}
+
+// StartMigration starts a job, which migrates the slots from startSlotID to
+// stopSlotID to the group identified by toGroupID in the background, and
+// returns the id of the job. Unlike MigrateSlots, the job goes on with the
+// following slots if a slot fails to be migrated.
+//
+// The job is recorded in the cluster, whose progress can be got by
+// Migration from any node. If the leader changes, the new leader resumes
+// the job from where it stopped.
+func (c *Cluster) StartMigration(toGroupID, startSlotID, stopSlotID int) (string, error) {
+	return c.startMigration(toGroupID, startSlotID, stopSlotID, "", 0)
+}
+
+// startMigration is like StartMigration, but the job does not run until the
+// job identified by after, if not empty, is done, and it pauses for throttle
+// after each slot is migrated.
+func (c *Cluster) startMigration(toGroupID, startSlotID, stopSlotID int, after string, throttle time.Duration) (string, error) {
+	if err := c.validateSlotID(startSlotID); err != nil {
+		return "", err
+	}
+
+	if err := c.validateSlotID(stopSlotID); err != nil {
+		return "", err
+	}
+
+	now := time.Now().UnixNano()
+	// The jobs started in a row may get the same time.
+	for c.migration(strconv.FormatInt(now, 10)) != nil {
+		now++
+	}
+	id := strconv.FormatInt(now, 10)
+	err := c.apply(
+		&command{
+			Op:          "start_migration",
+			JobID:       id,
+			GroupID:     toGroupID,
+			StartSlotID: startSlotID,
+			StopSlotID:  stopSlotID,
+			After:       after,
+			Throttle:    throttle,
+			Time:        now,
+		},
+		true,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	c.runMigrationAsync(id)
+	return id, nil
+}
+
+// AbortMigration aborts the migration job identified by id. The slots that
+// have been migrated are left in the target group, while the slots in
+// migration (including the failed ones) are migrated back to their source
+// groups, and the others are left as is.
+func (c *Cluster) AbortMigration(id string) error {
+	err := c.apply(
+		&command{
+			Op:    "abort_migration",
+			JobID: id,
+		},
+		true,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Roll back the finished job, or let the running one roll back itself.
+	c.runMigrationAsync(id)
+	return nil
+}
+
+// Migration returns the status of the migration job identified by id.
+func (c *Cluster) Migration(id string) (MigrationStatus, error) {
+	m := c.migration(id)
+	if m == nil {
+		return MigrationStatus{}, fmt.Errorf("migration job %s not found", id)
+	}
+
+	status := MigrationStatus{
+		ID:        m.ID,
+		ToGroupID: m.ToGroupID,
+		After:     m.After,
+		Slots:     make([]SlotMigration, len(m.Slots)),
+		Aborted:   m.Aborted,
+		Done:      m.Done,
+		StartTime: time.Unix(0, m.StartTime),
+	}
+	if m.EndTime != 0 {
+		status.EndTime = time.Unix(0, m.EndTime)
+	}
+	for i, s := range m.Slots {
+		status.Slots[i] = SlotMigration{SlotID: s.SlotID, Phase: s.Phase}
+		if s.Err != "" {
+			status.Slots[i].Err = errors.New(s.Err)
+		}
+	}
+	return status, nil
+}
+
+// migration returns a copy of the migration job identified by id, or nil if
+// the job is not found.
+func (c *Cluster) migration(id string) *migration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if m, ok := c.migrations[id]; ok {
+		return m.clone()
+	}
+	return nil
+}
+
+// migratingSlots returns the target groups of the slots, which are to be
+// migrated by the unfinished migration jobs, by the slot ids.
+func (c *Cluster) migratingSlots() map[int]int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	slots := make(map[int]int)
+	for _, m := range c.migrations {
+		if m.Done || m.Aborted {
+			continue
+		}
+		for _, s := range m.Slots {
+			switch s.Phase {
+			case MigrationPending, MigrationPreMigration, MigrationInMigration:
+				slots[s.SlotID] = m.ToGroupID
+			}
+		}
+	}
+	return slots
+}
+
+// resumeMigrations resumes all the unfinished migration jobs on the new
+// leader.
+func (c *Cluster) resumeMigrations() {
+	// Ensure that all the preceding commands have been applied to the FSM.
+	if err := c.raft.Barrier(raftTimeout).Error(); err != nil {
+		log.Printf("failed to resume migration jobs: %v", err)
+		return
+	}
+
+	c.mu.RLock()
+	var ids []string
+	for id, m := range c.migrations {
+		if !m.Done {
+			ids = append(ids, id)
+		}
+	}
+	c.mu.RUnlock()
+
+	for _, id := range ids {
+		log.Printf("resuming migration job %s", id)
+		c.runMigrationAsync(id)
+	}
+}
+
+// runMigrationAsync runs the migration job identified by id in the
+// background, unless it is already running on this node.
+func (c *Cluster) runMigrationAsync(id string) {
+	c.runningMu.Lock()
+	defer c.runningMu.Unlock()
+
+	if c.running[id] {
+		return
+	}
+	c.running[id] = true
+
+	go func() {
+		c.runMigration(id)
+
+		c.runningMu.Lock()
+		delete(c.running, id)
+		c.runningMu.Unlock()
+	}()
+}
+
+// runMigration runs the migration job identified by id, which goes through
+// the slots in order, and resumes each slot from its recorded phase. It
+// stops once this node is no longer the leader, and leaves the rest of the
+// job to the new leader.
+func (c *Cluster) runMigration(id string) {
+	if !c.waitMigration(id) {
+		return
+	}
+
+	m := c.migration(id)
+	if m == nil {
+		return
+	}
+
+	aborted := m.Aborted
+	for {
+		if !c.runSlots(id, m.Slots) {
+			return
+		}
+		if m = c.migration(id); m.Aborted == aborted {
+			break
+		}
+		// The job has been aborted while running, go through the slots
+		// again to roll back the failed ones.
+		aborted = true
+	}
+
+	err := c.apply(
+		&command{
+			Op:    "finish_migration",
+			JobID: id,
+			Time:  time.Now().UnixNano(),
+		},
+		true,
+	)
+	if err != nil {
+		log.Printf("failed to finish migration job %s: %v", id, err)
+	}
+}
+
+// waitMigration waits until the job, after which the migration job
+// identified by id runs, is done, or the job identified by id is aborted.
+// It reports whether this node is still the leader.
+func (c *Cluster) waitMigration(id string) bool {
+	for {
+		m := c.migration(id)
+		if m == nil || m.After == "" || m.Aborted {
+			return true
+		}
+		if after := c.migration(m.After); after == nil || after.Done {
+			return true
+		}
+
+		if !c.IsLeader() {
+			return false
+		}
+		select {
+		case <-time.After(waitMigrationInterval):
+		case <-c.stopC:
+			return false
+		}
+	}
+}
+
+// runSlots migrates, or aborts, the given slots of the migration job
+// identified by id. It reports whether this node is still the leader.
+func (c *Cluster) runSlots(id string, slots []slotMigration) bool {
+	for _, s := range slots {
+		// Reload the job, which may have been aborted.
+		m := c.migration(id)
+		phase := m.Slots[m.slot(s.SlotID)].Phase
+
+		var err error
+		switch {
+		case c.slots[s.SlotID] == nil:
+			// The slot does not exist, which may be left by a job recorded
+			// before the slot range was validated.
+			if phase != MigrationFailed {
+				err = fmt.Errorf("slot %d not found", s.SlotID)
+			}
+		case m.Aborted:
+			err = c.abortSlot(m, s.SlotID, phase)
+		case phase == MigrationPending, phase == MigrationPreMigration, phase == MigrationInMigration:
+			var skipped bool
+			if skipped, err = c.migrateSlot(m.ToGroupID, s.SlotID, id); err == nil && skipped {
+				err = c.setSlotPhase(id, s.SlotID, MigrationSkipped, "")
+			}
+			if err == nil && !skipped && m.Throttle > 0 {
+				select {
+				case <-time.After(m.Throttle):
+				case <-c.stopC:
+				}
+			}
+		}
+		if err == nil {
+			continue
+		}
+
+		if c.raft.State() != raft.Leader {
+			log.Printf("migration job %s is left to the new leader: %v", id, err)
+			return false
+		}
+		if err := c.setSlotPhase(id, s.SlotID, MigrationFailed, err.Error()); err != nil {
+			log.Printf("failed to record the failure of migration job %s: %v", id, err)
+			return false
+		}
+	}
+	return true
+}
+
+// abortSlot aborts the slot identified by slotID, which is in the given
+// phase of the aborted job m. The pending slot is left as is, and the slot
+// in migration to the target group is migrated back to its source group.
+func (c *Cluster) abortSlot(m *migration, slotID int, phase MigrationPhase) error {
+	switch phase {
+	case MigrationPending:
+		return c.setSlotPhase(m.ID, slotID, MigrationAborted, "")
+	case MigrationPreMigration, MigrationInMigration, MigrationFailed:
+		slot := c.slots[slotID]
+		switch slot.State() {
+		case SlotStatePreMigration, SlotStateInMigration:
+			if slot.Group().ID() != m.ToGroupID {
+				// The slot is not migrated by this job.
+				return nil
+			}
+			if err := c.rollbackSlot(slotID); err != nil {
+				return err
+			}
+			return c.setSlotPhase(m.ID, slotID, MigrationAborted, "")
+		}
+	}
+	return nil
+}
+
+// rollbackSlot migrates the slot identified by slotID, which is in
+// migration, back to its source group. The slot is reversed to be in
+// pre-migration to its source group, and then migrated as usual.
+func (c *Cluster) rollbackSlot(slotID int) error {
+	fromGroupID := c.slots[slotID].FromGroup().ID()
+
+	err := c.apply(
+		&command{
+			Op:          "rollback_slot",
+			StartSlotID: slotID,
+		},
+		true,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.migrateSlot(fromGroupID, slotID, "")
+	return err
+}
+
+// setSlotPhase records the phase of the slot identified by slotID in the
+// migration job identified by id.
+func (c *Cluster) setSlotPhase(id string, slotID int, phase MigrationPhase, errMsg string) error {
+	return c.apply(
+		&command{
+			Op:          "set_migration_phase",
+			JobID:       id,
+			StartSlotID: slotID,
+			Phase:       phase,
+			Error:       errMsg,
+		},
+		true,
+	)
+}
@@ -0,0 +1,340 @@
+package cluster
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Move is a move of a slot between groups in a rebalance plan.
+type Move struct {
+	SlotID      int
+	FromGroupID int
+	ToGroupID   int
+	// The data size of the slot.
+	Size int64
+}
+
+// Plan is a rebalance plan.
+type Plan struct {
+	Moves []Move
+	// The loads (i.e. the total data sizes of the slots) of the groups
+	// before and after the moves.
+	Before map[int]int64
+	After  map[int]int64
+}
+
+// Rebalancer distributes the online slots across the groups in a cluster,
+// in proportion to the weights of the groups.
+type Rebalancer struct {
+	cluster     *Cluster
+	concurrency int
+	throttle    time.Duration
+
+	weights  map[int]float64
+	slotSize func(slotID int) (int64, error)
+}
+
+// NewRebalancer creates a Rebalancer, which runs at most concurrency
+// migration jobs at a time, each of which pauses for throttle after each
+// slot is migrated.
+func NewRebalancer(c *Cluster, concurrency int, throttle time.Duration) *Rebalancer {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &Rebalancer{
+		cluster:     c,
+		concurrency: concurrency,
+		throttle:    throttle,
+	}
+}
+
+// SetWeights sets the weights of the groups, which default to 1. A group
+// with zero weight is drained of all its slots. It must be called before
+// the rebalancer is used.
+func (r *Rebalancer) SetWeights(weights map[int]float64) {
+	r.weights = weights
+}
+
+// SetSlotSize sets the function to get the data size of a slot, which
+// defaults to 1 for every slot (i.e. the slots are balanced by their
+// numbers). It must be called before the rebalancer is used.
+func (r *Rebalancer) SetSlotSize(slotSize func(slotID int) (int64, error)) {
+	r.slotSize = slotSize
+}
+
+func (r *Rebalancer) weight(groupID int) float64 {
+	if w, ok := r.weights[groupID]; ok {
+		return w
+	}
+	return 1
+}
+
+// Plan computes the moves to balance the loads of the groups. The slots in
+// migration, or to be migrated by the unfinished migration jobs, are counted
+// toward their target groups and never moved, while the offline slots are
+// ignored, which must be assigned by AssignSlots first.
+//
+// The moves are chosen greedily: the slots of the overloaded groups are
+// moved to the most underloaded group, as long as the total deviation from
+// the target loads decreases. Each slot is moved at most once.
+func (r *Rebalancer) Plan() (*Plan, error) {
+	// Take a consistent view of the groups, the slots and the migration
+	// jobs, which are not changed by the FSM meanwhile.
+	var (
+		states    = make([]SlotState, SlotNum)
+		owners    = make([]int, SlotNum) // The groups of the slots
+		migrating map[int]int
+	)
+	r.cluster.applyMu.RLock()
+	groups := r.cluster.Groups()
+	for slotID := 0; slotID < SlotNum; slotID++ {
+		slot := r.cluster.slots[slotID]
+		states[slotID] = slot.State()
+		if g := slot.Group(); g != nil {
+			owners[slotID] = g.ID()
+		}
+	}
+	migrating = r.cluster.migratingSlots()
+	r.cluster.applyMu.RUnlock()
+
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("no group in the cluster")
+	}
+
+	for id := range r.weights {
+		if _, ok := groups[id]; !ok {
+			return nil, fmt.Errorf("group %d not found", id)
+		}
+	}
+
+	var total float64
+	for id := range groups {
+		w := r.weight(id)
+		if w < 0 {
+			return nil, fmt.Errorf("negative weight %v of group %d", w, id)
+		}
+		total += w
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("no group with positive weight")
+	}
+
+	// Collect the loads and the movable slots of each group.
+	var (
+		loads   = make(map[int]int64, len(groups))
+		movable = make(map[int][]Move, len(groups))
+		sum     int64
+	)
+	for id := range groups {
+		loads[id] = 0
+	}
+	for slotID := 0; slotID < SlotNum; slotID++ {
+		state := states[slotID]
+		if state == SlotStateOffline {
+			continue
+		}
+
+		size := int64(1)
+		if r.slotSize != nil {
+			var err error
+			if size, err = r.slotSize(slotID); err != nil {
+				return nil, fmt.Errorf("failed to get size of slot %d: %s", slotID, err)
+			}
+		}
+
+		groupID := owners[slotID]
+		toGroupID, ok := migrating[slotID]
+		if _, exists := groups[toGroupID]; ok && exists {
+			groupID = toGroupID
+		}
+		loads[groupID] += size
+		sum += size
+		if state == SlotStateOnline && !ok {
+			movable[groupID] = append(movable[groupID], Move{SlotID: slotID, FromGroupID: groupID, Size: size})
+		}
+	}
+
+	plan := &Plan{Before: make(map[int]int64, len(loads)), After: loads}
+	for id, load := range loads {
+		plan.Before[id] = load
+	}
+
+	// The deviation of each group from its target load.
+	deviation := func(id int) float64 {
+		return float64(loads[id]) - float64(sum)*r.weight(id)/total
+	}
+
+	for {
+		receiver := -1
+		for id := range loads {
+			if receiver == -1 || deviation(id) < deviation(receiver) || (deviation(id) == deviation(receiver) && id < receiver) {
+				receiver = id
+			}
+		}
+		deficit := -deviation(receiver)
+		if deficit <= 0 {
+			break
+		}
+
+		// Try the overloaded groups from the most overloaded one.
+		var donors []int
+		for id := range loads {
+			if deviation(id) > 0 {
+				donors = append(donors, id)
+			}
+		}
+		sort.Slice(donors, func(i, j int) bool {
+			di, dj := deviation(donors[i]), deviation(donors[j])
+			return di > dj || (di == dj && donors[i] < donors[j])
+		})
+
+		moved := false
+		for _, donor := range donors {
+			slots := movable[donor]
+			i := pickSlot(slots, deviation(donor), deficit)
+			if i == -1 {
+				continue
+			}
+
+			m := slots[i]
+			movable[donor] = append(slots[:i], slots[i+1:]...)
+			m.ToGroupID = receiver
+			plan.Moves = append(plan.Moves, m)
+			loads[donor] -= m.Size
+			loads[receiver] += m.Size
+			moved = true
+			break
+		}
+		if !moved {
+			break
+		}
+	}
+
+	regroup(plan.Moves)
+	sort.Slice(plan.Moves, func(i, j int) bool { return plan.Moves[i].SlotID < plan.Moves[j].SlotID })
+	return plan, nil
+}
+
+// regroup reassigns the target groups of the moves of the same size from
+// the same group, which are interchangeable, to move the consecutive slots
+// to the same group. This keeps the loads as is, while making fewer runs
+// (see runs) and thus fewer migration jobs.
+func regroup(moves []Move) {
+	type class struct {
+		from int
+		size int64
+	}
+	classes := make(map[class][]int) // The indexes of the moves by class
+	for i, m := range moves {
+		c := class{from: m.FromGroupID, size: m.Size}
+		classes[c] = append(classes[c], i)
+	}
+
+	for _, indexes := range classes {
+		slotIDs := make([]int, len(indexes))
+		toGroupIDs := make([]int, len(indexes))
+		for k, i := range indexes {
+			slotIDs[k] = moves[i].SlotID
+			toGroupIDs[k] = moves[i].ToGroupID
+		}
+		sort.Ints(slotIDs)
+		sort.Ints(toGroupIDs)
+		for k, i := range indexes {
+			moves[i].SlotID = slotIDs[k]
+			moves[i].ToGroupID = toGroupIDs[k]
+		}
+	}
+}
+
+// Execute executes the moves in plan by the migration jobs, and returns the
+// ids of the jobs, whose progress can be got by Migration. Each run of the
+// consecutive slots moved to the same group makes a job, and the jobs are
+// chained to run at most concurrency at a time.
+//
+// The jobs are all recorded in the cluster before Execute returns, which
+// survive the leader changes. If a job fails to start, the ids of the jobs
+// started so far are returned along with the error.
+func (r *Rebalancer) Execute(plan *Plan) ([]string, error) {
+	if !r.cluster.IsLeader() {
+		return nil, ErrNotLeader
+	}
+
+	var (
+		ids   []string
+		lasts = make([]string, r.concurrency) // The last jobs of the chains
+	)
+	for i, run := range runs(plan.Moves) {
+		chain := i % r.concurrency
+		first, last := run[0], run[len(run)-1]
+		id, err := r.cluster.startMigration(last.ToGroupID, first.SlotID, last.SlotID, lasts[chain], r.throttle)
+		if err != nil {
+			return ids, fmt.Errorf("failed to migrate slots [%d, %d] to group %d: %s", first.SlotID, last.SlotID, last.ToGroupID, err)
+		}
+		ids = append(ids, id)
+		lasts[chain] = id
+	}
+	return ids, nil
+}
+
+// runs splits moves, which are ordered by the slot ids, into the runs of
+// the consecutive slots moved to the same group.
+func runs(moves []Move) [][]Move {
+	var runs [][]Move
+	for i, m := range moves {
+		if i > 0 {
+			prev := moves[i-1]
+			if m.SlotID == prev.SlotID+1 && m.ToGroupID == prev.ToGroupID {
+				runs[len(runs)-1] = append(runs[len(runs)-1], m)
+				continue
+			}
+		}
+		runs = append(runs, []Move{m})
+	}
+	return runs
+}
+
+// Rebalance computes a plan and executes it, unless dryRun is set. It also
+// returns the ids of the migration jobs, if the plan is executed.
+func (r *Rebalancer) Rebalance(dryRun bool) (*Plan, []string, error) {
+	plan, err := r.Plan()
+	if err != nil || dryRun {
+		return plan, nil, err
+	}
+	ids, err := r.Execute(plan)
+	return plan, ids, err
+}
+
+// pickSlot returns the index of the slot in slots to be moved from a group
+// with the given excess to a group with the given deficit, or -1 if moving
+// any slot would not decrease the total deviation from the target loads.
+//
+// The largest slot which fits in both the excess and the deficit is
+// preferred, or else the smallest slot which still decreases the total
+// deviation.
+func pickSlot(slots []Move, excess, deficit float64) int {
+	// The tolerance of the floating-point errors.
+	const epsilon = 1e-9
+
+	fit, fallback := -1, -1
+	for i, m := range slots {
+		if m.Size <= 0 {
+			// Moving an empty slot changes nothing.
+			continue
+		}
+		s := float64(m.Size)
+		if s <= excess+epsilon && s <= deficit+epsilon {
+			if fit == -1 || m.Size > slots[fit].Size {
+				fit = i
+			}
+		} else if s < excess+deficit-epsilon {
+			if fallback == -1 || m.Size < slots[fallback].Size {
+				fallback = i
+			}
+		}
+	}
+	if fit != -1 {
+		return fit
+	}
+	return fallback
+}
@@ -0,0 +1,202 @@
+package cluster_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/RussellLuo/goku/cluster"
+)
+
+func TestRebalancer_Plan(t *testing.T) {
+	clusters, cleanup := newAndOpenClusters(t, 1)
+	defer cleanup()
+	c1 := clusters[0]
+
+	c1.AddGroup(1, "server1", "server2")
+	c1.AssignSlots(1, 0, cluster.SlotNum-1)
+	c1.AddGroup(2, "server3", "server4")
+	c1.AddGroup(3, "server5", "server6")
+	// Wait for committed log entry to be applied.
+	time.Sleep(500 * time.Millisecond)
+
+	// The first half of the slots are three times the size of the others.
+	slotSize := func(slotID int) (int64, error) {
+		if slotID < cluster.SlotNum/2 {
+			return 3, nil
+		}
+		return 1, nil
+	}
+
+	cases := []struct {
+		weights   map[int]float64
+		slotSize  func(slotID int) (int64, error)
+		wantMoves int
+		wantRuns  int
+		wantAfter map[int]int64
+		wantErr   bool
+	}{
+		{
+			wantMoves: 682,
+			wantRuns:  2,
+			wantAfter: map[int]int64{1: 342, 2: 341, 3: 341},
+		},
+		{
+			weights:   map[int]float64{3: 2},
+			wantMoves: 768,
+			wantRuns:  2,
+			wantAfter: map[int]int64{1: 256, 2: 256, 3: 512},
+		},
+		{
+			weights:   map[int]float64{3: 0},
+			slotSize:  slotSize,
+			wantMoves: 342,
+			wantRuns:  2,
+			wantAfter: map[int]int64{1: 1024, 2: 1024, 3: 0},
+		},
+		{
+			weights: map[int]float64{1: -1},
+			wantErr: true,
+		},
+		{
+			weights: map[int]float64{1: 0, 2: 0, 3: 0},
+			wantErr: true,
+		},
+		{
+			weights: map[int]float64{4: 1},
+			wantErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run("", func(t *testing.T) {
+			r := cluster.NewRebalancer(c1, 1, 0)
+			r.SetWeights(c.weights)
+			r.SetSlotSize(c.slotSize)
+
+			plan, err := r.Plan()
+			if (err != nil) != c.wantErr {
+				t.Fatalf("err: got(%+v), want error(%+v)", err, c.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if len(plan.Moves) != c.wantMoves {
+				t.Errorf("moves: got(%+v) != want(%+v)", len(plan.Moves), c.wantMoves)
+			}
+			if !reflect.DeepEqual(plan.After, c.wantAfter) {
+				t.Errorf("after: got(%+v) != want(%+v)", plan.After, c.wantAfter)
+			}
+			// The consecutive slots are moved to the same group.
+			runs := 0
+			for i, m := range plan.Moves {
+				if m.FromGroupID != 1 || m.ToGroupID == 1 {
+					t.Errorf("move: got(%+v)", m)
+				}
+				if i == 0 || m.SlotID != plan.Moves[i-1].SlotID+1 || m.ToGroupID != plan.Moves[i-1].ToGroupID {
+					runs++
+				}
+			}
+			if runs != c.wantRuns {
+				t.Errorf("runs: got(%+v) != want(%+v)", runs, c.wantRuns)
+			}
+		})
+	}
+}
+
+func TestRebalancer_Rebalance(t *testing.T) {
+	clusters, cleanup := newAndOpenClusters(t, 2)
+	defer cleanup()
+	c1 := clusters[0]
+	c2 := clusters[1]
+
+	c1.AddGroup(1, "server1", "server2")
+	c1.AssignSlots(1, 0, 99)
+	c1.AddGroup(2, "server3", "server4")
+	c1.AddGroup(3, "server5", "server6")
+	// Wait for committed log entry to be applied.
+	time.Sleep(500 * time.Millisecond)
+
+	countSlots := func(c *cluster.Cluster) map[int]int {
+		counts := make(map[int]int)
+		for _, s := range c.Slots() {
+			if s.State() != cluster.SlotStateOffline {
+				counts[s.Group().ID()]++
+			}
+		}
+		return counts
+	}
+
+	// The dry run changes nothing.
+	plan, ids, err := cluster.NewRebalancer(c1, 1, 0).Rebalance(true)
+	if err != nil {
+		t.Fatalf("err: got(%+v) != want(<nil>)", err)
+	}
+	if len(plan.Moves) != 66 || len(ids) != 0 {
+		t.Errorf("moves and jobs: got(%+v, %+v) != want(%+v, %+v)", len(plan.Moves), len(ids), 66, 0)
+	}
+	want := map[int]int{1: 100}
+	if got := countSlots(c1); !reflect.DeepEqual(got, want) {
+		t.Errorf("slots: got(%+v) != want(%+v)", got, want)
+	}
+
+	if _, err := cluster.NewRebalancer(c2, 1, 0).Execute(plan); err != cluster.ErrNotLeader {
+		t.Fatalf("err: got(%+v) != want(%+v)", err, cluster.ErrNotLeader)
+	}
+
+	// The moves to group 2 and group 3 make two jobs, which run one by one.
+	_, ids, err = cluster.NewRebalancer(c1, 1, 10*time.Millisecond).Rebalance(false)
+	if err != nil {
+		t.Fatalf("err: got(%+v) != want(<nil>)", err)
+	}
+	if len(ids) != 2 {
+		t.Fatalf("jobs: got(%+v) != want(%+v)", len(ids), 2)
+	}
+
+	// The slots to be migrated by the unfinished jobs are not moved again.
+	plan, err = cluster.NewRebalancer(c1, 1, 0).Plan()
+	if err != nil {
+		t.Fatalf("err: got(%+v) != want(<nil>)", err)
+	}
+	if len(plan.Moves) != 0 {
+		t.Errorf("moves: got(%+v) != want(%+v)", len(plan.Moves), 0)
+	}
+	var statuses []cluster.MigrationStatus
+	for _, id := range ids {
+		for {
+			status, err := c1.Migration(id)
+			if err != nil {
+				t.Fatalf("err: got(%+v) != want(<nil>)", err)
+			}
+			if status.Done {
+				statuses = append(statuses, status)
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+	if statuses[1].After != ids[0] {
+		t.Errorf("after: got(%+v) != want(%+v)", statuses[1].After, ids[0])
+	}
+	if statuses[1].EndTime.Before(statuses[0].EndTime) {
+		t.Errorf("job %s is done before job %s", ids[1], ids[0])
+	}
+
+	// Wait for committed log entry to be applied.
+	time.Sleep(500 * time.Millisecond)
+	want = map[int]int{1: 34, 2: 33, 3: 33}
+	for _, c := range []*cluster.Cluster{c1, c2} {
+		if got := countSlots(c); !reflect.DeepEqual(got, want) {
+			t.Errorf("slots: got(%+v) != want(%+v)", got, want)
+		}
+	}
+
+	// The balanced cluster needs no more moves.
+	plan, err = cluster.NewRebalancer(c1, 1, 0).Plan()
+	if err != nil {
+		t.Fatalf("err: got(%+v) != want(<nil>)", err)
+	}
+	if len(plan.Moves) != 0 {
+		t.Errorf("moves: got(%+v) != want(%+v)", plan.Moves, nil)
+	}
+}
@@ -1,6 +1,7 @@
 package cluster
 
 import (
+	"context"
 	"fmt"
 	"sync"
 )
@@ -132,11 +133,57 @@ func (s *Slot) MarkInMigration() error {
 	return nil
 }
 
+// MarkRollback reverses the slot in migration to be migrated back to its
+// source group, which starts from pre-migration as a new migration does.
+func (s *Slot) MarkRollback() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch s.state {
+	case SlotStatePreMigration, SlotStateInMigration:
+		s.state = SlotStatePreMigration
+		s.group, s.fromGroup = s.fromGroup, s.group
+		return nil
+	default:
+		return fmt.Errorf("cannot roll back %s slot", s.state)
+	}
+}
+
+// replaceGroup replaces the group old, which the slot belongs to or is
+// migrated from, with the group new, which has the same id but different
+// servers.
+func (s *Slot) replaceGroup(old, new Group) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.group == old {
+		s.group = new
+	}
+	if s.fromGroup == old {
+		s.fromGroup = new
+	}
+}
+
+// restore sets the slot to the given state, which is restored from a
+// snapshot.
+func (s *Slot) restore(state SlotState, group, fromGroup Group) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state = state
+	s.group = group
+	s.fromGroup = fromGroup
+	// Wake all the requests/goroutines that are being blocked in
+	// pre-migration state, which go on if the slot is in migration now.
+	s.co.Broadcast()
+}
+
 // GetWorkingGroups returns the group the slot belongs to, as well as the
 // possible source group if the slot is in migration.
 //
-// If the slot is offline, an error will be returned.
-func (s *Slot) GetWorkingGroups() (g, from Group, err error) {
+// If the slot is offline, or ctx is done while waiting for the slot in
+// pre-migration, an error will be returned.
+func (s *Slot) GetWorkingGroups(ctx context.Context) (g, from Group, err error) {
 	s.co.L.Lock()
 	defer s.co.L.Unlock()
 
@@ -144,10 +191,28 @@ func (s *Slot) GetWorkingGroups() (g, from Group, err error) {
 	case SlotStateOffline:
 		return nil, nil, fmt.Errorf("slot is offline")
 	case SlotStatePreMigration:
+		// Wake the wait below once ctx is done. The writer lock ensures
+		// that the wakeup is not lost between the check of ctx and the
+		// wait.
+		stopC := make(chan struct{})
+		defer close(stopC)
+		go func() {
+			select {
+			case <-ctx.Done():
+				s.mu.Lock()
+				s.co.Broadcast()
+				s.mu.Unlock()
+			case <-stopC:
+			}
+		}()
+
 		// To handle the migration in a highly consistent manner, we
 		// must wait until the state has been changed to in-migration
 		// if it is pre-migration before.
 		for s.state != SlotStateInMigration {
+			if err := ctx.Err(); err != nil {
+				return nil, nil, err
+			}
 			s.co.Wait()
 		}
 		// Go to the in-migration case.
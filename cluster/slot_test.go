@@ -1,6 +1,7 @@
 package cluster_test
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"testing"
@@ -11,8 +12,8 @@ import (
 
 func TestSlot_MarkOffline(t *testing.T) {
 	slotID := 0
-	group1 := newGroup(1, []cluster.Server{"server1"})
-	group2 := newGroup(2, []cluster.Server{"server2"})
+	group1, _ := newGroup(1, []cluster.Server{"server1"}, nil, cluster.GroupOptions{})
+	group2, _ := newGroup(2, []cluster.Server{"server2"}, nil, cluster.GroupOptions{})
 
 	type wantType struct {
 		err       error
@@ -88,8 +89,8 @@ func TestSlot_MarkOffline(t *testing.T) {
 
 func TestSlot_MarkOnline(t *testing.T) {
 	slotID := 0
-	group1 := newGroup(1, []cluster.Server{"server1"})
-	group2 := newGroup(2, []cluster.Server{"server2"})
+	group1, _ := newGroup(1, []cluster.Server{"server1"}, nil, cluster.GroupOptions{})
+	group2, _ := newGroup(2, []cluster.Server{"server2"}, nil, cluster.GroupOptions{})
 
 	type wantType struct {
 		err       error
@@ -165,8 +166,8 @@ func TestSlot_MarkOnline(t *testing.T) {
 
 func TestSlot_MarkPreMigration(t *testing.T) {
 	slotID := 0
-	group1 := newGroup(1, []cluster.Server{"server1"})
-	group2 := newGroup(2, []cluster.Server{"server2"})
+	group1, _ := newGroup(1, []cluster.Server{"server1"}, nil, cluster.GroupOptions{})
+	group2, _ := newGroup(2, []cluster.Server{"server2"}, nil, cluster.GroupOptions{})
 
 	type wantType struct {
 		err       error
@@ -242,8 +243,8 @@ func TestSlot_MarkPreMigration(t *testing.T) {
 
 func TestSlot_MarkInMigration(t *testing.T) {
 	slotID := 0
-	group1 := newGroup(1, []cluster.Server{"server1"})
-	group2 := newGroup(2, []cluster.Server{"server2"})
+	group1, _ := newGroup(1, []cluster.Server{"server1"}, nil, cluster.GroupOptions{})
+	group2, _ := newGroup(2, []cluster.Server{"server2"}, nil, cluster.GroupOptions{})
 
 	type wantType struct {
 		err       error
@@ -317,10 +318,87 @@ func TestSlot_MarkInMigration(t *testing.T) {
 	}
 }
 
+func TestSlot_MarkRollback(t *testing.T) {
+	slotID := 0
+	group1, _ := newGroup(1, []cluster.Server{"server1"}, nil, cluster.GroupOptions{})
+	group2, _ := newGroup(2, []cluster.Server{"server2"}, nil, cluster.GroupOptions{})
+
+	type wantType struct {
+		err       error
+		state     cluster.SlotState
+		group     cluster.Group
+		fromGroup cluster.Group
+	}
+
+	cases := []struct {
+		in   *cluster.Slot
+		want wantType
+	}{
+		{
+			in: cluster.NewSlot(slotID, cluster.SlotStateOffline, nil, nil),
+			want: wantType{
+				err:       fmt.Errorf("cannot roll back offline slot"),
+				state:     cluster.SlotStateOffline,
+				group:     nil,
+				fromGroup: nil,
+			},
+		},
+		{
+			in: cluster.NewSlot(slotID, cluster.SlotStateOnline, group1, nil),
+			want: wantType{
+				err:       fmt.Errorf("cannot roll back online slot"),
+				state:     cluster.SlotStateOnline,
+				group:     group1,
+				fromGroup: nil,
+			},
+		},
+		{
+			in: cluster.NewSlot(slotID, cluster.SlotStatePreMigration, group2, group1),
+			want: wantType{
+				err:       nil,
+				state:     cluster.SlotStatePreMigration,
+				group:     group1,
+				fromGroup: group2,
+			},
+		},
+		{
+			in: cluster.NewSlot(slotID, cluster.SlotStateInMigration, group2, group1),
+			want: wantType{
+				err:       nil,
+				state:     cluster.SlotStatePreMigration,
+				group:     group1,
+				fromGroup: group2,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.in.State().String(), func(t *testing.T) {
+			t.Parallel()
+
+			s := c.in
+			err := s.MarkRollback()
+			if !reflect.DeepEqual(err, c.want.err) {
+				t.Errorf("err: got(%+v) != want(%+v)", err, c.want.err)
+			}
+			if s.State() != c.want.state {
+				t.Errorf("state: got(%+v) != want(%+v)", s.State(), c.want.state)
+			}
+			if s.Group() != c.want.group {
+				t.Errorf("group: got(%+v) != want(%+v)", s.Group(), c.want.group)
+			}
+			if s.FromGroup() != c.want.fromGroup {
+				t.Errorf("fromGroup: got(%+v) != want(%+v)", s.FromGroup(), c.want.fromGroup)
+			}
+		})
+	}
+}
+
 func TestSlot_GetWorkingGroups(t *testing.T) {
 	slotID := 0
-	group1 := newGroup(1, []cluster.Server{"server1"})
-	group2 := newGroup(2, []cluster.Server{"server2"})
+	group1, _ := newGroup(1, []cluster.Server{"server1"}, nil, cluster.GroupOptions{})
+	group2, _ := newGroup(2, []cluster.Server{"server2"}, nil, cluster.GroupOptions{})
 
 	type wantType struct {
 		err       error
@@ -385,7 +463,7 @@ func TestSlot_GetWorkingGroups(t *testing.T) {
 			}()
 
 			start := time.Now()
-			g, from, err := s.GetWorkingGroups()
+			g, from, err := s.GetWorkingGroups(context.Background())
 			stop := time.Now()
 			// GetWorkingGroups is considered to be blocked, if it consumes
 			// greater than 1ms, which is lower than the above sleeping time but
@@ -407,3 +485,17 @@ func TestSlot_GetWorkingGroups(t *testing.T) {
 		})
 	}
 }
+
+func TestSlot_GetWorkingGroups_Canceled(t *testing.T) {
+	group1, _ := newGroup(1, []cluster.Server{"server1"}, nil, cluster.GroupOptions{})
+	group2, _ := newGroup(2, []cluster.Server{"server2"}, nil, cluster.GroupOptions{})
+	s := cluster.NewSlot(0, cluster.SlotStatePreMigration, group2, group1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Millisecond)
+	defer cancel()
+
+	_, _, err := s.GetWorkingGroups(ctx)
+	if err != context.DeadlineExceeded {
+		t.Errorf("err: got(%+v) != want(%+v)", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/RussellLuo/goku/cluster"
+	"github.com/RussellLuo/goku/cmd/goku-proxy/pb"
+)
+
+// forwardedKey is the metadata key marking the requests forwarded by a
+// follower, which are never forwarded again to avoid the loops during the
+// leader changes.
+const forwardedKey = "goku-forwarded"
+
+func isForwarded(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	return ok && len(md[forwardedKey]) > 0
+}
+
+// forward sends the leader-only request, which has been rejected by this
+// node, to the leader by call. The error is only returned if the request
+// cannot be forwarded, while the reply of the leader is left to call.
+func (p *Proxy) forward(ctx context.Context, call func(ctx context.Context, c pb.GokuProxyClient) error) error {
+	if isForwarded(ctx) {
+		return cluster.ErrNotLeader
+	}
+
+	addr, err := p.cluster.LeaderAPIAddr()
+	if err != nil {
+		return err
+	}
+
+	conn, err := grpc.DialContext(ctx, addr, grpc.WithInsecure(), grpc.WithBlock())
+	if err != nil {
+		return fmt.Errorf("failed to dial leader at %s: %v", addr, err)
+	}
+	defer conn.Close()
+
+	ctx = metadata.NewOutgoingContext(ctx, metadata.Pairs(forwardedKey, "true"))
+	return call(ctx, pb.NewGokuProxyClient(conn))
+}
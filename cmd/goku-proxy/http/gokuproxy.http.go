@@ -27,14 +27,17 @@ func MakeHandler(method Method, in proto.Message) http.HandlerFunc {
 			return
 		}
 
-		if err := unmarshaler.Unmarshal(r.Body, in); err != nil {
+		// Unmarshal into a new message per request, or the fields absent
+		// from a request would be left by the previous one.
+		req := proto.Clone(in)
+		if err := unmarshaler.Unmarshal(r.Body, req); err != nil {
 			if err != io.EOF {
 				w.WriteHeader(http.StatusBadRequest)
 				return
 			}
 		}
 
-		out, err := method(nil, in)
+		out, err := method(r.Context(), req)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
 			return
@@ -62,10 +65,24 @@ func (g *GokuProxy) HandlerMap() map[string]http.HandlerFunc {
 	m := make(map[string]http.HandlerFunc)
 	m["/goku_proxy/add_group"] = MakeHandler(g.AddGroup, new(pb.AddGroupRequest))
 	m["/goku_proxy/del_group"] = MakeHandler(g.DelGroup, new(pb.DelGroupRequest))
+	m["/goku_proxy/add_server_to_group"] = MakeHandler(g.AddServerToGroup, new(pb.AddServerToGroupRequest))
+	m["/goku_proxy/remove_server_from_group"] = MakeHandler(g.RemoveServerFromGroup, new(pb.RemoveServerFromGroupRequest))
 	m["/goku_proxy/assign_slots"] = MakeHandler(g.AssignSlots, new(pb.AssignSlotsRequest))
+	m["/goku_proxy/migrate_slots"] = MakeHandler(g.MigrateSlots, new(pb.MigrateSlotsRequest))
+	m["/goku_proxy/get_migration_status"] = MakeHandler(g.GetMigrationStatus, new(pb.GetMigrationStatusRequest))
+	m["/goku_proxy/abort_migration"] = MakeHandler(g.AbortMigration, new(pb.AbortMigrationRequest))
+	m["/goku_proxy/rebalance"] = MakeHandler(g.Rebalance, new(pb.RebalanceRequest))
 	m["/goku_proxy/insert"] = MakeHandler(g.Insert, new(pb.InsertRequest))
 	m["/goku_proxy/delete"] = MakeHandler(g.Delete, new(pb.DeleteRequest))
 	m["/goku_proxy/select"] = MakeHandler(g.Select, new(pb.SelectRequest))
+	m["/goku_proxy/batch_insert"] = MakeHandler(g.BatchInsert, new(pb.BatchInsertRequest))
+	m["/goku_proxy/batch_delete"] = MakeHandler(g.BatchDelete, new(pb.BatchDeleteRequest))
+	m["/goku_proxy/multi_select"] = MakeHandler(g.MultiSelect, new(pb.MultiSelectRequest))
+	m["/goku_proxy/health"] = MakeHandler(g.Health, new(pb.HealthRequest))
+	m["/goku_proxy/join_node"] = MakeHandler(g.JoinNode, new(pb.JoinNodeRequest))
+	m["/goku_proxy/add_non_voter"] = MakeHandler(g.AddNonVoter, new(pb.AddNonVoterRequest))
+	m["/goku_proxy/remove_node"] = MakeHandler(g.RemoveNode, new(pb.RemoveNodeRequest))
+	m["/goku_proxy/list_nodes"] = MakeHandler(g.ListNodes, new(pb.ListNodesRequest))
 	return m
 }
 
@@ -105,6 +122,42 @@ func (g *GokuProxy) DelGroup(ctx context.Context, in proto.Message) (proto.Messa
 	return out.(*pb.DelGroupReply), err
 }
 
+func (g *GokuProxy) AddServerToGroup(ctx context.Context, in proto.Message) (proto.Message, error) {
+	if g.interceptor == nil {
+		return g.srv.AddServerToGroup(ctx, in.(*pb.AddServerToGroupRequest))
+	}
+	out, err := g.interceptor(
+		ctx,
+		in.(*pb.AddServerToGroupRequest),
+		&grpc.UnaryServerInfo{
+			Server:     g.srv,
+			FullMethod: "/pb.GokuProxy/AddServerToGroup",
+		},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return g.srv.AddServerToGroup(ctx, req.(*pb.AddServerToGroupRequest))
+		},
+	)
+	return out.(*pb.AddServerToGroupReply), err
+}
+
+func (g *GokuProxy) RemoveServerFromGroup(ctx context.Context, in proto.Message) (proto.Message, error) {
+	if g.interceptor == nil {
+		return g.srv.RemoveServerFromGroup(ctx, in.(*pb.RemoveServerFromGroupRequest))
+	}
+	out, err := g.interceptor(
+		ctx,
+		in.(*pb.RemoveServerFromGroupRequest),
+		&grpc.UnaryServerInfo{
+			Server:     g.srv,
+			FullMethod: "/pb.GokuProxy/RemoveServerFromGroup",
+		},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return g.srv.RemoveServerFromGroup(ctx, req.(*pb.RemoveServerFromGroupRequest))
+		},
+	)
+	return out.(*pb.RemoveServerFromGroupReply), err
+}
+
 func (g *GokuProxy) AssignSlots(ctx context.Context, in proto.Message) (proto.Message, error) {
 	if g.interceptor == nil {
 		return g.srv.AssignSlots(ctx, in.(*pb.AssignSlotsRequest))
@@ -123,6 +176,78 @@ func (g *GokuProxy) AssignSlots(ctx context.Context, in proto.Message) (proto.Me
 	return out.(*pb.AssignSlotsReply), err
 }
 
+func (g *GokuProxy) MigrateSlots(ctx context.Context, in proto.Message) (proto.Message, error) {
+	if g.interceptor == nil {
+		return g.srv.MigrateSlots(ctx, in.(*pb.MigrateSlotsRequest))
+	}
+	out, err := g.interceptor(
+		ctx,
+		in.(*pb.MigrateSlotsRequest),
+		&grpc.UnaryServerInfo{
+			Server:     g.srv,
+			FullMethod: "/pb.GokuProxy/MigrateSlots",
+		},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return g.srv.MigrateSlots(ctx, req.(*pb.MigrateSlotsRequest))
+		},
+	)
+	return out.(*pb.MigrateSlotsReply), err
+}
+
+func (g *GokuProxy) GetMigrationStatus(ctx context.Context, in proto.Message) (proto.Message, error) {
+	if g.interceptor == nil {
+		return g.srv.GetMigrationStatus(ctx, in.(*pb.GetMigrationStatusRequest))
+	}
+	out, err := g.interceptor(
+		ctx,
+		in.(*pb.GetMigrationStatusRequest),
+		&grpc.UnaryServerInfo{
+			Server:     g.srv,
+			FullMethod: "/pb.GokuProxy/GetMigrationStatus",
+		},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return g.srv.GetMigrationStatus(ctx, req.(*pb.GetMigrationStatusRequest))
+		},
+	)
+	return out.(*pb.GetMigrationStatusReply), err
+}
+
+func (g *GokuProxy) AbortMigration(ctx context.Context, in proto.Message) (proto.Message, error) {
+	if g.interceptor == nil {
+		return g.srv.AbortMigration(ctx, in.(*pb.AbortMigrationRequest))
+	}
+	out, err := g.interceptor(
+		ctx,
+		in.(*pb.AbortMigrationRequest),
+		&grpc.UnaryServerInfo{
+			Server:     g.srv,
+			FullMethod: "/pb.GokuProxy/AbortMigration",
+		},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return g.srv.AbortMigration(ctx, req.(*pb.AbortMigrationRequest))
+		},
+	)
+	return out.(*pb.AbortMigrationReply), err
+}
+
+func (g *GokuProxy) Rebalance(ctx context.Context, in proto.Message) (proto.Message, error) {
+	if g.interceptor == nil {
+		return g.srv.Rebalance(ctx, in.(*pb.RebalanceRequest))
+	}
+	out, err := g.interceptor(
+		ctx,
+		in.(*pb.RebalanceRequest),
+		&grpc.UnaryServerInfo{
+			Server:     g.srv,
+			FullMethod: "/pb.GokuProxy/Rebalance",
+		},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return g.srv.Rebalance(ctx, req.(*pb.RebalanceRequest))
+		},
+	)
+	return out.(*pb.RebalanceReply), err
+}
+
 func (g *GokuProxy) Insert(ctx context.Context, in proto.Message) (proto.Message, error) {
 	if g.interceptor == nil {
 		return g.srv.Insert(ctx, in.(*pb.InsertRequest))
@@ -177,6 +302,150 @@ func (g *GokuProxy) Select(ctx context.Context, in proto.Message) (proto.Message
 	return out.(*pb.SelectReply), err
 }
 
+func (g *GokuProxy) BatchInsert(ctx context.Context, in proto.Message) (proto.Message, error) {
+	if g.interceptor == nil {
+		return g.srv.BatchInsert(ctx, in.(*pb.BatchInsertRequest))
+	}
+	out, err := g.interceptor(
+		ctx,
+		in.(*pb.BatchInsertRequest),
+		&grpc.UnaryServerInfo{
+			Server:     g.srv,
+			FullMethod: "/pb.GokuProxy/BatchInsert",
+		},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return g.srv.BatchInsert(ctx, req.(*pb.BatchInsertRequest))
+		},
+	)
+	return out.(*pb.BatchInsertReply), err
+}
+
+func (g *GokuProxy) BatchDelete(ctx context.Context, in proto.Message) (proto.Message, error) {
+	if g.interceptor == nil {
+		return g.srv.BatchDelete(ctx, in.(*pb.BatchDeleteRequest))
+	}
+	out, err := g.interceptor(
+		ctx,
+		in.(*pb.BatchDeleteRequest),
+		&grpc.UnaryServerInfo{
+			Server:     g.srv,
+			FullMethod: "/pb.GokuProxy/BatchDelete",
+		},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return g.srv.BatchDelete(ctx, req.(*pb.BatchDeleteRequest))
+		},
+	)
+	return out.(*pb.BatchDeleteReply), err
+}
+
+func (g *GokuProxy) MultiSelect(ctx context.Context, in proto.Message) (proto.Message, error) {
+	if g.interceptor == nil {
+		return g.srv.MultiSelect(ctx, in.(*pb.MultiSelectRequest))
+	}
+	out, err := g.interceptor(
+		ctx,
+		in.(*pb.MultiSelectRequest),
+		&grpc.UnaryServerInfo{
+			Server:     g.srv,
+			FullMethod: "/pb.GokuProxy/MultiSelect",
+		},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return g.srv.MultiSelect(ctx, req.(*pb.MultiSelectRequest))
+		},
+	)
+	return out.(*pb.MultiSelectReply), err
+}
+
+func (g *GokuProxy) Health(ctx context.Context, in proto.Message) (proto.Message, error) {
+	if g.interceptor == nil {
+		return g.srv.Health(ctx, in.(*pb.HealthRequest))
+	}
+	out, err := g.interceptor(
+		ctx,
+		in.(*pb.HealthRequest),
+		&grpc.UnaryServerInfo{
+			Server:     g.srv,
+			FullMethod: "/pb.GokuProxy/Health",
+		},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return g.srv.Health(ctx, req.(*pb.HealthRequest))
+		},
+	)
+	return out.(*pb.HealthReply), err
+}
+
+func (g *GokuProxy) JoinNode(ctx context.Context, in proto.Message) (proto.Message, error) {
+	if g.interceptor == nil {
+		return g.srv.JoinNode(ctx, in.(*pb.JoinNodeRequest))
+	}
+	out, err := g.interceptor(
+		ctx,
+		in.(*pb.JoinNodeRequest),
+		&grpc.UnaryServerInfo{
+			Server:     g.srv,
+			FullMethod: "/pb.GokuProxy/JoinNode",
+		},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return g.srv.JoinNode(ctx, req.(*pb.JoinNodeRequest))
+		},
+	)
+	return out.(*pb.JoinNodeReply), err
+}
+
+func (g *GokuProxy) AddNonVoter(ctx context.Context, in proto.Message) (proto.Message, error) {
+	if g.interceptor == nil {
+		return g.srv.AddNonVoter(ctx, in.(*pb.AddNonVoterRequest))
+	}
+	out, err := g.interceptor(
+		ctx,
+		in.(*pb.AddNonVoterRequest),
+		&grpc.UnaryServerInfo{
+			Server:     g.srv,
+			FullMethod: "/pb.GokuProxy/AddNonVoter",
+		},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return g.srv.AddNonVoter(ctx, req.(*pb.AddNonVoterRequest))
+		},
+	)
+	return out.(*pb.AddNonVoterReply), err
+}
+
+func (g *GokuProxy) RemoveNode(ctx context.Context, in proto.Message) (proto.Message, error) {
+	if g.interceptor == nil {
+		return g.srv.RemoveNode(ctx, in.(*pb.RemoveNodeRequest))
+	}
+	out, err := g.interceptor(
+		ctx,
+		in.(*pb.RemoveNodeRequest),
+		&grpc.UnaryServerInfo{
+			Server:     g.srv,
+			FullMethod: "/pb.GokuProxy/RemoveNode",
+		},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return g.srv.RemoveNode(ctx, req.(*pb.RemoveNodeRequest))
+		},
+	)
+	return out.(*pb.RemoveNodeReply), err
+}
+
+func (g *GokuProxy) ListNodes(ctx context.Context, in proto.Message) (proto.Message, error) {
+	if g.interceptor == nil {
+		return g.srv.ListNodes(ctx, in.(*pb.ListNodesRequest))
+	}
+	out, err := g.interceptor(
+		ctx,
+		in.(*pb.ListNodesRequest),
+		&grpc.UnaryServerInfo{
+			Server:     g.srv,
+			FullMethod: "/pb.GokuProxy/ListNodes",
+		},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return g.srv.ListNodes(ctx, req.(*pb.ListNodesRequest))
+		},
+	)
+	return out.(*pb.ListNodesReply), err
+}
+
 type Server struct {
 	mux         *http.ServeMux
 	interceptor grpc.UnaryServerInterceptor
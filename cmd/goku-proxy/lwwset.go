@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
+	"sync"
 	"time"
 
 	"github.com/RussellLuo/goku/cluster"
 	"github.com/RussellLuo/goku/common"
+	"github.com/RussellLuo/goku/group"
 )
 
 type Group interface {
@@ -12,10 +15,17 @@ type Group interface {
 	common.Inserter
 	common.Deleter
 	common.Selector
+
+	InsertWithResult(ctx context.Context, slotID int, key, member string, timestamp int64, ttl time.Duration) (*group.WriteResult, error)
+	DeleteWithResult(ctx context.Context, slotID int, key, member string, timestamp int64) (*group.WriteResult, error)
+	BatchInsert(ctx context.Context, ops []group.Op) []group.ItemResult
+	BatchDelete(ctx context.Context, ops []group.Op) []group.ItemResult
+	MultiSelect(ctx context.Context, ops []group.Op) []group.SelectResult
+	Health() []group.ReplicaHealth
 }
 
 type Mapper interface {
-	MapToSlot(key string) (*cluster.Slot, error)
+	MapToSlot(ctx context.Context, key string) (*cluster.Slot, error)
 }
 
 type LWWSet struct {
@@ -26,29 +36,147 @@ func NewLWWSet(mapper Mapper) *LWWSet {
 	return &LWWSet{mapper: mapper}
 }
 
-func (l *LWWSet) Insert(key, member string, timestamp int64, ttl time.Duration) (bool, error) {
-	slot, err := l.mapper.MapToSlot(key)
+func (l *LWWSet) Insert(ctx context.Context, key, member string, timestamp int64, ttl time.Duration) (bool, error) {
+	slot, err := l.mapper.MapToSlot(ctx, key)
 	if err != nil {
 		return false, err
 	}
 	g := slot.Group().(Group)
-	return g.Insert(slot.ID, key, member, timestamp, ttl)
+	return g.Insert(ctx, slot.ID, key, member, timestamp, ttl)
 }
 
-func (l *LWWSet) Delete(key, member string, timestamp int64) (bool, error) {
-	slot, err := l.mapper.MapToSlot(key)
+func (l *LWWSet) Delete(ctx context.Context, key, member string, timestamp int64) (bool, error) {
+	slot, err := l.mapper.MapToSlot(ctx, key)
 	if err != nil {
 		return false, err
 	}
 	g := slot.Group().(Group)
-	return g.Delete(slot.ID, key, member, timestamp)
+	return g.Delete(ctx, slot.ID, key, member, timestamp)
+}
+
+// InsertWithResult is like Insert, but returns the outcomes on all the
+// replicas, which is nil if the key cannot be mapped.
+func (l *LWWSet) InsertWithResult(ctx context.Context, key, member string, timestamp int64, ttl time.Duration) (*group.WriteResult, error) {
+	slot, err := l.mapper.MapToSlot(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	g := slot.Group().(Group)
+	return g.InsertWithResult(ctx, slot.ID, key, member, timestamp, ttl)
 }
 
-func (l *LWWSet) Select(key string, timestamp int64) ([]common.Element, error) {
-	slot, err := l.mapper.MapToSlot(key)
+// DeleteWithResult is like Delete, but returns the outcomes on all the
+// replicas, which is nil if the key cannot be mapped.
+func (l *LWWSet) DeleteWithResult(ctx context.Context, key, member string, timestamp int64) (*group.WriteResult, error) {
+	slot, err := l.mapper.MapToSlot(ctx, key)
 	if err != nil {
 		return nil, err
 	}
 	g := slot.Group().(Group)
-	return g.Select(slot.ID, key, timestamp)
+	return g.DeleteWithResult(ctx, slot.ID, key, member, timestamp)
+}
+
+func (l *LWWSet) Select(ctx context.Context, key string, timestamp int64) ([]common.Element, error) {
+	slot, err := l.mapper.MapToSlot(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	g := slot.Group().(Group)
+	return g.Select(ctx, slot.ID, key, timestamp)
+}
+
+// batch is the operations in a batch that belong to the same group, along
+// with their indices in the batch.
+type batch struct {
+	indices []int
+	ops     []group.Op
+}
+
+// split splits ops by the groups, to which their keys belong, and fills in
+// their slot IDs. The errors of the operations that cannot be mapped are
+// returned in the order of ops.
+func (l *LWWSet) split(ctx context.Context, ops []group.Op) (map[Group]*batch, []error) {
+	batches := make(map[Group]*batch)
+	errs := make([]error, len(ops))
+	for i, op := range ops {
+		slot, err := l.mapper.MapToSlot(ctx, op.Key)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		g := slot.Group().(Group)
+		b, ok := batches[g]
+		if !ok {
+			b = &batch{}
+			batches[g] = b
+		}
+		op.SlotID = slot.ID
+		b.indices = append(b.indices, i)
+		b.ops = append(b.ops, op)
+	}
+	return batches, errs
+}
+
+// scatter calls fn with the batches of all the groups concurrently.
+func scatter(batches map[Group]*batch, fn func(g Group, b *batch)) {
+	var wg sync.WaitGroup
+	for g, b := range batches {
+		wg.Add(1)
+		go func(g Group, b *batch) {
+			defer wg.Done()
+			fn(g, b)
+		}(g, b)
+	}
+	wg.Wait()
+}
+
+// BatchInsert inserts the members in ops, which may belong to different
+// groups, with one batch per group. The results are in the same order as
+// ops.
+func (l *LWWSet) BatchInsert(ctx context.Context, ops []group.Op) []group.ItemResult {
+	batches, errs := l.split(ctx, ops)
+	results := make([]group.ItemResult, len(ops))
+	for i, err := range errs {
+		results[i].Err = err
+	}
+	scatter(batches, func(g Group, b *batch) {
+		for j, r := range g.BatchInsert(ctx, b.ops) {
+			results[b.indices[j]] = r
+		}
+	})
+	return results
+}
+
+// BatchDelete deletes the members in ops, which may belong to different
+// groups, with one batch per group. The results are in the same order as
+// ops.
+func (l *LWWSet) BatchDelete(ctx context.Context, ops []group.Op) []group.ItemResult {
+	batches, errs := l.split(ctx, ops)
+	results := make([]group.ItemResult, len(ops))
+	for i, err := range errs {
+		results[i].Err = err
+	}
+	scatter(batches, func(g Group, b *batch) {
+		for j, r := range g.BatchDelete(ctx, b.ops) {
+			results[b.indices[j]] = r
+		}
+	})
+	return results
+}
+
+// MultiSelect selects the sets in ops, which may belong to different
+// groups, with one batch per group. The results are in the same order as
+// ops.
+func (l *LWWSet) MultiSelect(ctx context.Context, ops []group.Op) []group.SelectResult {
+	batches, errs := l.split(ctx, ops)
+	results := make([]group.SelectResult, len(ops))
+	for i, err := range errs {
+		results[i].Err = err
+	}
+	scatter(batches, func(g Group, b *batch) {
+		for j, r := range g.MultiSelect(ctx, b.ops) {
+			results[b.indices[j]] = r
+		}
+	})
+	return results
 }
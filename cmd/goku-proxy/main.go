@@ -1,11 +1,16 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"log"
 	"net"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/soheilhy/cmux"
+	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 
 	"github.com/RussellLuo/goku/cluster"
@@ -43,33 +48,130 @@ func serve(srv pb.GokuProxyServer, addr string) error {
 	return m.Serve()
 }
 
+// joinCluster asks the proxy at joinAddr, which is an existing member of
+// the cluster, to add this node as a voter.
+func joinCluster(joinAddr, nodeID, raftAddr string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, joinAddr, grpc.WithInsecure(), grpc.WithBlock())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	out, err := pb.NewGokuProxyClient(conn).JoinNode(ctx, &pb.JoinNodeRequest{NodeId: nodeID, Addr: raftAddr})
+	if err != nil {
+		return err
+	}
+	if out.Error != nil {
+		return errors.New(out.Error.Message)
+	}
+	return nil
+}
+
 func main() {
-	// TODO: Read arguments from flags.
-	writeQuorum := 1
-	readStrategy := ""
-	timeout := 2 * time.Second
-	raftBind := "127.0.0.1:12000"
-	raftDir := "~/node"
-	proxyAddr := ":50051"
-
-	newGroup := func(id int, serverAddrs []cluster.Server) cluster.Group {
+	var (
+		proxyAddr = flag.String("addr", ":50051", "The address to listen on")
+		apiAddr   = flag.String("advertise-addr", "127.0.0.1:50051", "The address advertised to the other proxies, to which the admin commands are forwarded if this node is the leader")
+		nodeID    = flag.String("node-id", "node", "The id of this node in the Raft cluster")
+		raftBind  = flag.String("raft-addr", "127.0.0.1:12000", "The address to bind the Raft communications to")
+		raftDir   = flag.String("raft-dir", "~/node", "The directory to store the Raft data in")
+		join      = flag.String("join", "", "The address of an existing proxy to join (bootstraps a new cluster if empty)")
+
+		writeQuorum         = flag.Int("write-quorum", 1, "The number of the servers in a group that a write must succeed on")
+		readStrategy        = flag.String("read-strategy", group.ReadPrimary, "How the servers in a group are read from: primary, round-robin, random or quorum")
+		readRepairMode      = flag.String("read-repair", "async", "How the stale replicas found by a quorum read are repaired: off, async or blocking")
+		antiEntropyInterval = flag.Duration("anti-entropy-interval", 10*time.Minute, "The interval between two anti-entropy rounds")
+
+		timeout             = flag.Duration("timeout", 2*time.Second, "The timeout of each request to a server")
+		poolSize            = flag.Int("pool-size", 4, "The number of the connections to each server")
+		poolIdleTimeout     = flag.Duration("pool-idle-timeout", 5*time.Minute, "The period after which an idle connection is closed (never if 0)")
+		failureThreshold    = flag.Int("failure-threshold", 3, "The number of the consecutive failures after which a server is ejected")
+		ejectCooldown       = flag.Duration("eject-cooldown", 5*time.Second, "The period before an ejected server is probed again")
+		healthCheckInterval = flag.Duration("health-interval", time.Second, "The interval between two health probes of the servers")
+
+		maxHints        = flag.Int("max-hints", 100000, "The maximum number of hints kept for the failed writes")
+		hintWindow      = flag.Duration("hint-window", 3*time.Hour, "The period after which an undelivered hint expires")
+		handoffInterval = flag.Duration("handoff-interval", 10*time.Second, "The interval between two hint handoffs")
+	)
+	flag.Parse()
+
+	if *antiEntropyInterval <= 0 || *healthCheckInterval <= 0 || *handoffInterval <= 0 {
+		log.Fatalf("anti-entropy-interval, health-interval and handoff-interval must be positive")
+	}
+
+	if *writeQuorum < 1 {
+		log.Fatalf("write-quorum must be positive")
+	}
+	// Every group would be rejected with an unknown read strategy.
+	if _, err := group.NewGroup(0, nil, *writeQuorum, *readStrategy); err != nil {
+		log.Fatalf("invalid read-strategy: %v", err)
+	}
+
+	readRepair, err := group.ParseReadRepair(*readRepairMode)
+	if err != nil {
+		log.Fatalf("invalid read-repair: %v", err)
+	}
+
+	if err := os.MkdirAll(*raftDir, 0755); err != nil {
+		log.Fatalf("failed to create %s: %v", *raftDir, err)
+	}
+	hints, err := group.OpenHintStore(filepath.Join(*raftDir, "hints.db"), *maxHints, *hintWindow)
+	if err != nil {
+		log.Fatalf("failed to open hints: %v", err)
+	}
+	defer hints.Close()
+
+	newGroup := func(id int, serverAddrs, joiningAddrs []cluster.Server, opts cluster.GroupOptions) (cluster.Group, error) {
 		servers := make([]group.Server, len(serverAddrs))
 		for i, sAddr := range serverAddrs {
 			addr := string(sAddr)
-			servers[i] = group.NewServer(addr, timeout, group.NewPool(addr))
+			s := group.NewServer(addr, *timeout, group.NewPool(addr, *poolSize, *poolIdleTimeout))
+			servers[i] = group.NewBreaker(s, *failureThreshold, *ejectCooldown)
+		}
+		g, err := group.NewGroup(id, servers, *writeQuorum, *readStrategy)
+		if err != nil {
+			return nil, err
 		}
-		return group.NewGroup(id, servers, writeQuorum, readStrategy)
+		joining := make([]string, len(joiningAddrs))
+		for i, sAddr := range joiningAddrs {
+			joining[i] = string(sAddr)
+		}
+		g.SetJoining(joining...)
+		g.SetReadRepair(readRepair)
+		g.SetHedge(opts.HedgePercentile)
+		g.SetHints(hints)
+		return g, nil
 	}
 
-	c := cluster.NewCluster("goku-proxy", newGroup, raftBind, raftDir)
-	if err := c.Open(true, "node"); err != nil {
+	c := cluster.NewCluster("goku-proxy", newGroup, *raftBind, *raftDir)
+	c.SetAPIAddr(*apiAddr)
+	if err := c.Open(*join == "", *nodeID); err != nil {
 		panic(err)
 	}
+	if *join != "" {
+		if err := joinCluster(*join, *nodeID, *raftBind); err != nil {
+			log.Fatalf("failed to join %s: %v", *join, err)
+		}
+	}
+
+	ae := group.NewAntiEntropy(c, *antiEntropyInterval)
+	ae.Start()
+	defer ae.Stop()
+
+	ho := group.NewHandoff(c, hints, *handoffInterval)
+	ho.Start()
+	defer ho.Stop()
+
+	hc := group.NewHealthChecker(c, *healthCheckInterval)
+	hc.Start()
+	defer hc.Stop()
 
 	l := NewLWWSet(c)
 
 	proxy := NewProxy(c, l)
-	if err := serve(proxy, proxyAddr); err != nil {
+	if err := serve(proxy, *proxyAddr); err != nil {
 		log.Fatalf("err: %v", err)
 	}
 }
@@ -14,15 +14,50 @@ It has these top-level messages:
 	AddGroupReply
 	DelGroupRequest
 	DelGroupReply
+	AddServerToGroupRequest
+	AddServerToGroupReply
+	RemoveServerFromGroupRequest
+	RemoveServerFromGroupReply
 	AssignSlotsRequest
 	AssignSlotsReply
+	MigrateSlotsRequest
+	MigrateSlotsReply
+	RebalanceRequest
+	SlotMove
+	GroupLoad
+	RebalanceReply
+	GetMigrationStatusRequest
+	SlotMigration
+	GetMigrationStatusReply
+	AbortMigrationRequest
+	AbortMigrationReply
 	InsertRequest
+	ReplicaResult
 	InsertReply
 	DeleteRequest
 	DeleteReply
 	SelectRequest
 	Element
 	SelectReply
+	BatchInsertRequest
+	BatchInsertReply
+	BatchDeleteRequest
+	BatchDeleteReply
+	MultiSelectRequest
+	MultiSelectReply
+	HealthRequest
+	ReplicaHealth
+	GroupHealth
+	HealthReply
+	JoinNodeRequest
+	JoinNodeReply
+	AddNonVoterRequest
+	AddNonVoterReply
+	RemoveNodeRequest
+	RemoveNodeReply
+	ListNodesRequest
+	Node
+	ListNodesReply
 */
 package pb
 
@@ -71,8 +106,10 @@ func (m *Error) GetMessage() string {
 }
 
 type AddGroupRequest struct {
-	GroupId int64    `protobuf:"varint,1,opt,name=group_id,json=groupId" json:"group_id,omitempty"`
-	Servers []string `protobuf:"bytes,2,rep,name=servers" json:"servers,omitempty"`
+	GroupId         int64    `protobuf:"varint,1,opt,name=group_id,json=groupId" json:"group_id,omitempty"`
+	Servers         []string `protobuf:"bytes,2,rep,name=servers" json:"servers,omitempty"`
+	Rebalance       bool     `protobuf:"varint,3,opt,name=rebalance" json:"rebalance,omitempty"`
+	HedgePercentile float64  `protobuf:"fixed64,4,opt,name=hedge_percentile,json=hedgePercentile" json:"hedge_percentile,omitempty"`
 }
 
 func (m *AddGroupRequest) Reset()                    { *m = AddGroupRequest{} }
@@ -94,8 +131,23 @@ func (m *AddGroupRequest) GetServers() []string {
 	return nil
 }
 
+func (m *AddGroupRequest) GetRebalance() bool {
+	if m != nil {
+		return m.Rebalance
+	}
+	return false
+}
+
+func (m *AddGroupRequest) GetHedgePercentile() float64 {
+	if m != nil {
+		return m.HedgePercentile
+	}
+	return 0
+}
+
 type AddGroupReply struct {
-	Error *Error `protobuf:"bytes,1,opt,name=error" json:"error,omitempty"`
+	Error  *Error   `protobuf:"bytes,1,opt,name=error" json:"error,omitempty"`
+	JobIds []string `protobuf:"bytes,2,rep,name=job_ids,json=jobIds" json:"job_ids,omitempty"`
 }
 
 func (m *AddGroupReply) Reset()                    { *m = AddGroupReply{} }
@@ -110,6 +162,13 @@ func (m *AddGroupReply) GetError() *Error {
 	return nil
 }
 
+func (m *AddGroupReply) GetJobIds() []string {
+	if m != nil {
+		return m.JobIds
+	}
+	return nil
+}
+
 type DelGroupRequest struct {
 	GroupId int64 `protobuf:"varint,1,opt,name=group_id,json=groupId" json:"group_id,omitempty"`
 }
@@ -142,6 +201,86 @@ func (m *DelGroupReply) GetError() *Error {
 	return nil
 }
 
+type AddServerToGroupRequest struct {
+	GroupId int64  `protobuf:"varint,1,opt,name=group_id,json=groupId" json:"group_id,omitempty"`
+	Server  string `protobuf:"bytes,2,opt,name=server" json:"server,omitempty"`
+}
+
+func (m *AddServerToGroupRequest) Reset()                    { *m = AddServerToGroupRequest{} }
+func (m *AddServerToGroupRequest) String() string            { return proto.CompactTextString(m) }
+func (*AddServerToGroupRequest) ProtoMessage()               {}
+func (*AddServerToGroupRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *AddServerToGroupRequest) GetGroupId() int64 {
+	if m != nil {
+		return m.GroupId
+	}
+	return 0
+}
+
+func (m *AddServerToGroupRequest) GetServer() string {
+	if m != nil {
+		return m.Server
+	}
+	return ""
+}
+
+type AddServerToGroupReply struct {
+	Error *Error `protobuf:"bytes,1,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *AddServerToGroupReply) Reset()                    { *m = AddServerToGroupReply{} }
+func (m *AddServerToGroupReply) String() string            { return proto.CompactTextString(m) }
+func (*AddServerToGroupReply) ProtoMessage()               {}
+func (*AddServerToGroupReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *AddServerToGroupReply) GetError() *Error {
+	if m != nil {
+		return m.Error
+	}
+	return nil
+}
+
+type RemoveServerFromGroupRequest struct {
+	GroupId int64  `protobuf:"varint,1,opt,name=group_id,json=groupId" json:"group_id,omitempty"`
+	Server  string `protobuf:"bytes,2,opt,name=server" json:"server,omitempty"`
+}
+
+func (m *RemoveServerFromGroupRequest) Reset()                    { *m = RemoveServerFromGroupRequest{} }
+func (m *RemoveServerFromGroupRequest) String() string            { return proto.CompactTextString(m) }
+func (*RemoveServerFromGroupRequest) ProtoMessage()               {}
+func (*RemoveServerFromGroupRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *RemoveServerFromGroupRequest) GetGroupId() int64 {
+	if m != nil {
+		return m.GroupId
+	}
+	return 0
+}
+
+func (m *RemoveServerFromGroupRequest) GetServer() string {
+	if m != nil {
+		return m.Server
+	}
+	return ""
+}
+
+type RemoveServerFromGroupReply struct {
+	Error *Error `protobuf:"bytes,1,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *RemoveServerFromGroupReply) Reset()                    { *m = RemoveServerFromGroupReply{} }
+func (m *RemoveServerFromGroupReply) String() string            { return proto.CompactTextString(m) }
+func (*RemoveServerFromGroupReply) ProtoMessage()               {}
+func (*RemoveServerFromGroupReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *RemoveServerFromGroupReply) GetError() *Error {
+	if m != nil {
+		return m.Error
+	}
+	return nil
+}
+
 type AssignSlotsRequest struct {
 	ToGroupId   int64 `protobuf:"varint,1,opt,name=to_group_id,json=toGroupId" json:"to_group_id,omitempty"`
 	StartSlotId int64 `protobuf:"varint,2,opt,name=start_slot_id,json=startSlotId" json:"start_slot_id,omitempty"`
@@ -151,7 +290,7 @@ type AssignSlotsRequest struct {
 func (m *AssignSlotsRequest) Reset()                    { *m = AssignSlotsRequest{} }
 func (m *AssignSlotsRequest) String() string            { return proto.CompactTextString(m) }
 func (*AssignSlotsRequest) ProtoMessage()               {}
-func (*AssignSlotsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+func (*AssignSlotsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
 
 func (m *AssignSlotsRequest) GetToGroupId() int64 {
 	if m != nil {
@@ -181,7 +320,7 @@ type AssignSlotsReply struct {
 func (m *AssignSlotsReply) Reset()                    { *m = AssignSlotsReply{} }
 func (m *AssignSlotsReply) String() string            { return proto.CompactTextString(m) }
 func (*AssignSlotsReply) ProtoMessage()               {}
-func (*AssignSlotsReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+func (*AssignSlotsReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
 
 func (m *AssignSlotsReply) GetError() *Error {
 	if m != nil {
@@ -190,275 +329,1210 @@ func (m *AssignSlotsReply) GetError() *Error {
 	return nil
 }
 
-type InsertRequest struct {
-	Key         string `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
-	Member      string `protobuf:"bytes,2,opt,name=member" json:"member,omitempty"`
-	TimestampNs int64  `protobuf:"varint,3,opt,name=timestamp_ns,json=timestampNs" json:"timestamp_ns,omitempty"`
-	TtlNs       int64  `protobuf:"varint,4,opt,name=ttl_ns,json=ttlNs" json:"ttl_ns,omitempty"`
+type MigrateSlotsRequest struct {
+	ToGroupId   int64 `protobuf:"varint,1,opt,name=to_group_id,json=toGroupId" json:"to_group_id,omitempty"`
+	StartSlotId int64 `protobuf:"varint,2,opt,name=start_slot_id,json=startSlotId" json:"start_slot_id,omitempty"`
+	StopSlotId  int64 `protobuf:"varint,3,opt,name=stop_slot_id,json=stopSlotId" json:"stop_slot_id,omitempty"`
 }
 
-func (m *InsertRequest) Reset()                    { *m = InsertRequest{} }
-func (m *InsertRequest) String() string            { return proto.CompactTextString(m) }
-func (*InsertRequest) ProtoMessage()               {}
-func (*InsertRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
-
-func (m *InsertRequest) GetKey() string {
-	if m != nil {
-		return m.Key
-	}
-	return ""
-}
+func (m *MigrateSlotsRequest) Reset()                    { *m = MigrateSlotsRequest{} }
+func (m *MigrateSlotsRequest) String() string            { return proto.CompactTextString(m) }
+func (*MigrateSlotsRequest) ProtoMessage()               {}
+func (*MigrateSlotsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
 
-func (m *InsertRequest) GetMember() string {
+func (m *MigrateSlotsRequest) GetToGroupId() int64 {
 	if m != nil {
-		return m.Member
+		return m.ToGroupId
 	}
-	return ""
+	return 0
 }
 
-func (m *InsertRequest) GetTimestampNs() int64 {
+func (m *MigrateSlotsRequest) GetStartSlotId() int64 {
 	if m != nil {
-		return m.TimestampNs
+		return m.StartSlotId
 	}
 	return 0
 }
 
-func (m *InsertRequest) GetTtlNs() int64 {
+func (m *MigrateSlotsRequest) GetStopSlotId() int64 {
 	if m != nil {
-		return m.TtlNs
+		return m.StopSlotId
 	}
 	return 0
 }
 
-type InsertReply struct {
-	Updated bool   `protobuf:"varint,1,opt,name=updated" json:"updated,omitempty"`
-	Error   *Error `protobuf:"bytes,2,opt,name=error" json:"error,omitempty"`
+type MigrateSlotsReply struct {
+	JobId string `protobuf:"bytes,1,opt,name=job_id,json=jobId" json:"job_id,omitempty"`
+	Error *Error `protobuf:"bytes,2,opt,name=error" json:"error,omitempty"`
 }
 
-func (m *InsertReply) Reset()                    { *m = InsertReply{} }
-func (m *InsertReply) String() string            { return proto.CompactTextString(m) }
-func (*InsertReply) ProtoMessage()               {}
-func (*InsertReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+func (m *MigrateSlotsReply) Reset()                    { *m = MigrateSlotsReply{} }
+func (m *MigrateSlotsReply) String() string            { return proto.CompactTextString(m) }
+func (*MigrateSlotsReply) ProtoMessage()               {}
+func (*MigrateSlotsReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
 
-func (m *InsertReply) GetUpdated() bool {
+func (m *MigrateSlotsReply) GetJobId() string {
 	if m != nil {
-		return m.Updated
+		return m.JobId
 	}
-	return false
+	return ""
 }
 
-func (m *InsertReply) GetError() *Error {
+func (m *MigrateSlotsReply) GetError() *Error {
 	if m != nil {
 		return m.Error
 	}
 	return nil
 }
 
-type DeleteRequest struct {
-	Key         string `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
-	Member      string `protobuf:"bytes,2,opt,name=member" json:"member,omitempty"`
-	TimestampNs int64  `protobuf:"varint,3,opt,name=timestamp_ns,json=timestampNs" json:"timestamp_ns,omitempty"`
+type RebalanceRequest struct {
+	Weights     map[int64]float64 `protobuf:"bytes,1,rep,name=weights" json:"weights,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	DryRun      bool              `protobuf:"varint,2,opt,name=dry_run,json=dryRun" json:"dry_run,omitempty"`
+	Concurrency int64             `protobuf:"varint,3,opt,name=concurrency" json:"concurrency,omitempty"`
+	ThrottleNs  int64             `protobuf:"varint,4,opt,name=throttle_ns,json=throttleNs" json:"throttle_ns,omitempty"`
 }
 
-func (m *DeleteRequest) Reset()                    { *m = DeleteRequest{} }
-func (m *DeleteRequest) String() string            { return proto.CompactTextString(m) }
-func (*DeleteRequest) ProtoMessage()               {}
-func (*DeleteRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+func (m *RebalanceRequest) Reset()                    { *m = RebalanceRequest{} }
+func (m *RebalanceRequest) String() string            { return proto.CompactTextString(m) }
+func (*RebalanceRequest) ProtoMessage()               {}
+func (*RebalanceRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
 
-func (m *DeleteRequest) GetKey() string {
+func (m *RebalanceRequest) GetWeights() map[int64]float64 {
 	if m != nil {
-		return m.Key
+		return m.Weights
 	}
-	return ""
+	return nil
 }
 
-func (m *DeleteRequest) GetMember() string {
+func (m *RebalanceRequest) GetDryRun() bool {
 	if m != nil {
-		return m.Member
+		return m.DryRun
 	}
-	return ""
+	return false
 }
 
-func (m *DeleteRequest) GetTimestampNs() int64 {
+func (m *RebalanceRequest) GetConcurrency() int64 {
 	if m != nil {
-		return m.TimestampNs
+		return m.Concurrency
 	}
 	return 0
 }
 
-type DeleteReply struct {
-	Deleted bool   `protobuf:"varint,1,opt,name=deleted" json:"deleted,omitempty"`
-	Error   *Error `protobuf:"bytes,2,opt,name=error" json:"error,omitempty"`
+func (m *RebalanceRequest) GetThrottleNs() int64 {
+	if m != nil {
+		return m.ThrottleNs
+	}
+	return 0
 }
 
-func (m *DeleteReply) Reset()                    { *m = DeleteReply{} }
-func (m *DeleteReply) String() string            { return proto.CompactTextString(m) }
-func (*DeleteReply) ProtoMessage()               {}
-func (*DeleteReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+type SlotMove struct {
+	SlotId      int64 `protobuf:"varint,1,opt,name=slot_id,json=slotId" json:"slot_id,omitempty"`
+	FromGroupId int64 `protobuf:"varint,2,opt,name=from_group_id,json=fromGroupId" json:"from_group_id,omitempty"`
+	ToGroupId   int64 `protobuf:"varint,3,opt,name=to_group_id,json=toGroupId" json:"to_group_id,omitempty"`
+	Size        int64 `protobuf:"varint,4,opt,name=size" json:"size,omitempty"`
+}
 
-func (m *DeleteReply) GetDeleted() bool {
+func (m *SlotMove) Reset()                    { *m = SlotMove{} }
+func (m *SlotMove) String() string            { return proto.CompactTextString(m) }
+func (*SlotMove) ProtoMessage()               {}
+func (*SlotMove) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *SlotMove) GetSlotId() int64 {
 	if m != nil {
-		return m.Deleted
+		return m.SlotId
 	}
-	return false
+	return 0
 }
 
-func (m *DeleteReply) GetError() *Error {
+func (m *SlotMove) GetFromGroupId() int64 {
 	if m != nil {
-		return m.Error
+		return m.FromGroupId
 	}
-	return nil
-}
-
-type SelectRequest struct {
-	Key         string `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
-	TimestampNs int64  `protobuf:"varint,2,opt,name=timestamp_ns,json=timestampNs" json:"timestamp_ns,omitempty"`
+	return 0
 }
 
-func (m *SelectRequest) Reset()                    { *m = SelectRequest{} }
-func (m *SelectRequest) String() string            { return proto.CompactTextString(m) }
-func (*SelectRequest) ProtoMessage()               {}
-func (*SelectRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
-
-func (m *SelectRequest) GetKey() string {
+func (m *SlotMove) GetToGroupId() int64 {
 	if m != nil {
-		return m.Key
+		return m.ToGroupId
 	}
-	return ""
+	return 0
 }
 
-func (m *SelectRequest) GetTimestampNs() int64 {
+func (m *SlotMove) GetSize() int64 {
 	if m != nil {
-		return m.TimestampNs
+		return m.Size
 	}
 	return 0
 }
 
-type Element struct {
-	Member      string `protobuf:"bytes,3,opt,name=member" json:"member,omitempty"`
-	TimestampNs int64  `protobuf:"varint,4,opt,name=timestamp_ns,json=timestampNs" json:"timestamp_ns,omitempty"`
-	TtlNs       int64  `protobuf:"varint,5,opt,name=ttl_ns,json=ttlNs" json:"ttl_ns,omitempty"`
+type GroupLoad struct {
+	GroupId int64 `protobuf:"varint,1,opt,name=group_id,json=groupId" json:"group_id,omitempty"`
+	Before  int64 `protobuf:"varint,2,opt,name=before" json:"before,omitempty"`
+	After   int64 `protobuf:"varint,3,opt,name=after" json:"after,omitempty"`
 }
 
-func (m *Element) Reset()                    { *m = Element{} }
-func (m *Element) String() string            { return proto.CompactTextString(m) }
-func (*Element) ProtoMessage()               {}
-func (*Element) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+func (m *GroupLoad) Reset()                    { *m = GroupLoad{} }
+func (m *GroupLoad) String() string            { return proto.CompactTextString(m) }
+func (*GroupLoad) ProtoMessage()               {}
+func (*GroupLoad) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
 
-func (m *Element) GetMember() string {
+func (m *GroupLoad) GetGroupId() int64 {
 	if m != nil {
-		return m.Member
+		return m.GroupId
 	}
-	return ""
+	return 0
 }
 
-func (m *Element) GetTimestampNs() int64 {
+func (m *GroupLoad) GetBefore() int64 {
 	if m != nil {
-		return m.TimestampNs
+		return m.Before
 	}
 	return 0
 }
 
-func (m *Element) GetTtlNs() int64 {
+func (m *GroupLoad) GetAfter() int64 {
 	if m != nil {
-		return m.TtlNs
+		return m.After
 	}
 	return 0
 }
 
-type SelectReply struct {
-	Elements []*Element `protobuf:"bytes,1,rep,name=elements" json:"elements,omitempty"`
-	Error    *Error     `protobuf:"bytes,2,opt,name=error" json:"error,omitempty"`
+type RebalanceReply struct {
+	Moves  []*SlotMove  `protobuf:"bytes,1,rep,name=moves" json:"moves,omitempty"`
+	Loads  []*GroupLoad `protobuf:"bytes,2,rep,name=loads" json:"loads,omitempty"`
+	Error  *Error       `protobuf:"bytes,3,opt,name=error" json:"error,omitempty"`
+	JobIds []string     `protobuf:"bytes,4,rep,name=job_ids,json=jobIds" json:"job_ids,omitempty"`
 }
 
-func (m *SelectReply) Reset()                    { *m = SelectReply{} }
-func (m *SelectReply) String() string            { return proto.CompactTextString(m) }
-func (*SelectReply) ProtoMessage()               {}
-func (*SelectReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+func (m *RebalanceReply) Reset()                    { *m = RebalanceReply{} }
+func (m *RebalanceReply) String() string            { return proto.CompactTextString(m) }
+func (*RebalanceReply) ProtoMessage()               {}
+func (*RebalanceReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
 
-func (m *SelectReply) GetElements() []*Element {
+func (m *RebalanceReply) GetMoves() []*SlotMove {
 	if m != nil {
-		return m.Elements
+		return m.Moves
 	}
 	return nil
 }
 
-func (m *SelectReply) GetError() *Error {
+func (m *RebalanceReply) GetLoads() []*GroupLoad {
 	if m != nil {
-		return m.Error
+		return m.Loads
 	}
 	return nil
 }
 
-func init() {
-	proto.RegisterType((*Error)(nil), "pb.Error")
-	proto.RegisterType((*AddGroupRequest)(nil), "pb.AddGroupRequest")
-	proto.RegisterType((*AddGroupReply)(nil), "pb.AddGroupReply")
-	proto.RegisterType((*DelGroupRequest)(nil), "pb.DelGroupRequest")
-	proto.RegisterType((*DelGroupReply)(nil), "pb.DelGroupReply")
-	proto.RegisterType((*AssignSlotsRequest)(nil), "pb.AssignSlotsRequest")
-	proto.RegisterType((*AssignSlotsReply)(nil), "pb.AssignSlotsReply")
-	proto.RegisterType((*InsertRequest)(nil), "pb.InsertRequest")
-	proto.RegisterType((*InsertReply)(nil), "pb.InsertReply")
-	proto.RegisterType((*DeleteRequest)(nil), "pb.DeleteRequest")
-	proto.RegisterType((*DeleteReply)(nil), "pb.DeleteReply")
-	proto.RegisterType((*SelectRequest)(nil), "pb.SelectRequest")
-	proto.RegisterType((*Element)(nil), "pb.Element")
-	proto.RegisterType((*SelectReply)(nil), "pb.SelectReply")
+func (m *RebalanceReply) GetError() *Error {
+	if m != nil {
+		return m.Error
+	}
+	return nil
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+func (m *RebalanceReply) GetJobIds() []string {
+	if m != nil {
+		return m.JobIds
+	}
+	return nil
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion3
+type GetMigrationStatusRequest struct {
+	JobId string `protobuf:"bytes,1,opt,name=job_id,json=jobId" json:"job_id,omitempty"`
+}
 
-// Client API for GokuProxy service
+func (m *GetMigrationStatusRequest) Reset()                    { *m = GetMigrationStatusRequest{} }
+func (m *GetMigrationStatusRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetMigrationStatusRequest) ProtoMessage()               {}
+func (*GetMigrationStatusRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
 
-type GokuProxyClient interface {
-	AddGroup(ctx context.Context, in *AddGroupRequest, opts ...grpc.CallOption) (*AddGroupReply, error)
-	DelGroup(ctx context.Context, in *DelGroupRequest, opts ...grpc.CallOption) (*DelGroupReply, error)
-	AssignSlots(ctx context.Context, in *AssignSlotsRequest, opts ...grpc.CallOption) (*AssignSlotsReply, error)
-	Insert(ctx context.Context, in *InsertRequest, opts ...grpc.CallOption) (*InsertReply, error)
-	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteReply, error)
-	Select(ctx context.Context, in *SelectRequest, opts ...grpc.CallOption) (*SelectReply, error)
+func (m *GetMigrationStatusRequest) GetJobId() string {
+	if m != nil {
+		return m.JobId
+	}
+	return ""
 }
 
-type gokuProxyClient struct {
-	cc *grpc.ClientConn
+type SlotMigration struct {
+	SlotId int64  `protobuf:"varint,1,opt,name=slot_id,json=slotId" json:"slot_id,omitempty"`
+	Phase  string `protobuf:"bytes,2,opt,name=phase" json:"phase,omitempty"`
+	Error  *Error `protobuf:"bytes,3,opt,name=error" json:"error,omitempty"`
 }
 
-func NewGokuProxyClient(cc *grpc.ClientConn) GokuProxyClient {
-	return &gokuProxyClient{cc}
+func (m *SlotMigration) Reset()                    { *m = SlotMigration{} }
+func (m *SlotMigration) String() string            { return proto.CompactTextString(m) }
+func (*SlotMigration) ProtoMessage()               {}
+func (*SlotMigration) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+
+func (m *SlotMigration) GetSlotId() int64 {
+	if m != nil {
+		return m.SlotId
+	}
+	return 0
 }
 
-func (c *gokuProxyClient) AddGroup(ctx context.Context, in *AddGroupRequest, opts ...grpc.CallOption) (*AddGroupReply, error) {
-	out := new(AddGroupReply)
-	err := grpc.Invoke(ctx, "/pb.GokuProxy/AddGroup", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+func (m *SlotMigration) GetPhase() string {
+	if m != nil {
+		return m.Phase
 	}
-	return out, nil
+	return ""
 }
 
-func (c *gokuProxyClient) DelGroup(ctx context.Context, in *DelGroupRequest, opts ...grpc.CallOption) (*DelGroupReply, error) {
-	out := new(DelGroupReply)
-	err := grpc.Invoke(ctx, "/pb.GokuProxy/DelGroup", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+func (m *SlotMigration) GetError() *Error {
+	if m != nil {
+		return m.Error
 	}
-	return out, nil
+	return nil
 }
 
-func (c *gokuProxyClient) AssignSlots(ctx context.Context, in *AssignSlotsRequest, opts ...grpc.CallOption) (*AssignSlotsReply, error) {
-	out := new(AssignSlotsReply)
-	err := grpc.Invoke(ctx, "/pb.GokuProxy/AssignSlots", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+type GetMigrationStatusReply struct {
+	JobId       string           `protobuf:"bytes,1,opt,name=job_id,json=jobId" json:"job_id,omitempty"`
+	ToGroupId   int64            `protobuf:"varint,2,opt,name=to_group_id,json=toGroupId" json:"to_group_id,omitempty"`
+	Slots       []*SlotMigration `protobuf:"bytes,3,rep,name=slots" json:"slots,omitempty"`
+	Done        bool             `protobuf:"varint,4,opt,name=done" json:"done,omitempty"`
+	StartTimeNs int64            `protobuf:"varint,5,opt,name=start_time_ns,json=startTimeNs" json:"start_time_ns,omitempty"`
+	EndTimeNs   int64            `protobuf:"varint,6,opt,name=end_time_ns,json=endTimeNs" json:"end_time_ns,omitempty"`
+	Error       *Error           `protobuf:"bytes,7,opt,name=error" json:"error,omitempty"`
+	Aborted     bool             `protobuf:"varint,8,opt,name=aborted" json:"aborted,omitempty"`
+	After       string           `protobuf:"bytes,9,opt,name=after" json:"after,omitempty"`
+}
+
+func (m *GetMigrationStatusReply) Reset()                    { *m = GetMigrationStatusReply{} }
+func (m *GetMigrationStatusReply) String() string            { return proto.CompactTextString(m) }
+func (*GetMigrationStatusReply) ProtoMessage()               {}
+func (*GetMigrationStatusReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+
+func (m *GetMigrationStatusReply) GetJobId() string {
+	if m != nil {
+		return m.JobId
 	}
-	return out, nil
+	return ""
+}
+
+func (m *GetMigrationStatusReply) GetToGroupId() int64 {
+	if m != nil {
+		return m.ToGroupId
+	}
+	return 0
+}
+
+func (m *GetMigrationStatusReply) GetSlots() []*SlotMigration {
+	if m != nil {
+		return m.Slots
+	}
+	return nil
+}
+
+func (m *GetMigrationStatusReply) GetDone() bool {
+	if m != nil {
+		return m.Done
+	}
+	return false
+}
+
+func (m *GetMigrationStatusReply) GetStartTimeNs() int64 {
+	if m != nil {
+		return m.StartTimeNs
+	}
+	return 0
+}
+
+func (m *GetMigrationStatusReply) GetEndTimeNs() int64 {
+	if m != nil {
+		return m.EndTimeNs
+	}
+	return 0
+}
+
+func (m *GetMigrationStatusReply) GetError() *Error {
+	if m != nil {
+		return m.Error
+	}
+	return nil
+}
+
+func (m *GetMigrationStatusReply) GetAborted() bool {
+	if m != nil {
+		return m.Aborted
+	}
+	return false
+}
+
+func (m *GetMigrationStatusReply) GetAfter() string {
+	if m != nil {
+		return m.After
+	}
+	return ""
+}
+
+type AbortMigrationRequest struct {
+	JobId string `protobuf:"bytes,1,opt,name=job_id,json=jobId" json:"job_id,omitempty"`
+}
+
+func (m *AbortMigrationRequest) Reset()                    { *m = AbortMigrationRequest{} }
+func (m *AbortMigrationRequest) String() string            { return proto.CompactTextString(m) }
+func (*AbortMigrationRequest) ProtoMessage()               {}
+func (*AbortMigrationRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+
+func (m *AbortMigrationRequest) GetJobId() string {
+	if m != nil {
+		return m.JobId
+	}
+	return ""
+}
+
+type AbortMigrationReply struct {
+	Error *Error `protobuf:"bytes,1,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *AbortMigrationReply) Reset()                    { *m = AbortMigrationReply{} }
+func (m *AbortMigrationReply) String() string            { return proto.CompactTextString(m) }
+func (*AbortMigrationReply) ProtoMessage()               {}
+func (*AbortMigrationReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
+
+func (m *AbortMigrationReply) GetError() *Error {
+	if m != nil {
+		return m.Error
+	}
+	return nil
+}
+
+type InsertRequest struct {
+	Key         string `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
+	Member      string `protobuf:"bytes,2,opt,name=member" json:"member,omitempty"`
+	TimestampNs int64  `protobuf:"varint,3,opt,name=timestamp_ns,json=timestampNs" json:"timestamp_ns,omitempty"`
+	TtlNs       int64  `protobuf:"varint,4,opt,name=ttl_ns,json=ttlNs" json:"ttl_ns,omitempty"`
+}
+
+func (m *InsertRequest) Reset()                    { *m = InsertRequest{} }
+func (m *InsertRequest) String() string            { return proto.CompactTextString(m) }
+func (*InsertRequest) ProtoMessage()               {}
+func (*InsertRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+
+func (m *InsertRequest) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *InsertRequest) GetMember() string {
+	if m != nil {
+		return m.Member
+	}
+	return ""
+}
+
+func (m *InsertRequest) GetTimestampNs() int64 {
+	if m != nil {
+		return m.TimestampNs
+	}
+	return 0
+}
+
+func (m *InsertRequest) GetTtlNs() int64 {
+	if m != nil {
+		return m.TtlNs
+	}
+	return 0
+}
+
+type ReplicaResult struct {
+	Addr      string `protobuf:"bytes,1,opt,name=addr" json:"addr,omitempty"`
+	Pending   bool   `protobuf:"varint,2,opt,name=pending" json:"pending,omitempty"`
+	Status    bool   `protobuf:"varint,3,opt,name=status" json:"status,omitempty"`
+	Error     *Error `protobuf:"bytes,4,opt,name=error" json:"error,omitempty"`
+	LatencyNs int64  `protobuf:"varint,5,opt,name=latency_ns,json=latencyNs" json:"latency_ns,omitempty"`
+	Joining   bool   `protobuf:"varint,6,opt,name=joining" json:"joining,omitempty"`
+}
+
+func (m *ReplicaResult) Reset()                    { *m = ReplicaResult{} }
+func (m *ReplicaResult) String() string            { return proto.CompactTextString(m) }
+func (*ReplicaResult) ProtoMessage()               {}
+func (*ReplicaResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+
+func (m *ReplicaResult) GetAddr() string {
+	if m != nil {
+		return m.Addr
+	}
+	return ""
+}
+
+func (m *ReplicaResult) GetPending() bool {
+	if m != nil {
+		return m.Pending
+	}
+	return false
+}
+
+func (m *ReplicaResult) GetStatus() bool {
+	if m != nil {
+		return m.Status
+	}
+	return false
+}
+
+func (m *ReplicaResult) GetError() *Error {
+	if m != nil {
+		return m.Error
+	}
+	return nil
+}
+
+func (m *ReplicaResult) GetLatencyNs() int64 {
+	if m != nil {
+		return m.LatencyNs
+	}
+	return 0
+}
+
+func (m *ReplicaResult) GetJoining() bool {
+	if m != nil {
+		return m.Joining
+	}
+	return false
+}
+
+type InsertReply struct {
+	Updated  bool             `protobuf:"varint,1,opt,name=updated" json:"updated,omitempty"`
+	Error    *Error           `protobuf:"bytes,2,opt,name=error" json:"error,omitempty"`
+	Replicas []*ReplicaResult `protobuf:"bytes,3,rep,name=replicas" json:"replicas,omitempty"`
+}
+
+func (m *InsertReply) Reset()                    { *m = InsertReply{} }
+func (m *InsertReply) String() string            { return proto.CompactTextString(m) }
+func (*InsertReply) ProtoMessage()               {}
+func (*InsertReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
+
+func (m *InsertReply) GetUpdated() bool {
+	if m != nil {
+		return m.Updated
+	}
+	return false
+}
+
+func (m *InsertReply) GetError() *Error {
+	if m != nil {
+		return m.Error
+	}
+	return nil
+}
+
+func (m *InsertReply) GetReplicas() []*ReplicaResult {
+	if m != nil {
+		return m.Replicas
+	}
+	return nil
+}
+
+type DeleteRequest struct {
+	Key         string `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
+	Member      string `protobuf:"bytes,2,opt,name=member" json:"member,omitempty"`
+	TimestampNs int64  `protobuf:"varint,3,opt,name=timestamp_ns,json=timestampNs" json:"timestamp_ns,omitempty"`
+}
+
+func (m *DeleteRequest) Reset()                    { *m = DeleteRequest{} }
+func (m *DeleteRequest) String() string            { return proto.CompactTextString(m) }
+func (*DeleteRequest) ProtoMessage()               {}
+func (*DeleteRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
+
+func (m *DeleteRequest) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *DeleteRequest) GetMember() string {
+	if m != nil {
+		return m.Member
+	}
+	return ""
+}
+
+func (m *DeleteRequest) GetTimestampNs() int64 {
+	if m != nil {
+		return m.TimestampNs
+	}
+	return 0
+}
+
+type DeleteReply struct {
+	Deleted  bool             `protobuf:"varint,1,opt,name=deleted" json:"deleted,omitempty"`
+	Error    *Error           `protobuf:"bytes,2,opt,name=error" json:"error,omitempty"`
+	Replicas []*ReplicaResult `protobuf:"bytes,3,rep,name=replicas" json:"replicas,omitempty"`
+}
+
+func (m *DeleteReply) Reset()                    { *m = DeleteReply{} }
+func (m *DeleteReply) String() string            { return proto.CompactTextString(m) }
+func (*DeleteReply) ProtoMessage()               {}
+func (*DeleteReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
+
+func (m *DeleteReply) GetDeleted() bool {
+	if m != nil {
+		return m.Deleted
+	}
+	return false
+}
+
+func (m *DeleteReply) GetError() *Error {
+	if m != nil {
+		return m.Error
+	}
+	return nil
+}
+
+func (m *DeleteReply) GetReplicas() []*ReplicaResult {
+	if m != nil {
+		return m.Replicas
+	}
+	return nil
+}
+
+type SelectRequest struct {
+	Key         string `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
+	TimestampNs int64  `protobuf:"varint,2,opt,name=timestamp_ns,json=timestampNs" json:"timestamp_ns,omitempty"`
+}
+
+func (m *SelectRequest) Reset()                    { *m = SelectRequest{} }
+func (m *SelectRequest) String() string            { return proto.CompactTextString(m) }
+func (*SelectRequest) ProtoMessage()               {}
+func (*SelectRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
+
+func (m *SelectRequest) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *SelectRequest) GetTimestampNs() int64 {
+	if m != nil {
+		return m.TimestampNs
+	}
+	return 0
+}
+
+type Element struct {
+	Member      string `protobuf:"bytes,3,opt,name=member" json:"member,omitempty"`
+	TimestampNs int64  `protobuf:"varint,4,opt,name=timestamp_ns,json=timestampNs" json:"timestamp_ns,omitempty"`
+	TtlNs       int64  `protobuf:"varint,5,opt,name=ttl_ns,json=ttlNs" json:"ttl_ns,omitempty"`
+}
+
+func (m *Element) Reset()                    { *m = Element{} }
+func (m *Element) String() string            { return proto.CompactTextString(m) }
+func (*Element) ProtoMessage()               {}
+func (*Element) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{28} }
+
+func (m *Element) GetMember() string {
+	if m != nil {
+		return m.Member
+	}
+	return ""
+}
+
+func (m *Element) GetTimestampNs() int64 {
+	if m != nil {
+		return m.TimestampNs
+	}
+	return 0
+}
+
+func (m *Element) GetTtlNs() int64 {
+	if m != nil {
+		return m.TtlNs
+	}
+	return 0
+}
+
+type SelectReply struct {
+	Elements []*Element `protobuf:"bytes,1,rep,name=elements" json:"elements,omitempty"`
+	Error    *Error     `protobuf:"bytes,2,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *SelectReply) Reset()                    { *m = SelectReply{} }
+func (m *SelectReply) String() string            { return proto.CompactTextString(m) }
+func (*SelectReply) ProtoMessage()               {}
+func (*SelectReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{29} }
+
+func (m *SelectReply) GetElements() []*Element {
+	if m != nil {
+		return m.Elements
+	}
+	return nil
+}
+
+func (m *SelectReply) GetError() *Error {
+	if m != nil {
+		return m.Error
+	}
+	return nil
+}
+
+type BatchInsertRequest struct {
+	Items []*InsertRequest `protobuf:"bytes,1,rep,name=items" json:"items,omitempty"`
+}
+
+func (m *BatchInsertRequest) Reset()                    { *m = BatchInsertRequest{} }
+func (m *BatchInsertRequest) String() string            { return proto.CompactTextString(m) }
+func (*BatchInsertRequest) ProtoMessage()               {}
+func (*BatchInsertRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{30} }
+
+func (m *BatchInsertRequest) GetItems() []*InsertRequest {
+	if m != nil {
+		return m.Items
+	}
+	return nil
+}
+
+type BatchInsertReply struct {
+	Items []*InsertReply `protobuf:"bytes,1,rep,name=items" json:"items,omitempty"`
+}
+
+func (m *BatchInsertReply) Reset()                    { *m = BatchInsertReply{} }
+func (m *BatchInsertReply) String() string            { return proto.CompactTextString(m) }
+func (*BatchInsertReply) ProtoMessage()               {}
+func (*BatchInsertReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{31} }
+
+func (m *BatchInsertReply) GetItems() []*InsertReply {
+	if m != nil {
+		return m.Items
+	}
+	return nil
+}
+
+type BatchDeleteRequest struct {
+	Items []*DeleteRequest `protobuf:"bytes,1,rep,name=items" json:"items,omitempty"`
+}
+
+func (m *BatchDeleteRequest) Reset()                    { *m = BatchDeleteRequest{} }
+func (m *BatchDeleteRequest) String() string            { return proto.CompactTextString(m) }
+func (*BatchDeleteRequest) ProtoMessage()               {}
+func (*BatchDeleteRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{32} }
+
+func (m *BatchDeleteRequest) GetItems() []*DeleteRequest {
+	if m != nil {
+		return m.Items
+	}
+	return nil
+}
+
+type BatchDeleteReply struct {
+	Items []*DeleteReply `protobuf:"bytes,1,rep,name=items" json:"items,omitempty"`
+}
+
+func (m *BatchDeleteReply) Reset()                    { *m = BatchDeleteReply{} }
+func (m *BatchDeleteReply) String() string            { return proto.CompactTextString(m) }
+func (*BatchDeleteReply) ProtoMessage()               {}
+func (*BatchDeleteReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{33} }
+
+func (m *BatchDeleteReply) GetItems() []*DeleteReply {
+	if m != nil {
+		return m.Items
+	}
+	return nil
+}
+
+type MultiSelectRequest struct {
+	Items []*SelectRequest `protobuf:"bytes,1,rep,name=items" json:"items,omitempty"`
+}
+
+func (m *MultiSelectRequest) Reset()                    { *m = MultiSelectRequest{} }
+func (m *MultiSelectRequest) String() string            { return proto.CompactTextString(m) }
+func (*MultiSelectRequest) ProtoMessage()               {}
+func (*MultiSelectRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34} }
+
+func (m *MultiSelectRequest) GetItems() []*SelectRequest {
+	if m != nil {
+		return m.Items
+	}
+	return nil
+}
+
+type MultiSelectReply struct {
+	Items []*SelectReply `protobuf:"bytes,1,rep,name=items" json:"items,omitempty"`
+}
+
+func (m *MultiSelectReply) Reset()                    { *m = MultiSelectReply{} }
+func (m *MultiSelectReply) String() string            { return proto.CompactTextString(m) }
+func (*MultiSelectReply) ProtoMessage()               {}
+func (*MultiSelectReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{35} }
+
+func (m *MultiSelectReply) GetItems() []*SelectReply {
+	if m != nil {
+		return m.Items
+	}
+	return nil
+}
+
+type HealthRequest struct {
+	GroupIds []int64 `protobuf:"varint,1,rep,packed,name=group_ids,json=groupIds" json:"group_ids,omitempty"`
+}
+
+func (m *HealthRequest) Reset()                    { *m = HealthRequest{} }
+func (m *HealthRequest) String() string            { return proto.CompactTextString(m) }
+func (*HealthRequest) ProtoMessage()               {}
+func (*HealthRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{36} }
+
+func (m *HealthRequest) GetGroupIds() []int64 {
+	if m != nil {
+		return m.GroupIds
+	}
+	return nil
+}
+
+type ReplicaHealth struct {
+	Addr     string `protobuf:"bytes,1,opt,name=addr" json:"addr,omitempty"`
+	State    string `protobuf:"bytes,2,opt,name=state" json:"state,omitempty"`
+	Failures int64  `protobuf:"varint,3,opt,name=failures" json:"failures,omitempty"`
+	Error    *Error `protobuf:"bytes,4,opt,name=error" json:"error,omitempty"`
+	SinceNs  int64  `protobuf:"varint,5,opt,name=since_ns,json=sinceNs" json:"since_ns,omitempty"`
+}
+
+func (m *ReplicaHealth) Reset()                    { *m = ReplicaHealth{} }
+func (m *ReplicaHealth) String() string            { return proto.CompactTextString(m) }
+func (*ReplicaHealth) ProtoMessage()               {}
+func (*ReplicaHealth) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{37} }
+
+func (m *ReplicaHealth) GetAddr() string {
+	if m != nil {
+		return m.Addr
+	}
+	return ""
+}
+
+func (m *ReplicaHealth) GetState() string {
+	if m != nil {
+		return m.State
+	}
+	return ""
+}
+
+func (m *ReplicaHealth) GetFailures() int64 {
+	if m != nil {
+		return m.Failures
+	}
+	return 0
+}
+
+func (m *ReplicaHealth) GetError() *Error {
+	if m != nil {
+		return m.Error
+	}
+	return nil
+}
+
+func (m *ReplicaHealth) GetSinceNs() int64 {
+	if m != nil {
+		return m.SinceNs
+	}
+	return 0
+}
+
+type GroupHealth struct {
+	GroupId  int64            `protobuf:"varint,1,opt,name=group_id,json=groupId" json:"group_id,omitempty"`
+	Replicas []*ReplicaHealth `protobuf:"bytes,2,rep,name=replicas" json:"replicas,omitempty"`
+}
+
+func (m *GroupHealth) Reset()                    { *m = GroupHealth{} }
+func (m *GroupHealth) String() string            { return proto.CompactTextString(m) }
+func (*GroupHealth) ProtoMessage()               {}
+func (*GroupHealth) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{38} }
+
+func (m *GroupHealth) GetGroupId() int64 {
+	if m != nil {
+		return m.GroupId
+	}
+	return 0
+}
+
+func (m *GroupHealth) GetReplicas() []*ReplicaHealth {
+	if m != nil {
+		return m.Replicas
+	}
+	return nil
+}
+
+type HealthReply struct {
+	Groups []*GroupHealth `protobuf:"bytes,1,rep,name=groups" json:"groups,omitempty"`
+	Error  *Error         `protobuf:"bytes,2,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *HealthReply) Reset()                    { *m = HealthReply{} }
+func (m *HealthReply) String() string            { return proto.CompactTextString(m) }
+func (*HealthReply) ProtoMessage()               {}
+func (*HealthReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{39} }
+
+func (m *HealthReply) GetGroups() []*GroupHealth {
+	if m != nil {
+		return m.Groups
+	}
+	return nil
+}
+
+func (m *HealthReply) GetError() *Error {
+	if m != nil {
+		return m.Error
+	}
+	return nil
+}
+
+type JoinNodeRequest struct {
+	NodeId string `protobuf:"bytes,1,opt,name=node_id,json=nodeId" json:"node_id,omitempty"`
+	Addr   string `protobuf:"bytes,2,opt,name=addr" json:"addr,omitempty"`
+}
+
+func (m *JoinNodeRequest) Reset()                    { *m = JoinNodeRequest{} }
+func (m *JoinNodeRequest) String() string            { return proto.CompactTextString(m) }
+func (*JoinNodeRequest) ProtoMessage()               {}
+func (*JoinNodeRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{40} }
+
+func (m *JoinNodeRequest) GetNodeId() string {
+	if m != nil {
+		return m.NodeId
+	}
+	return ""
+}
+
+func (m *JoinNodeRequest) GetAddr() string {
+	if m != nil {
+		return m.Addr
+	}
+	return ""
+}
+
+type JoinNodeReply struct {
+	Error *Error `protobuf:"bytes,1,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *JoinNodeReply) Reset()                    { *m = JoinNodeReply{} }
+func (m *JoinNodeReply) String() string            { return proto.CompactTextString(m) }
+func (*JoinNodeReply) ProtoMessage()               {}
+func (*JoinNodeReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{41} }
+
+func (m *JoinNodeReply) GetError() *Error {
+	if m != nil {
+		return m.Error
+	}
+	return nil
+}
+
+type AddNonVoterRequest struct {
+	NodeId string `protobuf:"bytes,1,opt,name=node_id,json=nodeId" json:"node_id,omitempty"`
+	Addr   string `protobuf:"bytes,2,opt,name=addr" json:"addr,omitempty"`
+}
+
+func (m *AddNonVoterRequest) Reset()                    { *m = AddNonVoterRequest{} }
+func (m *AddNonVoterRequest) String() string            { return proto.CompactTextString(m) }
+func (*AddNonVoterRequest) ProtoMessage()               {}
+func (*AddNonVoterRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{42} }
+
+func (m *AddNonVoterRequest) GetNodeId() string {
+	if m != nil {
+		return m.NodeId
+	}
+	return ""
+}
+
+func (m *AddNonVoterRequest) GetAddr() string {
+	if m != nil {
+		return m.Addr
+	}
+	return ""
+}
+
+type AddNonVoterReply struct {
+	Error *Error `protobuf:"bytes,1,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *AddNonVoterReply) Reset()                    { *m = AddNonVoterReply{} }
+func (m *AddNonVoterReply) String() string            { return proto.CompactTextString(m) }
+func (*AddNonVoterReply) ProtoMessage()               {}
+func (*AddNonVoterReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{43} }
+
+func (m *AddNonVoterReply) GetError() *Error {
+	if m != nil {
+		return m.Error
+	}
+	return nil
+}
+
+type RemoveNodeRequest struct {
+	NodeId string `protobuf:"bytes,1,opt,name=node_id,json=nodeId" json:"node_id,omitempty"`
+}
+
+func (m *RemoveNodeRequest) Reset()                    { *m = RemoveNodeRequest{} }
+func (m *RemoveNodeRequest) String() string            { return proto.CompactTextString(m) }
+func (*RemoveNodeRequest) ProtoMessage()               {}
+func (*RemoveNodeRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{44} }
+
+func (m *RemoveNodeRequest) GetNodeId() string {
+	if m != nil {
+		return m.NodeId
+	}
+	return ""
+}
+
+type RemoveNodeReply struct {
+	Error *Error `protobuf:"bytes,1,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *RemoveNodeReply) Reset()                    { *m = RemoveNodeReply{} }
+func (m *RemoveNodeReply) String() string            { return proto.CompactTextString(m) }
+func (*RemoveNodeReply) ProtoMessage()               {}
+func (*RemoveNodeReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{45} }
+
+func (m *RemoveNodeReply) GetError() *Error {
+	if m != nil {
+		return m.Error
+	}
+	return nil
+}
+
+type ListNodesRequest struct {
+}
+
+func (m *ListNodesRequest) Reset()                    { *m = ListNodesRequest{} }
+func (m *ListNodesRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListNodesRequest) ProtoMessage()               {}
+func (*ListNodesRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{46} }
+
+type Node struct {
+	Id     string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Addr   string `protobuf:"bytes,2,opt,name=addr" json:"addr,omitempty"`
+	Voter  bool   `protobuf:"varint,3,opt,name=voter" json:"voter,omitempty"`
+	Leader bool   `protobuf:"varint,4,opt,name=leader" json:"leader,omitempty"`
+}
+
+func (m *Node) Reset()                    { *m = Node{} }
+func (m *Node) String() string            { return proto.CompactTextString(m) }
+func (*Node) ProtoMessage()               {}
+func (*Node) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{47} }
+
+func (m *Node) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Node) GetAddr() string {
+	if m != nil {
+		return m.Addr
+	}
+	return ""
+}
+
+func (m *Node) GetVoter() bool {
+	if m != nil {
+		return m.Voter
+	}
+	return false
+}
+
+func (m *Node) GetLeader() bool {
+	if m != nil {
+		return m.Leader
+	}
+	return false
+}
+
+type ListNodesReply struct {
+	Nodes []*Node `protobuf:"bytes,1,rep,name=nodes" json:"nodes,omitempty"`
+	Error *Error  `protobuf:"bytes,2,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *ListNodesReply) Reset()                    { *m = ListNodesReply{} }
+func (m *ListNodesReply) String() string            { return proto.CompactTextString(m) }
+func (*ListNodesReply) ProtoMessage()               {}
+func (*ListNodesReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{48} }
+
+func (m *ListNodesReply) GetNodes() []*Node {
+	if m != nil {
+		return m.Nodes
+	}
+	return nil
+}
+
+func (m *ListNodesReply) GetError() *Error {
+	if m != nil {
+		return m.Error
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Error)(nil), "pb.Error")
+	proto.RegisterType((*AddGroupRequest)(nil), "pb.AddGroupRequest")
+	proto.RegisterType((*AddGroupReply)(nil), "pb.AddGroupReply")
+	proto.RegisterType((*DelGroupRequest)(nil), "pb.DelGroupRequest")
+	proto.RegisterType((*DelGroupReply)(nil), "pb.DelGroupReply")
+	proto.RegisterType((*AddServerToGroupRequest)(nil), "pb.AddServerToGroupRequest")
+	proto.RegisterType((*AddServerToGroupReply)(nil), "pb.AddServerToGroupReply")
+	proto.RegisterType((*RemoveServerFromGroupRequest)(nil), "pb.RemoveServerFromGroupRequest")
+	proto.RegisterType((*RemoveServerFromGroupReply)(nil), "pb.RemoveServerFromGroupReply")
+	proto.RegisterType((*AssignSlotsRequest)(nil), "pb.AssignSlotsRequest")
+	proto.RegisterType((*AssignSlotsReply)(nil), "pb.AssignSlotsReply")
+	proto.RegisterType((*MigrateSlotsRequest)(nil), "pb.MigrateSlotsRequest")
+	proto.RegisterType((*MigrateSlotsReply)(nil), "pb.MigrateSlotsReply")
+	proto.RegisterType((*RebalanceRequest)(nil), "pb.RebalanceRequest")
+	proto.RegisterType((*SlotMove)(nil), "pb.SlotMove")
+	proto.RegisterType((*GroupLoad)(nil), "pb.GroupLoad")
+	proto.RegisterType((*RebalanceReply)(nil), "pb.RebalanceReply")
+	proto.RegisterType((*GetMigrationStatusRequest)(nil), "pb.GetMigrationStatusRequest")
+	proto.RegisterType((*SlotMigration)(nil), "pb.SlotMigration")
+	proto.RegisterType((*GetMigrationStatusReply)(nil), "pb.GetMigrationStatusReply")
+	proto.RegisterType((*AbortMigrationRequest)(nil), "pb.AbortMigrationRequest")
+	proto.RegisterType((*AbortMigrationReply)(nil), "pb.AbortMigrationReply")
+	proto.RegisterType((*InsertRequest)(nil), "pb.InsertRequest")
+	proto.RegisterType((*ReplicaResult)(nil), "pb.ReplicaResult")
+	proto.RegisterType((*InsertReply)(nil), "pb.InsertReply")
+	proto.RegisterType((*DeleteRequest)(nil), "pb.DeleteRequest")
+	proto.RegisterType((*DeleteReply)(nil), "pb.DeleteReply")
+	proto.RegisterType((*SelectRequest)(nil), "pb.SelectRequest")
+	proto.RegisterType((*Element)(nil), "pb.Element")
+	proto.RegisterType((*SelectReply)(nil), "pb.SelectReply")
+	proto.RegisterType((*BatchInsertRequest)(nil), "pb.BatchInsertRequest")
+	proto.RegisterType((*BatchInsertReply)(nil), "pb.BatchInsertReply")
+	proto.RegisterType((*BatchDeleteRequest)(nil), "pb.BatchDeleteRequest")
+	proto.RegisterType((*BatchDeleteReply)(nil), "pb.BatchDeleteReply")
+	proto.RegisterType((*MultiSelectRequest)(nil), "pb.MultiSelectRequest")
+	proto.RegisterType((*MultiSelectReply)(nil), "pb.MultiSelectReply")
+	proto.RegisterType((*HealthRequest)(nil), "pb.HealthRequest")
+	proto.RegisterType((*ReplicaHealth)(nil), "pb.ReplicaHealth")
+	proto.RegisterType((*GroupHealth)(nil), "pb.GroupHealth")
+	proto.RegisterType((*HealthReply)(nil), "pb.HealthReply")
+	proto.RegisterType((*JoinNodeRequest)(nil), "pb.JoinNodeRequest")
+	proto.RegisterType((*JoinNodeReply)(nil), "pb.JoinNodeReply")
+	proto.RegisterType((*AddNonVoterRequest)(nil), "pb.AddNonVoterRequest")
+	proto.RegisterType((*AddNonVoterReply)(nil), "pb.AddNonVoterReply")
+	proto.RegisterType((*RemoveNodeRequest)(nil), "pb.RemoveNodeRequest")
+	proto.RegisterType((*RemoveNodeReply)(nil), "pb.RemoveNodeReply")
+	proto.RegisterType((*ListNodesRequest)(nil), "pb.ListNodesRequest")
+	proto.RegisterType((*Node)(nil), "pb.Node")
+	proto.RegisterType((*ListNodesReply)(nil), "pb.ListNodesReply")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion3
+
+// Client API for GokuProxy service
+
+type GokuProxyClient interface {
+	AddGroup(ctx context.Context, in *AddGroupRequest, opts ...grpc.CallOption) (*AddGroupReply, error)
+	DelGroup(ctx context.Context, in *DelGroupRequest, opts ...grpc.CallOption) (*DelGroupReply, error)
+	AddServerToGroup(ctx context.Context, in *AddServerToGroupRequest, opts ...grpc.CallOption) (*AddServerToGroupReply, error)
+	RemoveServerFromGroup(ctx context.Context, in *RemoveServerFromGroupRequest, opts ...grpc.CallOption) (*RemoveServerFromGroupReply, error)
+	AssignSlots(ctx context.Context, in *AssignSlotsRequest, opts ...grpc.CallOption) (*AssignSlotsReply, error)
+	MigrateSlots(ctx context.Context, in *MigrateSlotsRequest, opts ...grpc.CallOption) (*MigrateSlotsReply, error)
+	GetMigrationStatus(ctx context.Context, in *GetMigrationStatusRequest, opts ...grpc.CallOption) (*GetMigrationStatusReply, error)
+	AbortMigration(ctx context.Context, in *AbortMigrationRequest, opts ...grpc.CallOption) (*AbortMigrationReply, error)
+	Rebalance(ctx context.Context, in *RebalanceRequest, opts ...grpc.CallOption) (*RebalanceReply, error)
+	Insert(ctx context.Context, in *InsertRequest, opts ...grpc.CallOption) (*InsertReply, error)
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteReply, error)
+	Select(ctx context.Context, in *SelectRequest, opts ...grpc.CallOption) (*SelectReply, error)
+	BatchInsert(ctx context.Context, in *BatchInsertRequest, opts ...grpc.CallOption) (*BatchInsertReply, error)
+	BatchDelete(ctx context.Context, in *BatchDeleteRequest, opts ...grpc.CallOption) (*BatchDeleteReply, error)
+	MultiSelect(ctx context.Context, in *MultiSelectRequest, opts ...grpc.CallOption) (*MultiSelectReply, error)
+	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthReply, error)
+	JoinNode(ctx context.Context, in *JoinNodeRequest, opts ...grpc.CallOption) (*JoinNodeReply, error)
+	AddNonVoter(ctx context.Context, in *AddNonVoterRequest, opts ...grpc.CallOption) (*AddNonVoterReply, error)
+	RemoveNode(ctx context.Context, in *RemoveNodeRequest, opts ...grpc.CallOption) (*RemoveNodeReply, error)
+	ListNodes(ctx context.Context, in *ListNodesRequest, opts ...grpc.CallOption) (*ListNodesReply, error)
+}
+
+type gokuProxyClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewGokuProxyClient(cc *grpc.ClientConn) GokuProxyClient {
+	return &gokuProxyClient{cc}
+}
+
+func (c *gokuProxyClient) AddGroup(ctx context.Context, in *AddGroupRequest, opts ...grpc.CallOption) (*AddGroupReply, error) {
+	out := new(AddGroupReply)
+	err := grpc.Invoke(ctx, "/pb.GokuProxy/AddGroup", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gokuProxyClient) DelGroup(ctx context.Context, in *DelGroupRequest, opts ...grpc.CallOption) (*DelGroupReply, error) {
+	out := new(DelGroupReply)
+	err := grpc.Invoke(ctx, "/pb.GokuProxy/DelGroup", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gokuProxyClient) AddServerToGroup(ctx context.Context, in *AddServerToGroupRequest, opts ...grpc.CallOption) (*AddServerToGroupReply, error) {
+	out := new(AddServerToGroupReply)
+	err := grpc.Invoke(ctx, "/pb.GokuProxy/AddServerToGroup", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gokuProxyClient) RemoveServerFromGroup(ctx context.Context, in *RemoveServerFromGroupRequest, opts ...grpc.CallOption) (*RemoveServerFromGroupReply, error) {
+	out := new(RemoveServerFromGroupReply)
+	err := grpc.Invoke(ctx, "/pb.GokuProxy/RemoveServerFromGroup", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gokuProxyClient) AssignSlots(ctx context.Context, in *AssignSlotsRequest, opts ...grpc.CallOption) (*AssignSlotsReply, error) {
+	out := new(AssignSlotsReply)
+	err := grpc.Invoke(ctx, "/pb.GokuProxy/AssignSlots", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gokuProxyClient) MigrateSlots(ctx context.Context, in *MigrateSlotsRequest, opts ...grpc.CallOption) (*MigrateSlotsReply, error) {
+	out := new(MigrateSlotsReply)
+	err := grpc.Invoke(ctx, "/pb.GokuProxy/MigrateSlots", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gokuProxyClient) GetMigrationStatus(ctx context.Context, in *GetMigrationStatusRequest, opts ...grpc.CallOption) (*GetMigrationStatusReply, error) {
+	out := new(GetMigrationStatusReply)
+	err := grpc.Invoke(ctx, "/pb.GokuProxy/GetMigrationStatus", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gokuProxyClient) AbortMigration(ctx context.Context, in *AbortMigrationRequest, opts ...grpc.CallOption) (*AbortMigrationReply, error) {
+	out := new(AbortMigrationReply)
+	err := grpc.Invoke(ctx, "/pb.GokuProxy/AbortMigration", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gokuProxyClient) Rebalance(ctx context.Context, in *RebalanceRequest, opts ...grpc.CallOption) (*RebalanceReply, error) {
+	out := new(RebalanceReply)
+	err := grpc.Invoke(ctx, "/pb.GokuProxy/Rebalance", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
 func (c *gokuProxyClient) Insert(ctx context.Context, in *InsertRequest, opts ...grpc.CallOption) (*InsertReply, error) {
@@ -470,18 +1544,90 @@ func (c *gokuProxyClient) Insert(ctx context.Context, in *InsertRequest, opts ..
 	return out, nil
 }
 
-func (c *gokuProxyClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteReply, error) {
-	out := new(DeleteReply)
-	err := grpc.Invoke(ctx, "/pb.GokuProxy/Delete", in, out, c.cc, opts...)
+func (c *gokuProxyClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteReply, error) {
+	out := new(DeleteReply)
+	err := grpc.Invoke(ctx, "/pb.GokuProxy/Delete", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gokuProxyClient) Select(ctx context.Context, in *SelectRequest, opts ...grpc.CallOption) (*SelectReply, error) {
+	out := new(SelectReply)
+	err := grpc.Invoke(ctx, "/pb.GokuProxy/Select", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gokuProxyClient) BatchInsert(ctx context.Context, in *BatchInsertRequest, opts ...grpc.CallOption) (*BatchInsertReply, error) {
+	out := new(BatchInsertReply)
+	err := grpc.Invoke(ctx, "/pb.GokuProxy/BatchInsert", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *gokuProxyClient) Select(ctx context.Context, in *SelectRequest, opts ...grpc.CallOption) (*SelectReply, error) {
-	out := new(SelectReply)
-	err := grpc.Invoke(ctx, "/pb.GokuProxy/Select", in, out, c.cc, opts...)
+func (c *gokuProxyClient) BatchDelete(ctx context.Context, in *BatchDeleteRequest, opts ...grpc.CallOption) (*BatchDeleteReply, error) {
+	out := new(BatchDeleteReply)
+	err := grpc.Invoke(ctx, "/pb.GokuProxy/BatchDelete", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gokuProxyClient) MultiSelect(ctx context.Context, in *MultiSelectRequest, opts ...grpc.CallOption) (*MultiSelectReply, error) {
+	out := new(MultiSelectReply)
+	err := grpc.Invoke(ctx, "/pb.GokuProxy/MultiSelect", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gokuProxyClient) Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthReply, error) {
+	out := new(HealthReply)
+	err := grpc.Invoke(ctx, "/pb.GokuProxy/Health", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gokuProxyClient) JoinNode(ctx context.Context, in *JoinNodeRequest, opts ...grpc.CallOption) (*JoinNodeReply, error) {
+	out := new(JoinNodeReply)
+	err := grpc.Invoke(ctx, "/pb.GokuProxy/JoinNode", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gokuProxyClient) AddNonVoter(ctx context.Context, in *AddNonVoterRequest, opts ...grpc.CallOption) (*AddNonVoterReply, error) {
+	out := new(AddNonVoterReply)
+	err := grpc.Invoke(ctx, "/pb.GokuProxy/AddNonVoter", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gokuProxyClient) RemoveNode(ctx context.Context, in *RemoveNodeRequest, opts ...grpc.CallOption) (*RemoveNodeReply, error) {
+	out := new(RemoveNodeReply)
+	err := grpc.Invoke(ctx, "/pb.GokuProxy/RemoveNode", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gokuProxyClient) ListNodes(ctx context.Context, in *ListNodesRequest, opts ...grpc.CallOption) (*ListNodesReply, error) {
+	out := new(ListNodesReply)
+	err := grpc.Invoke(ctx, "/pb.GokuProxy/ListNodes", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -493,10 +1639,24 @@ func (c *gokuProxyClient) Select(ctx context.Context, in *SelectRequest, opts ..
 type GokuProxyServer interface {
 	AddGroup(context.Context, *AddGroupRequest) (*AddGroupReply, error)
 	DelGroup(context.Context, *DelGroupRequest) (*DelGroupReply, error)
+	AddServerToGroup(context.Context, *AddServerToGroupRequest) (*AddServerToGroupReply, error)
+	RemoveServerFromGroup(context.Context, *RemoveServerFromGroupRequest) (*RemoveServerFromGroupReply, error)
 	AssignSlots(context.Context, *AssignSlotsRequest) (*AssignSlotsReply, error)
+	MigrateSlots(context.Context, *MigrateSlotsRequest) (*MigrateSlotsReply, error)
+	GetMigrationStatus(context.Context, *GetMigrationStatusRequest) (*GetMigrationStatusReply, error)
+	AbortMigration(context.Context, *AbortMigrationRequest) (*AbortMigrationReply, error)
+	Rebalance(context.Context, *RebalanceRequest) (*RebalanceReply, error)
 	Insert(context.Context, *InsertRequest) (*InsertReply, error)
 	Delete(context.Context, *DeleteRequest) (*DeleteReply, error)
 	Select(context.Context, *SelectRequest) (*SelectReply, error)
+	BatchInsert(context.Context, *BatchInsertRequest) (*BatchInsertReply, error)
+	BatchDelete(context.Context, *BatchDeleteRequest) (*BatchDeleteReply, error)
+	MultiSelect(context.Context, *MultiSelectRequest) (*MultiSelectReply, error)
+	Health(context.Context, *HealthRequest) (*HealthReply, error)
+	JoinNode(context.Context, *JoinNodeRequest) (*JoinNodeReply, error)
+	AddNonVoter(context.Context, *AddNonVoterRequest) (*AddNonVoterReply, error)
+	RemoveNode(context.Context, *RemoveNodeRequest) (*RemoveNodeReply, error)
+	ListNodes(context.Context, *ListNodesRequest) (*ListNodesReply, error)
 }
 
 func RegisterGokuProxyServer(s *grpc.Server, srv GokuProxyServer) {
@@ -539,6 +1699,42 @@ func _GokuProxy_DelGroup_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GokuProxy_AddServerToGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddServerToGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GokuProxyServer).AddServerToGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GokuProxy/AddServerToGroup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GokuProxyServer).AddServerToGroup(ctx, req.(*AddServerToGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GokuProxy_RemoveServerFromGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveServerFromGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GokuProxyServer).RemoveServerFromGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GokuProxy/RemoveServerFromGroup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GokuProxyServer).RemoveServerFromGroup(ctx, req.(*RemoveServerFromGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _GokuProxy_AssignSlots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(AssignSlotsRequest)
 	if err := dec(in); err != nil {
@@ -557,6 +1753,78 @@ func _GokuProxy_AssignSlots_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GokuProxy_MigrateSlots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MigrateSlotsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GokuProxyServer).MigrateSlots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GokuProxy/MigrateSlots",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GokuProxyServer).MigrateSlots(ctx, req.(*MigrateSlotsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GokuProxy_GetMigrationStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMigrationStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GokuProxyServer).GetMigrationStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GokuProxy/GetMigrationStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GokuProxyServer).GetMigrationStatus(ctx, req.(*GetMigrationStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GokuProxy_AbortMigration_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AbortMigrationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GokuProxyServer).AbortMigration(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GokuProxy/AbortMigration",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GokuProxyServer).AbortMigration(ctx, req.(*AbortMigrationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GokuProxy_Rebalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RebalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GokuProxyServer).Rebalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GokuProxy/Rebalance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GokuProxyServer).Rebalance(ctx, req.(*RebalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _GokuProxy_Insert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(InsertRequest)
 	if err := dec(in); err != nil {
@@ -611,6 +1879,150 @@ func _GokuProxy_Select_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GokuProxy_BatchInsert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchInsertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GokuProxyServer).BatchInsert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GokuProxy/BatchInsert",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GokuProxyServer).BatchInsert(ctx, req.(*BatchInsertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GokuProxy_BatchDelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchDeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GokuProxyServer).BatchDelete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GokuProxy/BatchDelete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GokuProxyServer).BatchDelete(ctx, req.(*BatchDeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GokuProxy_MultiSelect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MultiSelectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GokuProxyServer).MultiSelect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GokuProxy/MultiSelect",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GokuProxyServer).MultiSelect(ctx, req.(*MultiSelectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GokuProxy_Health_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GokuProxyServer).Health(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GokuProxy/Health",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GokuProxyServer).Health(ctx, req.(*HealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GokuProxy_JoinNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JoinNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GokuProxyServer).JoinNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GokuProxy/JoinNode",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GokuProxyServer).JoinNode(ctx, req.(*JoinNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GokuProxy_AddNonVoter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddNonVoterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GokuProxyServer).AddNonVoter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GokuProxy/AddNonVoter",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GokuProxyServer).AddNonVoter(ctx, req.(*AddNonVoterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GokuProxy_RemoveNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GokuProxyServer).RemoveNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GokuProxy/RemoveNode",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GokuProxyServer).RemoveNode(ctx, req.(*RemoveNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GokuProxy_ListNodes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListNodesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GokuProxyServer).ListNodes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GokuProxy/ListNodes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GokuProxyServer).ListNodes(ctx, req.(*ListNodesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _GokuProxy_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "pb.GokuProxy",
 	HandlerType: (*GokuProxyServer)(nil),
@@ -623,10 +2035,34 @@ var _GokuProxy_serviceDesc = grpc.ServiceDesc{
 			MethodName: "DelGroup",
 			Handler:    _GokuProxy_DelGroup_Handler,
 		},
+		{
+			MethodName: "AddServerToGroup",
+			Handler:    _GokuProxy_AddServerToGroup_Handler,
+		},
+		{
+			MethodName: "RemoveServerFromGroup",
+			Handler:    _GokuProxy_RemoveServerFromGroup_Handler,
+		},
 		{
 			MethodName: "AssignSlots",
 			Handler:    _GokuProxy_AssignSlots_Handler,
 		},
+		{
+			MethodName: "MigrateSlots",
+			Handler:    _GokuProxy_MigrateSlots_Handler,
+		},
+		{
+			MethodName: "GetMigrationStatus",
+			Handler:    _GokuProxy_GetMigrationStatus_Handler,
+		},
+		{
+			MethodName: "AbortMigration",
+			Handler:    _GokuProxy_AbortMigration_Handler,
+		},
+		{
+			MethodName: "Rebalance",
+			Handler:    _GokuProxy_Rebalance_Handler,
+		},
 		{
 			MethodName: "Insert",
 			Handler:    _GokuProxy_Insert_Handler,
@@ -639,6 +2075,38 @@ var _GokuProxy_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Select",
 			Handler:    _GokuProxy_Select_Handler,
 		},
+		{
+			MethodName: "BatchInsert",
+			Handler:    _GokuProxy_BatchInsert_Handler,
+		},
+		{
+			MethodName: "BatchDelete",
+			Handler:    _GokuProxy_BatchDelete_Handler,
+		},
+		{
+			MethodName: "MultiSelect",
+			Handler:    _GokuProxy_MultiSelect_Handler,
+		},
+		{
+			MethodName: "Health",
+			Handler:    _GokuProxy_Health_Handler,
+		},
+		{
+			MethodName: "JoinNode",
+			Handler:    _GokuProxy_JoinNode_Handler,
+		},
+		{
+			MethodName: "AddNonVoter",
+			Handler:    _GokuProxy_AddNonVoter_Handler,
+		},
+		{
+			MethodName: "RemoveNode",
+			Handler:    _GokuProxy_RemoveNode_Handler,
+		},
+		{
+			MethodName: "ListNodes",
+			Handler:    _GokuProxy_ListNodes_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "gokuproxy.proto",
@@ -647,40 +2115,111 @@ var _GokuProxy_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("gokuproxy.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 550 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x54, 0x4d, 0x8f, 0xd3, 0x30,
-	0x10, 0x6d, 0x93, 0x7e, 0x4e, 0xb6, 0xea, 0x62, 0x60, 0x15, 0x7a, 0x80, 0xe2, 0x0b, 0x3d, 0xa0,
-	0x0a, 0x75, 0xe1, 0xc8, 0x61, 0xd1, 0x2e, 0xa5, 0x97, 0x15, 0xca, 0x1e, 0x90, 0x00, 0xa9, 0x6a,
-	0x37, 0xa3, 0xaa, 0x6a, 0x52, 0x1b, 0xdb, 0x45, 0x94, 0x1f, 0xc8, 0xef, 0x42, 0xb6, 0xe3, 0x34,
-	0x69, 0x45, 0xb7, 0x17, 0x6e, 0x9e, 0xf1, 0x9b, 0x37, 0x6f, 0x3c, 0x33, 0x86, 0xee, 0x82, 0xad,
-	0x36, 0x5c, 0xb0, 0x5f, 0xdb, 0x21, 0x17, 0x4c, 0x31, 0xe2, 0xf1, 0x39, 0x7d, 0x07, 0xf5, 0x1b,
-	0x21, 0x98, 0x20, 0x04, 0x6a, 0xf7, 0x2c, 0xc6, 0xb0, 0xda, 0xaf, 0x0e, 0xfc, 0xc8, 0x9c, 0x49,
-	0x08, 0xcd, 0x14, 0xa5, 0x9c, 0x2d, 0x30, 0xf4, 0xfa, 0xd5, 0x41, 0x3b, 0x72, 0x26, 0xfd, 0x08,
-	0xdd, 0xab, 0x38, 0x1e, 0x0b, 0xb6, 0xe1, 0x11, 0xfe, 0xd8, 0xa0, 0x54, 0xe4, 0x19, 0xb4, 0x16,
-	0xda, 0x9e, 0x2e, 0xe3, 0x8c, 0xa4, 0x69, 0xec, 0x49, 0xac, 0x79, 0x24, 0x8a, 0x9f, 0x28, 0x64,
-	0xe8, 0xf5, 0x7d, 0xcd, 0x93, 0x99, 0xf4, 0x0d, 0x74, 0x76, 0x3c, 0x3c, 0xd9, 0x92, 0x17, 0x50,
-	0x47, 0xad, 0xc7, 0x50, 0x04, 0xa3, 0xf6, 0x90, 0xcf, 0x87, 0x46, 0x60, 0x64, 0xfd, 0xf4, 0x35,
-	0x74, 0xaf, 0x31, 0x39, 0x31, 0xb3, 0xe6, 0xdf, 0xa1, 0x4f, 0xe2, 0xff, 0x0d, 0xe4, 0x4a, 0xca,
-	0xe5, 0x62, 0x7d, 0x97, 0x30, 0x25, 0x5d, 0x8a, 0xe7, 0x10, 0x28, 0x36, 0xdd, 0xcb, 0xd2, 0x56,
-	0x6c, 0x9c, 0x55, 0x48, 0xa1, 0x23, 0xd5, 0x4c, 0xa8, 0xa9, 0x4c, 0x98, 0xd2, 0x08, 0xcf, 0x20,
-	0x02, 0xe3, 0xd4, 0x4c, 0x93, 0x98, 0xf4, 0xe1, 0x4c, 0x2a, 0xc6, 0x73, 0x88, 0x6f, 0x20, 0xa0,
-	0x7d, 0x16, 0x41, 0x2f, 0xe1, 0xbc, 0x94, 0xfb, 0x24, 0xc1, 0x12, 0x3a, 0x93, 0xb5, 0x44, 0xa1,
-	0x9c, 0xd6, 0x73, 0xf0, 0x57, 0xb8, 0x35, 0xf8, 0x76, 0xa4, 0x8f, 0xe4, 0x02, 0x1a, 0x29, 0xa6,
-	0x73, 0x14, 0x59, 0x1b, 0x33, 0x8b, 0xbc, 0x84, 0x33, 0xb5, 0x4c, 0x51, 0xaa, 0x59, 0xca, 0xa7,
-	0x6b, 0x99, 0x29, 0x0a, 0x72, 0xdf, 0xad, 0x24, 0x4f, 0xa1, 0xa1, 0x54, 0xa2, 0x2f, 0x6b, 0xe6,
-	0xb2, 0xae, 0x54, 0x72, 0x2b, 0xe9, 0x27, 0x08, 0x5c, 0x52, 0x2d, 0x32, 0x84, 0xe6, 0x86, 0xc7,
-	0x33, 0x85, 0xf6, 0x69, 0x5a, 0x91, 0x33, 0x77, 0xf2, 0xbd, 0x7f, 0xc8, 0xff, 0x6e, 0x3a, 0x84,
-	0x0a, 0xff, 0x87, 0x7c, 0xad, 0xd3, 0xb1, 0x67, 0x3a, 0x63, 0x63, 0xe6, 0x3a, 0x33, 0xf3, 0x61,
-	0x9d, 0xd7, 0xd0, 0xb9, 0xc3, 0x04, 0xef, 0x8f, 0x3c, 0xf3, 0xbe, 0x1e, 0xef, 0x50, 0xcf, 0x37,
-	0x68, 0xde, 0x24, 0x98, 0xe2, 0x5a, 0x15, 0xaa, 0xf2, 0x8f, 0x56, 0x55, 0x3b, 0xd6, 0x94, 0x7a,
-	0xb1, 0x29, 0x5f, 0x20, 0x70, 0x12, 0x75, 0xb1, 0xaf, 0xa0, 0x85, 0x36, 0x97, 0x0c, 0xab, 0x7d,
-	0x7f, 0x10, 0x8c, 0x02, 0x53, 0x95, 0xf5, 0x45, 0xf9, 0xe5, 0x83, 0xb5, 0x8f, 0xfe, 0x78, 0xd0,
-	0x1e, 0xb3, 0xd5, 0xe6, 0xb3, 0xfe, 0x3c, 0xc8, 0x5b, 0x68, 0xb9, 0x9d, 0x25, 0x8f, 0x35, 0x76,
-	0xef, 0x27, 0xe8, 0x3d, 0x2a, 0x3b, 0x79, 0xb2, 0xa5, 0x15, 0x1d, 0xe5, 0x36, 0xd1, 0x46, 0xed,
-	0x6d, 0xb1, 0x8d, 0x2a, 0x2d, 0x2b, 0xad, 0x90, 0xf7, 0x10, 0x14, 0x36, 0x82, 0x5c, 0x18, 0xe6,
-	0x83, 0xf5, 0xec, 0x3d, 0x39, 0xf0, 0xdb, 0xf0, 0x21, 0x34, 0xec, 0x98, 0x12, 0xc3, 0x5e, 0xda,
-	0x93, 0x5e, 0xb7, 0xe8, 0xca, 0xf1, 0x76, 0x5c, 0x88, 0x53, 0xb3, 0x1b, 0x4c, 0x8b, 0x2f, 0x4c,
-	0x93, 0xc5, 0xdb, 0x17, 0xb7, 0xf8, 0xd2, 0x80, 0x58, 0x7c, 0xa1, 0x21, 0xb4, 0xf2, 0xa1, 0xf6,
-	0xd5, 0xe3, 0xf3, 0x79, 0xc3, 0x7c, 0xbf, 0x97, 0x7f, 0x03, 0x00, 0x00, 0xff, 0xff, 0x64, 0x53,
-	0x04, 0x4a, 0x91, 0x05, 0x00, 0x00,
+	// 1689 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x18, 0xe9, 0x6e, 0xdb, 0x46,
+	0x7a, 0x75, 0x4b, 0x9f, 0x2c, 0xcb, 0x1e, 0xcb, 0xb1, 0xcc, 0x5c, 0x0a, 0x17, 0x8b, 0x78, 0x81,
+	0xac, 0xb1, 0x70, 0xf6, 0xc8, 0x66, 0x91, 0xa2, 0x0e, 0x72, 0xd4, 0xad, 0x63, 0x24, 0x74, 0xd0,
+	0x14, 0x4d, 0x01, 0x81, 0x12, 0xc7, 0x32, 0x13, 0x8a, 0xa3, 0xce, 0x8c, 0x9c, 0x2a, 0x79, 0x88,
+	0xa2, 0xe8, 0x1b, 0xf4, 0x05, 0xfa, 0x48, 0xfd, 0xd9, 0xd7, 0x28, 0xe6, 0xe2, 0x29, 0x59, 0x42,
+	0x0f, 0xf4, 0x1f, 0xbf, 0x6f, 0xbe, 0xfb, 0x9c, 0x21, 0xb4, 0x47, 0xe4, 0xed, 0x74, 0x42, 0xc9,
+	0x37, 0xb3, 0xfd, 0x09, 0x25, 0x9c, 0xa0, 0xe2, 0x64, 0x60, 0xff, 0x1b, 0x2a, 0x8f, 0x29, 0x25,
+	0x14, 0x21, 0x28, 0x0f, 0x89, 0x87, 0xbb, 0x85, 0x5e, 0x61, 0xaf, 0xe4, 0xc8, 0x6f, 0xd4, 0x85,
+	0xda, 0x18, 0x33, 0xe6, 0x8e, 0x70, 0xb7, 0xd8, 0x2b, 0xec, 0x35, 0x1c, 0x03, 0xda, 0xdf, 0x16,
+	0xa0, 0x7d, 0xe8, 0x79, 0x4f, 0x29, 0x99, 0x4e, 0x1c, 0xfc, 0xf5, 0x14, 0x33, 0x8e, 0x76, 0xa1,
+	0x3e, 0x12, 0x70, 0xdf, 0xf7, 0xb4, 0x94, 0x9a, 0x84, 0x8f, 0x3c, 0x21, 0x88, 0x61, 0x7a, 0x81,
+	0x29, 0xeb, 0x16, 0x7b, 0x25, 0x21, 0x48, 0x83, 0xe8, 0x1a, 0x34, 0x28, 0x1e, 0xb8, 0x81, 0x1b,
+	0x0e, 0x71, 0xb7, 0xd4, 0x2b, 0xec, 0xd5, 0x9d, 0x18, 0x81, 0xfe, 0x0e, 0x1b, 0xe7, 0xd8, 0x1b,
+	0xe1, 0xfe, 0x04, 0xd3, 0x21, 0x0e, 0xb9, 0x1f, 0xe0, 0x6e, 0xb9, 0x57, 0xd8, 0x2b, 0x38, 0x6d,
+	0x89, 0x7f, 0x1e, 0xa1, 0xed, 0x23, 0x68, 0xc5, 0x06, 0x4d, 0x82, 0x19, 0xba, 0x09, 0x15, 0x2c,
+	0x3c, 0x93, 0xb6, 0x34, 0x0f, 0x1a, 0xfb, 0x93, 0xc1, 0xbe, 0x74, 0xd5, 0x51, 0x78, 0xb4, 0x03,
+	0xb5, 0x37, 0x64, 0xd0, 0xf7, 0x3d, 0x63, 0x54, 0xf5, 0x0d, 0x19, 0x1c, 0x79, 0xcc, 0xbe, 0x03,
+	0xed, 0x47, 0x38, 0x58, 0xd1, 0x37, 0xfb, 0x9f, 0xd0, 0x8a, 0xa9, 0x57, 0x51, 0x6c, 0x1f, 0xc3,
+	0xce, 0xa1, 0xe7, 0x9d, 0xca, 0x08, 0xbc, 0x24, 0xab, 0xc6, 0xf0, 0x0a, 0x54, 0x55, 0xd0, 0x74,
+	0x2e, 0x34, 0x64, 0xdf, 0x83, 0xed, 0xbc, 0xb4, 0x95, 0xec, 0x78, 0x01, 0xd7, 0x1c, 0x3c, 0x26,
+	0x17, 0x58, 0x31, 0x3f, 0xa1, 0x64, 0xfc, 0x5b, 0x8d, 0x79, 0x00, 0xd6, 0x02, 0x91, 0x2b, 0x59,
+	0xf4, 0x1e, 0xd0, 0x21, 0x63, 0xfe, 0x28, 0x3c, 0x0d, 0x08, 0x67, 0xc6, 0x8e, 0x1b, 0xd0, 0xe4,
+	0xa4, 0x9f, 0x31, 0xa5, 0xc1, 0x95, 0xaf, 0x47, 0x1e, 0xb2, 0xa1, 0xc5, 0xb8, 0x4b, 0x79, 0x9f,
+	0x05, 0x84, 0x0b, 0x8a, 0xa2, 0xa4, 0x68, 0x4a, 0xa4, 0x90, 0x74, 0xe4, 0xa1, 0x1e, 0xac, 0x31,
+	0x4e, 0x26, 0x11, 0x49, 0x49, 0x92, 0x80, 0xc0, 0x29, 0x0a, 0xfb, 0x2e, 0x6c, 0xa4, 0x74, 0xaf,
+	0x64, 0xf0, 0x07, 0xd8, 0x7a, 0xe6, 0x8f, 0xa8, 0xcb, 0xf1, 0x9f, 0x60, 0xf1, 0x67, 0xb0, 0x99,
+	0x56, 0x2e, 0x4c, 0xde, 0x86, 0xaa, 0xaa, 0x6a, 0xa9, 0xb5, 0xe1, 0x54, 0x64, 0x51, 0xc7, 0x9e,
+	0x14, 0x17, 0x78, 0xf2, 0x73, 0x01, 0x36, 0x1c, 0xd3, 0x78, 0xc6, 0x8f, 0xff, 0x43, 0xed, 0x1d,
+	0xf6, 0x47, 0xe7, 0x9c, 0x75, 0x0b, 0xbd, 0xd2, 0x5e, 0xf3, 0xe0, 0x96, 0xe0, 0xcb, 0x92, 0xed,
+	0xbf, 0x52, 0x34, 0x8f, 0x43, 0x4e, 0x67, 0x8e, 0xe1, 0x10, 0xfd, 0xe5, 0xd1, 0x59, 0x9f, 0x4e,
+	0x43, 0xa9, 0xb4, 0xee, 0x54, 0x3d, 0x3a, 0x73, 0xa6, 0x21, 0xea, 0x41, 0x73, 0x48, 0xc2, 0xe1,
+	0x94, 0x52, 0x1c, 0x0e, 0x67, 0xda, 0xb1, 0x24, 0x0a, 0xdd, 0x84, 0x26, 0x3f, 0xa7, 0x84, 0xf3,
+	0x00, 0xf7, 0x43, 0x26, 0x5b, 0xbe, 0xe4, 0x80, 0x41, 0x9d, 0x30, 0xeb, 0x3e, 0xac, 0x25, 0x95,
+	0xa2, 0x0d, 0x28, 0xbd, 0xc5, 0x33, 0x1d, 0x68, 0xf1, 0x89, 0x3a, 0x50, 0xb9, 0x70, 0x83, 0xa9,
+	0x9a, 0x5c, 0x05, 0x47, 0x01, 0xf7, 0x8b, 0xf7, 0x0a, 0xf6, 0x07, 0xa8, 0x8b, 0x78, 0x3d, 0x23,
+	0x17, 0x58, 0xd8, 0x68, 0xe2, 0xab, 0x78, 0xab, 0x4c, 0x45, 0xdf, 0x86, 0xd6, 0x19, 0x25, 0xe3,
+	0x38, 0x87, 0x3a, 0x43, 0x67, 0xa6, 0xa2, 0x8f, 0xbc, 0x6c, 0x96, 0x4b, 0xd9, 0x2c, 0x23, 0x28,
+	0x33, 0xff, 0x3d, 0xd6, 0xe6, 0xcb, 0x6f, 0xfb, 0x25, 0x34, 0xe4, 0xf1, 0x31, 0x71, 0xbd, 0x25,
+	0x0d, 0x36, 0xc0, 0x67, 0x84, 0x62, 0xad, 0x58, 0x43, 0xc2, 0x2d, 0xf7, 0x8c, 0x63, 0xaa, 0xb5,
+	0x29, 0xc0, 0xfe, 0xbe, 0x00, 0xeb, 0x89, 0xac, 0x88, 0x3a, 0xb0, 0xa1, 0x22, 0xfa, 0xd0, 0x24,
+	0x6e, 0x4d, 0x24, 0xce, 0xb8, 0xed, 0xa8, 0x23, 0xf4, 0x57, 0xa8, 0x04, 0xc4, 0xd5, 0xf3, 0xaf,
+	0x79, 0xd0, 0x12, 0x34, 0x91, 0x75, 0x8e, 0x3a, 0x8b, 0x2b, 0xa7, 0xb4, 0x7c, 0x8e, 0x96, 0x53,
+	0x73, 0xf4, 0x00, 0x76, 0x9f, 0x62, 0xae, 0x4a, 0xd4, 0x27, 0xe1, 0x29, 0x77, 0xf9, 0x34, 0x6a,
+	0x91, 0xf9, 0x75, 0x6a, 0xf7, 0xa1, 0x25, 0xad, 0x34, 0x4c, 0x8b, 0x33, 0xd4, 0x81, 0xca, 0xe4,
+	0xdc, 0x65, 0x66, 0x35, 0x29, 0x60, 0xa9, 0xb5, 0xf6, 0x0f, 0x45, 0xd8, 0x99, 0x67, 0xd5, 0x25,
+	0xbd, 0x93, 0xc9, 0x73, 0x31, 0x9b, 0xe7, 0xdb, 0x50, 0x11, 0x36, 0xb1, 0x6e, 0x49, 0x86, 0x71,
+	0x33, 0x0a, 0xb5, 0xd1, 0xe1, 0xa8, 0x73, 0x51, 0x10, 0x1e, 0x09, 0x55, 0x41, 0xd4, 0x1d, 0xf9,
+	0x1d, 0x8f, 0x02, 0xee, 0x8f, 0x65, 0xb1, 0x57, 0x12, 0xa3, 0xe0, 0xa5, 0x3f, 0xc6, 0x27, 0x4c,
+	0x18, 0x80, 0x43, 0x2f, 0xa2, 0xa8, 0x2a, 0x03, 0x70, 0xe8, 0xe9, 0xf3, 0xc8, 0xe9, 0xda, 0x82,
+	0x14, 0x75, 0xa1, 0xe6, 0x0e, 0x08, 0xe5, 0xd8, 0xeb, 0xd6, 0xa5, 0x6e, 0x03, 0xc6, 0xf5, 0xd4,
+	0x50, 0x1e, 0x4b, 0xc0, 0xde, 0x87, 0xed, 0x43, 0x41, 0x10, 0x7b, 0x70, 0x79, 0xd6, 0xfe, 0x03,
+	0x5b, 0x59, 0xfa, 0x95, 0xc6, 0x27, 0x83, 0xd6, 0x51, 0xc8, 0x30, 0xe5, 0x46, 0x7e, 0xa2, 0x8f,
+	0x1b, 0xaa, 0x8f, 0xaf, 0x40, 0x75, 0x8c, 0xc7, 0x83, 0x78, 0xd3, 0x28, 0x08, 0xdd, 0x82, 0x35,
+	0x11, 0x0f, 0xc6, 0xdd, 0xf1, 0x44, 0x04, 0x45, 0x4f, 0x91, 0x08, 0x77, 0xc2, 0x84, 0xb1, 0x9c,
+	0x07, 0xf1, 0x00, 0xa9, 0x70, 0x1e, 0x9c, 0x30, 0xfb, 0xc7, 0x02, 0xb4, 0x84, 0x7d, 0xfe, 0xd0,
+	0x75, 0x30, 0x9b, 0x06, 0x5c, 0xe4, 0xc5, 0xf5, 0x3c, 0xaa, 0xd5, 0xca, 0x6f, 0x11, 0xb2, 0x09,
+	0x0e, 0x3d, 0x3f, 0x1c, 0xe9, 0xe9, 0x65, 0x40, 0xb9, 0xfb, 0x64, 0xd1, 0xe8, 0xfb, 0x8a, 0x86,
+	0x62, 0x6f, 0xcb, 0x0b, 0xb2, 0x70, 0x1d, 0x20, 0x70, 0xb9, 0x18, 0x70, 0x71, 0x9e, 0x1b, 0x1a,
+	0x73, 0xc2, 0x84, 0xc6, 0x37, 0xc4, 0x0f, 0x85, 0xc6, 0xaa, 0xd2, 0xa8, 0x41, 0xfb, 0x1d, 0x34,
+	0x4d, 0x98, 0x44, 0x58, 0xbb, 0x50, 0x9b, 0x4e, 0x3c, 0x57, 0x64, 0xb3, 0xa0, 0x08, 0x35, 0xb8,
+	0x74, 0xca, 0xa3, 0x7f, 0x40, 0x9d, 0x2a, 0xd7, 0x53, 0xd5, 0x9a, 0x0a, 0x87, 0x13, 0x91, 0xd8,
+	0x5f, 0xc9, 0xbb, 0x0d, 0xe6, 0xf8, 0x8f, 0xc8, 0x8f, 0x70, 0xcb, 0x48, 0xd7, 0x6e, 0x79, 0x12,
+	0x8c, 0xdc, 0xd2, 0xe0, 0xef, 0xee, 0xd6, 0x23, 0x68, 0x9d, 0xe2, 0x00, 0x0f, 0x2f, 0x29, 0xbb,
+	0xac, 0xf9, 0xc5, 0xbc, 0xf9, 0xaf, 0xa1, 0xf6, 0x38, 0xc0, 0x63, 0x1c, 0xf2, 0x44, 0x10, 0x4a,
+	0x97, 0x06, 0xa1, 0x7c, 0x59, 0x91, 0x56, 0x92, 0x45, 0xfa, 0x0a, 0x9a, 0xc6, 0x44, 0x11, 0x9b,
+	0xdb, 0x50, 0xc7, 0x4a, 0x97, 0x19, 0xe8, 0x4d, 0x19, 0x04, 0x85, 0x73, 0xa2, 0xc3, 0xe5, 0x7b,
+	0xfe, 0x01, 0xa0, 0x87, 0x2e, 0x1f, 0x9e, 0xa7, 0xfb, 0xee, 0x36, 0x54, 0x7c, 0x8e, 0xc7, 0x46,
+	0xb8, 0x8c, 0x5e, 0x8a, 0xc2, 0x51, 0xe7, 0xf6, 0xff, 0x60, 0x23, 0xc5, 0x2e, 0x8c, 0xfb, 0x5b,
+	0x9a, 0xb9, 0x9d, 0x64, 0x9e, 0x04, 0x33, 0xc3, 0x6a, 0x34, 0xa7, 0x2b, 0x6a, 0x9e, 0xe6, 0x14,
+	0x45, 0x56, 0x73, 0xb2, 0x64, 0xe6, 0x69, 0x4e, 0x9c, 0x27, 0x34, 0x3f, 0x9b, 0x06, 0xdc, 0x4f,
+	0x27, 0x7d, 0x9e, 0xe6, 0x14, 0x45, 0x42, 0x73, 0x8a, 0x7d, 0x91, 0xe6, 0xc4, 0xb9, 0x61, 0xbd,
+	0x03, 0xad, 0x4f, 0xb0, 0x1b, 0xf0, 0x73, 0xa3, 0xf4, 0x2a, 0x34, 0xcc, 0x22, 0x51, 0xbc, 0x25,
+	0xa7, 0xae, 0x77, 0x3e, 0xb3, 0xbf, 0x8b, 0x27, 0x93, 0xe2, 0x9a, 0x3b, 0x99, 0x3a, 0x50, 0x11,
+	0x13, 0x27, 0x5a, 0x7c, 0x12, 0x40, 0x16, 0xd4, 0xcf, 0x5c, 0x3f, 0x98, 0x52, 0x6c, 0x7a, 0x2d,
+	0x82, 0x97, 0x4f, 0xa6, 0x5d, 0xa8, 0x33, 0x3f, 0x1c, 0x26, 0xf6, 0x4f, 0x4d, 0xc2, 0xaa, 0x10,
+	0xe5, 0x9e, 0xd3, 0x06, 0x5d, 0x72, 0x65, 0x49, 0x36, 0x61, 0x31, 0xd7, 0x84, 0x3a, 0x0c, 0x71,
+	0x13, 0xbe, 0x82, 0xa6, 0xc6, 0xe9, 0x0a, 0xaf, 0x4a, 0x41, 0xa9, 0x88, 0x26, 0x34, 0x3b, 0xfa,
+	0x78, 0x79, 0x85, 0x7f, 0x04, 0xed, 0x4f, 0x89, 0x1f, 0x9e, 0x10, 0x2f, 0x2a, 0xb2, 0x1d, 0xa8,
+	0x85, 0xc4, 0xc3, 0xf1, 0xde, 0xaa, 0x0a, 0x50, 0x5d, 0xd1, 0x64, 0x7c, 0x8b, 0x71, 0x7c, 0xc5,
+	0x83, 0x2e, 0xe6, 0x5f, 0x69, 0x8d, 0x1d, 0x02, 0x3a, 0xf4, 0xbc, 0x13, 0x12, 0x7e, 0x4e, 0x38,
+	0xa6, 0xbf, 0x4a, 0xa9, 0x78, 0x7d, 0x24, 0x45, 0xac, 0xa4, 0xf7, 0x0e, 0x6c, 0xaa, 0xd7, 0xd6,
+	0x2a, 0xbe, 0xda, 0x07, 0xd0, 0x4e, 0x52, 0xaf, 0xa4, 0x01, 0xc1, 0xc6, 0xb1, 0xcf, 0xb8, 0xe0,
+	0x30, 0x37, 0x37, 0xfb, 0x0b, 0x28, 0x0b, 0x18, 0xad, 0x43, 0x31, 0xd2, 0x51, 0xf4, 0xe7, 0xba,
+	0x25, 0x6f, 0xe1, 0xc4, 0x5c, 0x57, 0xeb, 0x8e, 0x02, 0xc4, 0xb8, 0x0c, 0xb0, 0xeb, 0x61, 0xaa,
+	0x6f, 0x42, 0x1a, 0xb2, 0x5f, 0xc0, 0x7a, 0x42, 0x9b, 0x30, 0xf0, 0x06, 0x54, 0x84, 0xf5, 0xa6,
+	0x28, 0xea, 0xc2, 0x40, 0x69, 0xbe, 0x42, 0x2f, 0x2d, 0x86, 0x83, 0x9f, 0x1a, 0xd0, 0x78, 0x4a,
+	0xde, 0x4e, 0x9f, 0x8b, 0xff, 0x1e, 0xe8, 0x5f, 0x50, 0x37, 0x3f, 0x09, 0xd0, 0x96, 0xa0, 0xcd,
+	0xfc, 0xc3, 0xb0, 0x36, 0xd3, 0xc8, 0x49, 0x30, 0xb3, 0xff, 0x22, 0xb8, 0xcc, 0x0b, 0x5f, 0x71,
+	0x65, 0xfe, 0x0e, 0x58, 0x9b, 0x69, 0xa4, 0xe2, 0x3a, 0x96, 0x19, 0x4d, 0xbd, 0xcb, 0xd1, 0x55,
+	0x2d, 0x7e, 0xde, 0xdb, 0xdf, 0xda, 0x9d, 0x7f, 0xa8, 0xa4, 0xbd, 0x86, 0xed, 0xb9, 0x0f, 0x6b,
+	0xd4, 0x53, 0x3d, 0xb6, 0xf8, 0x19, 0x6f, 0xdd, 0xb8, 0x84, 0x42, 0x09, 0x7f, 0x00, 0xcd, 0xc4,
+	0xd3, 0x17, 0x5d, 0x91, 0x86, 0xe4, 0xde, 0xe1, 0x56, 0x27, 0x87, 0x57, 0xec, 0x1f, 0xc3, 0x5a,
+	0xf2, 0x1d, 0x8a, 0x76, 0x04, 0xdd, 0x9c, 0x67, 0xb1, 0xb5, 0x9d, 0x3f, 0x50, 0x12, 0x1c, 0x40,
+	0xf9, 0x3b, 0x39, 0xba, 0x2e, 0x47, 0xc0, 0xa2, 0x17, 0x84, 0x75, 0x75, 0xd1, 0xb1, 0x92, 0xf9,
+	0x04, 0xd6, 0xd3, 0x77, 0x52, 0xa4, 0x02, 0x3c, 0xef, 0x5e, 0x6b, 0xed, 0xcc, 0x3b, 0x52, 0x72,
+	0xfe, 0x0b, 0x8d, 0xe8, 0x69, 0x85, 0x3a, 0xf3, 0xde, 0xbf, 0x16, 0xca, 0x60, 0x15, 0xe3, 0x3e,
+	0x54, 0xd5, 0x16, 0x44, 0xf9, 0x75, 0x6a, 0x65, 0x97, 0xa4, 0xa2, 0x57, 0xbb, 0x0b, 0xe5, 0x97,
+	0xa0, 0x95, 0x5d, 0x6d, 0x8a, 0x5e, 0x6d, 0x1c, 0x94, 0x5f, 0x5d, 0x56, 0x76, 0x21, 0xa9, 0x2c,
+	0x27, 0x56, 0xb7, 0xca, 0x72, 0xfe, 0x2a, 0x60, 0x75, 0x72, 0xf8, 0x34, 0xbb, 0xb6, 0x31, 0x66,
+	0x4f, 0x1b, 0xda, 0xc9, 0xe1, 0x23, 0xf6, 0xc4, 0x12, 0x55, 0xec, 0xf9, 0xa5, 0x6c, 0x75, 0x72,
+	0xf8, 0xc8, 0x59, 0xbd, 0x81, 0xa4, 0xb3, 0xa9, 0xa5, 0x6a, 0xb5, 0x93, 0xa8, 0xa8, 0x67, 0xcd,
+	0x10, 0x57, 0x3d, 0x9b, 0x59, 0x09, 0xd6, 0x66, 0x1a, 0x19, 0x37, 0x42, 0x3c, 0x85, 0x75, 0x23,
+	0xe4, 0x26, 0xbb, 0xd5, 0xc9, 0xe1, 0x15, 0xfb, 0x7d, 0x80, 0x78, 0xc2, 0xa2, 0xed, 0xb8, 0xef,
+	0x92, 0x8a, 0xb7, 0xb2, 0xe8, 0xa8, 0xcc, 0xa2, 0xd9, 0xa7, 0xca, 0x2c, 0x3b, 0x78, 0x2d, 0x94,
+	0xc1, 0x4a, 0xc6, 0x87, 0xe5, 0x2f, 0x8b, 0x93, 0xc1, 0xa0, 0x2a, 0x7f, 0xe9, 0xde, 0xfd, 0x65,
+	0x00, 0xbf, 0x5c, 0xe9, 0xea, 0xe5, 0x15, 0x00, 0x00,
 }
@@ -1,12 +1,16 @@
 package main
 
 import (
+	"fmt"
+	"sort"
 	"time"
 
 	"golang.org/x/net/context"
 
 	"github.com/RussellLuo/goku/cluster"
 	"github.com/RussellLuo/goku/cmd/goku-proxy/pb"
+	"github.com/RussellLuo/goku/common"
+	"github.com/RussellLuo/goku/group"
 )
 
 type Proxy struct {
@@ -27,9 +31,27 @@ func (p *Proxy) AddGroup(ctx context.Context, in *pb.AddGroupRequest) (*pb.AddGr
 		servers[i] = cluster.Server(s)
 	}
 
-	err := p.cluster.AddGroup(int(in.GroupId), servers...)
+	opts := cluster.GroupOptions{HedgePercentile: in.HedgePercentile}
+	err := p.cluster.AddGroupWithOptions(int(in.GroupId), opts, servers...)
+	if err == cluster.ErrNotLeader {
+		var out *pb.AddGroupReply
+		err = p.forward(ctx, func(ctx context.Context, c pb.GokuProxyClient) (err error) {
+			out, err = c.AddGroup(ctx, in)
+			return err
+		})
+		if err == nil {
+			return out, nil
+		}
+	}
+
+	var ids []string
+	if err == nil && in.Rebalance {
+		if _, ids, err = cluster.NewRebalancer(p.cluster, 1, 0).Rebalance(false); err != nil {
+			err = fmt.Errorf("group %d is added but failed to rebalance: %s", in.GroupId, err)
+		}
+	}
 
-	out := &pb.AddGroupReply{}
+	out := &pb.AddGroupReply{JobIds: ids}
 	if err != nil {
 		out.Error = &pb.Error{Message: err.Error()}
 	}
@@ -38,6 +60,16 @@ func (p *Proxy) AddGroup(ctx context.Context, in *pb.AddGroupRequest) (*pb.AddGr
 
 func (p *Proxy) DelGroup(ctx context.Context, in *pb.DelGroupRequest) (*pb.DelGroupReply, error) {
 	err := p.cluster.DelGroup(int(in.GroupId))
+	if err == cluster.ErrNotLeader {
+		var out *pb.DelGroupReply
+		err = p.forward(ctx, func(ctx context.Context, c pb.GokuProxyClient) (err error) {
+			out, err = c.DelGroup(ctx, in)
+			return err
+		})
+		if err == nil {
+			return out, nil
+		}
+	}
 
 	out := &pb.DelGroupReply{}
 	if err != nil {
@@ -46,8 +78,58 @@ func (p *Proxy) DelGroup(ctx context.Context, in *pb.DelGroupRequest) (*pb.DelGr
 	return out, nil
 }
 
+func (p *Proxy) AddServerToGroup(ctx context.Context, in *pb.AddServerToGroupRequest) (*pb.AddServerToGroupReply, error) {
+	err := p.cluster.AddServerToGroup(int(in.GroupId), cluster.Server(in.Server))
+	if err == cluster.ErrNotLeader {
+		var out *pb.AddServerToGroupReply
+		err = p.forward(ctx, func(ctx context.Context, c pb.GokuProxyClient) (err error) {
+			out, err = c.AddServerToGroup(ctx, in)
+			return err
+		})
+		if err == nil {
+			return out, nil
+		}
+	}
+
+	out := &pb.AddServerToGroupReply{}
+	if err != nil {
+		out.Error = &pb.Error{Message: err.Error()}
+	}
+	return out, nil
+}
+
+func (p *Proxy) RemoveServerFromGroup(ctx context.Context, in *pb.RemoveServerFromGroupRequest) (*pb.RemoveServerFromGroupReply, error) {
+	err := p.cluster.RemoveServerFromGroup(int(in.GroupId), cluster.Server(in.Server))
+	if err == cluster.ErrNotLeader {
+		var out *pb.RemoveServerFromGroupReply
+		err = p.forward(ctx, func(ctx context.Context, c pb.GokuProxyClient) (err error) {
+			out, err = c.RemoveServerFromGroup(ctx, in)
+			return err
+		})
+		if err == nil {
+			return out, nil
+		}
+	}
+
+	out := &pb.RemoveServerFromGroupReply{}
+	if err != nil {
+		out.Error = &pb.Error{Message: err.Error()}
+	}
+	return out, nil
+}
+
 func (p *Proxy) AssignSlots(ctx context.Context, in *pb.AssignSlotsRequest) (*pb.AssignSlotsReply, error) {
 	err := p.cluster.AssignSlots(int(in.ToGroupId), int(in.StartSlotId), int(in.StopSlotId))
+	if err == cluster.ErrNotLeader {
+		var out *pb.AssignSlotsReply
+		err = p.forward(ctx, func(ctx context.Context, c pb.GokuProxyClient) (err error) {
+			out, err = c.AssignSlots(ctx, in)
+			return err
+		})
+		if err == nil {
+			return out, nil
+		}
+	}
 
 	out := &pb.AssignSlotsReply{}
 	if err != nil {
@@ -56,29 +138,358 @@ func (p *Proxy) AssignSlots(ctx context.Context, in *pb.AssignSlotsRequest) (*pb
 	return out, nil
 }
 
-func (p *Proxy) Insert(ctx context.Context, in *pb.InsertRequest) (*pb.InsertReply, error) {
-	updated, err := p.lwwset.Insert(in.Key, in.Member, in.TimestampNs, time.Duration(in.TtlNs))
+func (p *Proxy) MigrateSlots(ctx context.Context, in *pb.MigrateSlotsRequest) (*pb.MigrateSlotsReply, error) {
+	id, err := p.cluster.StartMigration(int(in.ToGroupId), int(in.StartSlotId), int(in.StopSlotId))
+	if err == cluster.ErrNotLeader {
+		var out *pb.MigrateSlotsReply
+		err = p.forward(ctx, func(ctx context.Context, c pb.GokuProxyClient) (err error) {
+			out, err = c.MigrateSlots(ctx, in)
+			return err
+		})
+		if err == nil {
+			return out, nil
+		}
+	}
 
-	out := &pb.InsertReply{Updated: updated}
+	out := &pb.MigrateSlotsReply{JobId: id}
 	if err != nil {
 		out.Error = &pb.Error{Message: err.Error()}
 	}
 	return out, nil
 }
 
-func (p *Proxy) Delete(ctx context.Context, in *pb.DeleteRequest) (*pb.DeleteReply, error) {
-	deleted, err := p.lwwset.Delete(in.Key, in.Member, in.TimestampNs)
+func (p *Proxy) GetMigrationStatus(ctx context.Context, in *pb.GetMigrationStatusRequest) (*pb.GetMigrationStatusReply, error) {
+	status, err := p.cluster.Migration(in.JobId)
 
-	out := &pb.DeleteReply{Deleted: deleted}
+	out := &pb.GetMigrationStatusReply{}
 	if err != nil {
 		out.Error = &pb.Error{Message: err.Error()}
+		return out, nil
+	}
+	out.JobId = status.ID
+	out.ToGroupId = int64(status.ToGroupID)
+	out.After = status.After
+	out.Aborted = status.Aborted
+	out.Done = status.Done
+	out.StartTimeNs = status.StartTime.UnixNano()
+	if !status.EndTime.IsZero() {
+		out.EndTimeNs = status.EndTime.UnixNano()
+	}
+	for _, s := range status.Slots {
+		sm := &pb.SlotMigration{
+			SlotId: int64(s.SlotID),
+			Phase:  s.Phase.String(),
+		}
+		if s.Err != nil {
+			sm.Error = &pb.Error{Message: s.Err.Error()}
+		}
+		out.Slots = append(out.Slots, sm)
 	}
 	return out, nil
 }
 
+func (p *Proxy) AbortMigration(ctx context.Context, in *pb.AbortMigrationRequest) (*pb.AbortMigrationReply, error) {
+	err := p.cluster.AbortMigration(in.JobId)
+	if err == cluster.ErrNotLeader {
+		var out *pb.AbortMigrationReply
+		err = p.forward(ctx, func(ctx context.Context, c pb.GokuProxyClient) (err error) {
+			out, err = c.AbortMigration(ctx, in)
+			return err
+		})
+		if err == nil {
+			return out, nil
+		}
+	}
+
+	out := &pb.AbortMigrationReply{}
+	if err != nil {
+		out.Error = &pb.Error{Message: err.Error()}
+	}
+	return out, nil
+}
+
+func (p *Proxy) Rebalance(ctx context.Context, in *pb.RebalanceRequest) (*pb.RebalanceReply, error) {
+	r := cluster.NewRebalancer(p.cluster, int(in.Concurrency), time.Duration(in.ThrottleNs))
+	if len(in.Weights) > 0 {
+		weights := make(map[int]float64, len(in.Weights))
+		for id, w := range in.Weights {
+			weights[int(id)] = w
+		}
+		r.SetWeights(weights)
+	}
+
+	// The plan can be shown by any node, while it must be executed by the
+	// leader.
+	plan, ids, err := r.Rebalance(in.DryRun)
+	if err == cluster.ErrNotLeader {
+		var out *pb.RebalanceReply
+		err = p.forward(ctx, func(ctx context.Context, c pb.GokuProxyClient) (err error) {
+			out, err = c.Rebalance(ctx, in)
+			return err
+		})
+		if err == nil {
+			return out, nil
+		}
+	}
+
+	out := &pb.RebalanceReply{JobIds: ids}
+	if err != nil {
+		out.Error = &pb.Error{Message: err.Error()}
+		return out, nil
+	}
+	for _, m := range plan.Moves {
+		out.Moves = append(out.Moves, &pb.SlotMove{
+			SlotId:      int64(m.SlotID),
+			FromGroupId: int64(m.FromGroupID),
+			ToGroupId:   int64(m.ToGroupID),
+			Size:        m.Size,
+		})
+	}
+	groupIDs := make([]int, 0, len(plan.Before))
+	for id := range plan.Before {
+		groupIDs = append(groupIDs, id)
+	}
+	sort.Ints(groupIDs)
+	for _, id := range groupIDs {
+		out.Loads = append(out.Loads, &pb.GroupLoad{
+			GroupId: int64(id),
+			Before:  plan.Before[id],
+			After:   plan.After[id],
+		})
+	}
+	return out, nil
+}
+
+func (p *Proxy) Insert(ctx context.Context, in *pb.InsertRequest) (*pb.InsertReply, error) {
+	wr, err := p.lwwset.InsertWithResult(ctx, in.Key, in.Member, in.TimestampNs, time.Duration(in.TtlNs))
+	return toPBInsertReply(wr, err), nil
+}
+
+func (p *Proxy) Delete(ctx context.Context, in *pb.DeleteRequest) (*pb.DeleteReply, error) {
+	wr, err := p.lwwset.DeleteWithResult(ctx, in.Key, in.Member, in.TimestampNs)
+	return toPBDeleteReply(wr, err), nil
+}
+
 func (p *Proxy) Select(ctx context.Context, in *pb.SelectRequest) (*pb.SelectReply, error) {
-	elements, err := p.lwwset.Select(in.Key, in.TimestampNs)
+	elements, err := p.lwwset.Select(ctx, in.Key, in.TimestampNs)
+	return toPBSelectReply(elements, err), nil
+}
 
+func (p *Proxy) BatchInsert(ctx context.Context, in *pb.BatchInsertRequest) (*pb.BatchInsertReply, error) {
+	ops := make([]group.Op, len(in.Items))
+	for i, item := range in.Items {
+		ops[i] = group.Op{
+			Key:       item.Key,
+			Member:    item.Member,
+			Timestamp: item.TimestampNs,
+			TTL:       time.Duration(item.TtlNs),
+		}
+	}
+	results := p.lwwset.BatchInsert(ctx, ops)
+
+	out := &pb.BatchInsertReply{Items: make([]*pb.InsertReply, len(results))}
+	for i, r := range results {
+		out.Items[i] = toPBInsertReply(r.Result, r.Err)
+	}
+	return out, nil
+}
+
+func (p *Proxy) BatchDelete(ctx context.Context, in *pb.BatchDeleteRequest) (*pb.BatchDeleteReply, error) {
+	ops := make([]group.Op, len(in.Items))
+	for i, item := range in.Items {
+		ops[i] = group.Op{
+			Key:       item.Key,
+			Member:    item.Member,
+			Timestamp: item.TimestampNs,
+		}
+	}
+	results := p.lwwset.BatchDelete(ctx, ops)
+
+	out := &pb.BatchDeleteReply{Items: make([]*pb.DeleteReply, len(results))}
+	for i, r := range results {
+		out.Items[i] = toPBDeleteReply(r.Result, r.Err)
+	}
+	return out, nil
+}
+
+func (p *Proxy) MultiSelect(ctx context.Context, in *pb.MultiSelectRequest) (*pb.MultiSelectReply, error) {
+	ops := make([]group.Op, len(in.Items))
+	for i, item := range in.Items {
+		ops[i] = group.Op{
+			Key:       item.Key,
+			Timestamp: item.TimestampNs,
+		}
+	}
+	results := p.lwwset.MultiSelect(ctx, ops)
+
+	out := &pb.MultiSelectReply{Items: make([]*pb.SelectReply, len(results))}
+	for i, r := range results {
+		out.Items[i] = toPBSelectReply(r.Elements, r.Err)
+	}
+	return out, nil
+}
+
+// Health returns the health of the replicas in the given groups, or in all
+// the groups if none is given.
+func (p *Proxy) Health(ctx context.Context, in *pb.HealthRequest) (*pb.HealthReply, error) {
+	ids := make([]int, len(in.GroupIds))
+	for i, id := range in.GroupIds {
+		ids[i] = int(id)
+	}
+
+	out := &pb.HealthReply{}
+	groups := p.cluster.Groups(ids...)
+	for _, id := range sortedGroupIDs(groups) {
+		g, ok := groups[id].(Group)
+		if !ok {
+			out.Error = &pb.Error{Message: fmt.Sprintf("group %d not found", id)}
+			return out, nil
+		}
+		gh := &pb.GroupHealth{GroupId: int64(id)}
+		for _, rh := range g.Health() {
+			h := &pb.ReplicaHealth{
+				Addr:     rh.Addr,
+				State:    rh.State.String(),
+				Failures: int64(rh.Failures),
+				SinceNs:  rh.Since.UnixNano(),
+			}
+			if rh.LastErr != nil {
+				h.Error = &pb.Error{Message: rh.LastErr.Error()}
+			}
+			gh.Replicas = append(gh.Replicas, h)
+		}
+		out.Groups = append(out.Groups, gh)
+	}
+	return out, nil
+}
+
+func (p *Proxy) JoinNode(ctx context.Context, in *pb.JoinNodeRequest) (*pb.JoinNodeReply, error) {
+	err := p.cluster.Join(in.NodeId, in.Addr)
+	if err == cluster.ErrNotLeader {
+		var out *pb.JoinNodeReply
+		err = p.forward(ctx, func(ctx context.Context, c pb.GokuProxyClient) (err error) {
+			out, err = c.JoinNode(ctx, in)
+			return err
+		})
+		if err == nil {
+			return out, nil
+		}
+	}
+
+	out := &pb.JoinNodeReply{}
+	if err != nil {
+		out.Error = &pb.Error{Message: err.Error()}
+	}
+	return out, nil
+}
+
+func (p *Proxy) AddNonVoter(ctx context.Context, in *pb.AddNonVoterRequest) (*pb.AddNonVoterReply, error) {
+	err := p.cluster.AddNonVoter(in.NodeId, in.Addr)
+	if err == cluster.ErrNotLeader {
+		var out *pb.AddNonVoterReply
+		err = p.forward(ctx, func(ctx context.Context, c pb.GokuProxyClient) (err error) {
+			out, err = c.AddNonVoter(ctx, in)
+			return err
+		})
+		if err == nil {
+			return out, nil
+		}
+	}
+
+	out := &pb.AddNonVoterReply{}
+	if err != nil {
+		out.Error = &pb.Error{Message: err.Error()}
+	}
+	return out, nil
+}
+
+func (p *Proxy) RemoveNode(ctx context.Context, in *pb.RemoveNodeRequest) (*pb.RemoveNodeReply, error) {
+	err := p.cluster.RemoveNode(in.NodeId)
+	if err == cluster.ErrNotLeader {
+		var out *pb.RemoveNodeReply
+		err = p.forward(ctx, func(ctx context.Context, c pb.GokuProxyClient) (err error) {
+			out, err = c.RemoveNode(ctx, in)
+			return err
+		})
+		if err == nil {
+			return out, nil
+		}
+	}
+
+	out := &pb.RemoveNodeReply{}
+	if err != nil {
+		out.Error = &pb.Error{Message: err.Error()}
+	}
+	return out, nil
+}
+
+func (p *Proxy) ListNodes(ctx context.Context, in *pb.ListNodesRequest) (*pb.ListNodesReply, error) {
+	out := &pb.ListNodesReply{}
+	nodes, err := p.cluster.Nodes()
+	if err != nil {
+		out.Error = &pb.Error{Message: err.Error()}
+		return out, nil
+	}
+	for _, n := range nodes {
+		out.Nodes = append(out.Nodes, &pb.Node{
+			Id:     n.ID,
+			Addr:   n.Addr,
+			Voter:  n.Voter,
+			Leader: n.Leader,
+		})
+	}
+	return out, nil
+}
+
+func sortedGroupIDs(groups map[int]cluster.Group) []int {
+	ids := make([]int, 0, len(groups))
+	for id := range groups {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// The codes of the errors in the replies.
+const (
+	codeNoQuorum = 1
+)
+
+func toPBError(err error) *pb.Error {
+	e := &pb.Error{Message: err.Error()}
+	if group.IsNoQuorum(err) {
+		e.Code = codeNoQuorum
+	}
+	return e
+}
+
+func toPBInsertReply(wr *group.WriteResult, err error) *pb.InsertReply {
+	out := &pb.InsertReply{}
+	if wr != nil {
+		out.Replicas = toPBReplicaResults(wr)
+	}
+	if err != nil {
+		out.Error = toPBError(err)
+	} else {
+		out.Updated = wr.Status()
+	}
+	return out
+}
+
+func toPBDeleteReply(wr *group.WriteResult, err error) *pb.DeleteReply {
+	out := &pb.DeleteReply{}
+	if wr != nil {
+		out.Replicas = toPBReplicaResults(wr)
+	}
+	if err != nil {
+		out.Error = toPBError(err)
+	} else {
+		out.Deleted = wr.Status()
+	}
+	return out
+}
+
+func toPBSelectReply(elements []common.Element, err error) *pb.SelectReply {
 	out := &pb.SelectReply{}
 	if err != nil {
 		out.Error = &pb.Error{Message: err.Error()}
@@ -86,11 +497,28 @@ func (p *Proxy) Select(ctx context.Context, in *pb.SelectRequest) (*pb.SelectRep
 		out.Elements = make([]*pb.Element, len(elements))
 		for i, e := range elements {
 			out.Elements[i] = &pb.Element{
-				Member: e.Member,
+				Member:      e.Member,
 				TimestampNs: e.Timestamp,
-				TtlNs: int64(e.TTL),
+				TtlNs:       int64(e.TTL),
 			}
 		}
 	}
-	return out, nil
+	return out
+}
+
+func toPBReplicaResults(wr *group.WriteResult) []*pb.ReplicaResult {
+	results := make([]*pb.ReplicaResult, len(wr.Replicas))
+	for i, rr := range wr.Replicas {
+		results[i] = &pb.ReplicaResult{
+			Addr:      rr.Addr,
+			Pending:   rr.Pending,
+			Joining:   rr.Joining,
+			Status:    rr.Status,
+			LatencyNs: rr.Latency.Nanoseconds(),
+		}
+		if rr.Err != nil {
+			results[i].Error = &pb.Error{Message: rr.Err.Error()}
+		}
+	}
+	return results
 }
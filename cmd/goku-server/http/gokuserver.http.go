@@ -27,14 +27,17 @@ func MakeHandler(method Method, in proto.Message) http.HandlerFunc {
 			return
 		}
 
-		if err := unmarshaler.Unmarshal(r.Body, in); err != nil {
+		// Unmarshal into a new message per request, or the fields absent
+		// from a request would be left by the previous one.
+		req := proto.Clone(in)
+		if err := unmarshaler.Unmarshal(r.Body, req); err != nil {
 			if err != io.EOF {
 				w.WriteHeader(http.StatusBadRequest)
 				return
 			}
 		}
 
-		out, err := method(nil, in)
+		out, err := method(r.Context(), req)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
 			return
@@ -63,6 +66,14 @@ func (g *GokuServer) HandlerMap() map[string]http.HandlerFunc {
 	m["/goku_server/insert"] = MakeHandler(g.Insert, new(pb.InsertRequest))
 	m["/goku_server/delete"] = MakeHandler(g.Delete, new(pb.DeleteRequest))
 	m["/goku_server/select"] = MakeHandler(g.Select, new(pb.SelectRequest))
+	m["/goku_server/batch_insert"] = MakeHandler(g.BatchInsert, new(pb.BatchInsertRequest))
+	m["/goku_server/batch_delete"] = MakeHandler(g.BatchDelete, new(pb.BatchDeleteRequest))
+	m["/goku_server/multi_select"] = MakeHandler(g.MultiSelect, new(pb.MultiSelectRequest))
+	m["/goku_server/dump"] = MakeHandler(g.Dump, new(pb.DumpRequest))
+	m["/goku_server/purge"] = MakeHandler(g.Purge, new(pb.PurgeRequest))
+	m["/goku_server/import_slot"] = MakeHandler(g.ImportSlot, new(pb.ImportSlotRequest))
+	m["/goku_server/drop_slot"] = MakeHandler(g.DropSlot, new(pb.DropSlotRequest))
+	m["/goku_server/tree"] = MakeHandler(g.Tree, new(pb.TreeRequest))
 	return m
 }
 
@@ -120,6 +131,150 @@ func (g *GokuServer) Select(ctx context.Context, in proto.Message) (proto.Messag
 	return out.(*pb.SelectReply), err
 }
 
+func (g *GokuServer) BatchInsert(ctx context.Context, in proto.Message) (proto.Message, error) {
+	if g.interceptor == nil {
+		return g.srv.BatchInsert(ctx, in.(*pb.BatchInsertRequest))
+	}
+	out, err := g.interceptor(
+		ctx,
+		in.(*pb.BatchInsertRequest),
+		&grpc.UnaryServerInfo{
+			Server:     g.srv,
+			FullMethod: "/pb.GokuServer/BatchInsert",
+		},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return g.srv.BatchInsert(ctx, req.(*pb.BatchInsertRequest))
+		},
+	)
+	return out.(*pb.BatchInsertReply), err
+}
+
+func (g *GokuServer) BatchDelete(ctx context.Context, in proto.Message) (proto.Message, error) {
+	if g.interceptor == nil {
+		return g.srv.BatchDelete(ctx, in.(*pb.BatchDeleteRequest))
+	}
+	out, err := g.interceptor(
+		ctx,
+		in.(*pb.BatchDeleteRequest),
+		&grpc.UnaryServerInfo{
+			Server:     g.srv,
+			FullMethod: "/pb.GokuServer/BatchDelete",
+		},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return g.srv.BatchDelete(ctx, req.(*pb.BatchDeleteRequest))
+		},
+	)
+	return out.(*pb.BatchDeleteReply), err
+}
+
+func (g *GokuServer) MultiSelect(ctx context.Context, in proto.Message) (proto.Message, error) {
+	if g.interceptor == nil {
+		return g.srv.MultiSelect(ctx, in.(*pb.MultiSelectRequest))
+	}
+	out, err := g.interceptor(
+		ctx,
+		in.(*pb.MultiSelectRequest),
+		&grpc.UnaryServerInfo{
+			Server:     g.srv,
+			FullMethod: "/pb.GokuServer/MultiSelect",
+		},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return g.srv.MultiSelect(ctx, req.(*pb.MultiSelectRequest))
+		},
+	)
+	return out.(*pb.MultiSelectReply), err
+}
+
+func (g *GokuServer) Dump(ctx context.Context, in proto.Message) (proto.Message, error) {
+	if g.interceptor == nil {
+		return g.srv.Dump(ctx, in.(*pb.DumpRequest))
+	}
+	out, err := g.interceptor(
+		ctx,
+		in.(*pb.DumpRequest),
+		&grpc.UnaryServerInfo{
+			Server:     g.srv,
+			FullMethod: "/pb.GokuServer/Dump",
+		},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return g.srv.Dump(ctx, req.(*pb.DumpRequest))
+		},
+	)
+	return out.(*pb.DumpReply), err
+}
+
+func (g *GokuServer) Purge(ctx context.Context, in proto.Message) (proto.Message, error) {
+	if g.interceptor == nil {
+		return g.srv.Purge(ctx, in.(*pb.PurgeRequest))
+	}
+	out, err := g.interceptor(
+		ctx,
+		in.(*pb.PurgeRequest),
+		&grpc.UnaryServerInfo{
+			Server:     g.srv,
+			FullMethod: "/pb.GokuServer/Purge",
+		},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return g.srv.Purge(ctx, req.(*pb.PurgeRequest))
+		},
+	)
+	return out.(*pb.PurgeReply), err
+}
+
+func (g *GokuServer) ImportSlot(ctx context.Context, in proto.Message) (proto.Message, error) {
+	if g.interceptor == nil {
+		return g.srv.ImportSlot(ctx, in.(*pb.ImportSlotRequest))
+	}
+	out, err := g.interceptor(
+		ctx,
+		in.(*pb.ImportSlotRequest),
+		&grpc.UnaryServerInfo{
+			Server:     g.srv,
+			FullMethod: "/pb.GokuServer/ImportSlot",
+		},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return g.srv.ImportSlot(ctx, req.(*pb.ImportSlotRequest))
+		},
+	)
+	return out.(*pb.ImportSlotReply), err
+}
+
+func (g *GokuServer) DropSlot(ctx context.Context, in proto.Message) (proto.Message, error) {
+	if g.interceptor == nil {
+		return g.srv.DropSlot(ctx, in.(*pb.DropSlotRequest))
+	}
+	out, err := g.interceptor(
+		ctx,
+		in.(*pb.DropSlotRequest),
+		&grpc.UnaryServerInfo{
+			Server:     g.srv,
+			FullMethod: "/pb.GokuServer/DropSlot",
+		},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return g.srv.DropSlot(ctx, req.(*pb.DropSlotRequest))
+		},
+	)
+	return out.(*pb.DropSlotReply), err
+}
+
+func (g *GokuServer) Tree(ctx context.Context, in proto.Message) (proto.Message, error) {
+	if g.interceptor == nil {
+		return g.srv.Tree(ctx, in.(*pb.TreeRequest))
+	}
+	out, err := g.interceptor(
+		ctx,
+		in.(*pb.TreeRequest),
+		&grpc.UnaryServerInfo{
+			Server:     g.srv,
+			FullMethod: "/pb.GokuServer/Tree",
+		},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return g.srv.Tree(ctx, req.(*pb.TreeRequest))
+		},
+	)
+	return out.(*pb.TreeReply), err
+}
+
 type Server struct {
 	mux         *http.ServeMux
 	interceptor grpc.UnaryServerInterceptor
@@ -1,52 +1,152 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
 	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
 
 	"github.com/soheilhy/cmux"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 
 	"github.com/RussellLuo/goku/cmd/goku-server/http"
 	"github.com/RussellLuo/goku/cmd/goku-server/pb"
 	"github.com/RussellLuo/goku/server"
 )
 
-func serve(srv pb.GokuServerServer, addr string) error {
+// serve serves srv on addr until a signal is received from sigC, and then
+// stops the listeners gracefully, i.e. waits for the pending gRPC calls.
+func serve(srv pb.GokuServerServer, addr string, sigC <-chan os.Signal) error {
 	lis, err := net.Listen("tcp", addr)
 	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
+		return fmt.Errorf("failed to listen: %v", err)
 	}
 
 	m := cmux.New(lis)
 	grpcL := m.MatchWithWriters(cmux.HTTP2MatchHeaderFieldPrefixSendSettings("content-type", "application/grpc"))
 	httpL := m.Match(cmux.Any())
 
+	// The errors of the HTTP server, the gRPC server and the mux.
+	errC := make(chan error, 3)
+
 	httpS := http.NewServer()
 	httpS.RegisterGokuServerServer(srv)
 	go func() {
-		if err := httpS.Serve(httpL); err != nil {
-			log.Fatalf("failed to start HTTP server listening: %v", err)
-		}
+		errC <- fmt.Errorf("failed to start HTTP server listening: %v", httpS.Serve(httpL))
 	}()
 
 	grpcS := grpc.NewServer()
 	pb.RegisterGokuServerServer(grpcS, srv)
+	// Serve the gRPC health protocol, which is used by the proxies to
+	// probe the server.
+	hs := health.NewServer()
+	hs.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(grpcS, hs)
 	go func() {
-		if err := grpcS.Serve(grpcL); err != nil {
-			log.Fatalf("failed to serve: %v", err)
-		}
+		errC <- fmt.Errorf("failed to serve: %v", grpcS.Serve(grpcL))
+	}()
+
+	go func() {
+		errC <- m.Serve()
 	}()
 
-	return m.Serve()
+	select {
+	case err := <-errC:
+		return err
+	case sig := <-sigC:
+		log.Printf("received signal %v, shutting down", sig)
+	}
+
+	// Let the proxies stop sending requests to this server, and then stop
+	// accepting new connections.
+	hs.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	grpcS.GracefulStop()
+	lis.Close()
+	return nil
+}
+
+// newServer creates a server with the given storage backend:
+//
+//	memory: all data is kept in memory, which will be persisted by
+//	        a write-ahead log and snapshots if dataDir is not empty.
+//	bolt:   all data is kept in a BoltDB database in dataDir.
+func newServer(backend, dataDir, syncPolicy string) (*server.Server, error) {
+	switch backend {
+	case "memory":
+		if dataDir == "" {
+			return server.NewServer(), nil
+		}
+
+		policy, err := server.ParseSyncPolicy(syncPolicy)
+		if err != nil {
+			return nil, err
+		}
+		return server.Open(dataDir, policy)
+	case "bolt":
+		if dataDir == "" {
+			return nil, fmt.Errorf("data dir is required by bolt")
+		}
+		if err := os.MkdirAll(dataDir, 0755); err != nil {
+			return nil, err
+		}
+
+		return server.OpenWithBolt(filepath.Join(dataDir, "goku.db"))
+	default:
+		return nil, fmt.Errorf("unrecognized storage backend: %s", backend)
+	}
 }
 
 func main() {
-	// TODO: Read arguments from flags.
-	proxyAddr := ":50052"
+	var (
+		serverAddr     = flag.String("addr", ":50052", "The address to listen on")
+		tombstoneGrace = flag.Duration("tombstone-grace", 24*time.Hour, "The grace period before purging a tombstone, which must be longer than the maximum replication lag")
+		gcInterval     = flag.Duration("gc-interval", time.Minute, "The interval between two tombstone collections")
+		expireInterval = flag.Duration("expire-interval", 100*time.Millisecond, "The interval between two active expirations")
+		expireBatch    = flag.Int("expire-batch", 20, "The maximum number of elements expired per slot lock")
+		backend        = flag.String("store", "memory", "The storage backend: memory or bolt")
+		dataDir        = flag.String("data-dir", "", "The directory to persist data in (in-memory only if empty)")
+		syncPolicy     = flag.String("sync", "everysec", "When to flush the write-ahead log: always, everysec or never")
+		snapInterval   = flag.Duration("snapshot-interval", 5*time.Minute, "The interval between two snapshots")
+	)
+	flag.Parse()
+
+	if *expireBatch <= 0 {
+		log.Fatalf("expire-batch must be positive: %d", *expireBatch)
+	}
+
+	ss, err := newServer(*backend, *dataDir, *syncPolicy)
+	if err != nil {
+		log.Fatalf("failed to create server: %v", err)
+	}
 
-	s := NewServer(server.NewServer())
-	if err := serve(s, proxyAddr); err != nil {
+	workers := []server.Worker{
+		server.NewCollector(ss, *tombstoneGrace, *gcInterval),
+		server.NewExpirer(ss, *expireInterval, *expireBatch),
+	}
+	if *backend == "memory" && *dataDir != "" {
+		workers = append(workers, server.NewSnapshotter(ss, *snapInterval))
+	}
+	ss.StartX(workers...)
+
+	sigC := make(chan os.Signal, 1)
+	signal.Notify(sigC, syscall.SIGINT, syscall.SIGTERM)
+
+	s := NewServer(ss)
+	err = serve(s, *serverAddr, sigC)
+
+	// Close the server explicitly, since log.Fatalf does not run the
+	// deferred calls.
+	if cerr := ss.Close(); cerr != nil {
+		log.Printf("failed to close server: %v", cerr)
+	}
+	if err != nil {
 		log.Fatalf("err: %v", err)
 	}
 }
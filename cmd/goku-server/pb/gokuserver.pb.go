@@ -17,6 +17,28 @@ It has these top-level messages:
 	DeleteReply
 	SelectRequest
 	SelectReply
+	BatchInsertRequest
+	BatchInsertReply
+	BatchDeleteRequest
+	BatchDeleteReply
+	MultiSelectRequest
+	MultiSelectReply
+	DumpRequest
+	DumpReply
+	PurgeRequest
+	PurgeReply
+	KeysRequest
+	KeysReply
+	Entry
+	ExportSlotRequest
+	ExportSlotReply
+	ImportSlotRequest
+	ImportSlotReply
+	DropSlotRequest
+	DropSlotReply
+	TreeRequest
+	TreeReply
+	TreeKeysRequest
 */
 package pb
 
@@ -68,6 +90,7 @@ type Element struct {
 	Member      string `protobuf:"bytes,3,opt,name=member" json:"member,omitempty"`
 	TimestampNs int64  `protobuf:"varint,4,opt,name=timestamp_ns,json=timestampNs" json:"timestamp_ns,omitempty"`
 	TtlNs       int64  `protobuf:"varint,5,opt,name=ttl_ns,json=ttlNs" json:"ttl_ns,omitempty"`
+	Deleted     bool   `protobuf:"varint,6,opt,name=deleted" json:"deleted,omitempty"`
 }
 
 func (m *Element) Reset()                    { *m = Element{} }
@@ -96,6 +119,13 @@ func (m *Element) GetTtlNs() int64 {
 	return 0
 }
 
+func (m *Element) GetDeleted() bool {
+	if m != nil {
+		return m.Deleted
+	}
+	return false
+}
+
 type InsertRequest struct {
 	SlotId      int64  `protobuf:"varint,1,opt,name=slot_id,json=slotId" json:"slot_id,omitempty"`
 	Key         string `protobuf:"bytes,2,opt,name=key" json:"key,omitempty"`
@@ -288,181 +318,1219 @@ func (m *SelectReply) GetError() *Error {
 	return nil
 }
 
-func init() {
-	proto.RegisterType((*Error)(nil), "pb.Error")
-	proto.RegisterType((*Element)(nil), "pb.Element")
-	proto.RegisterType((*InsertRequest)(nil), "pb.InsertRequest")
-	proto.RegisterType((*InsertReply)(nil), "pb.InsertReply")
-	proto.RegisterType((*DeleteRequest)(nil), "pb.DeleteRequest")
-	proto.RegisterType((*DeleteReply)(nil), "pb.DeleteReply")
-	proto.RegisterType((*SelectRequest)(nil), "pb.SelectRequest")
-	proto.RegisterType((*SelectReply)(nil), "pb.SelectReply")
+type BatchInsertRequest struct {
+	Items []*InsertRequest `protobuf:"bytes,1,rep,name=items" json:"items,omitempty"`
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+func (m *BatchInsertRequest) Reset()                    { *m = BatchInsertRequest{} }
+func (m *BatchInsertRequest) String() string            { return proto.CompactTextString(m) }
+func (*BatchInsertRequest) ProtoMessage()               {}
+func (*BatchInsertRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion3
+func (m *BatchInsertRequest) GetItems() []*InsertRequest {
+	if m != nil {
+		return m.Items
+	}
+	return nil
+}
 
-// Client API for GokuServer service
+type BatchInsertReply struct {
+	Items []*InsertReply `protobuf:"bytes,1,rep,name=items" json:"items,omitempty"`
+}
 
-type GokuServerClient interface {
-	Insert(ctx context.Context, in *InsertRequest, opts ...grpc.CallOption) (*InsertReply, error)
-	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteReply, error)
-	Select(ctx context.Context, in *SelectRequest, opts ...grpc.CallOption) (*SelectReply, error)
+func (m *BatchInsertReply) Reset()                    { *m = BatchInsertReply{} }
+func (m *BatchInsertReply) String() string            { return proto.CompactTextString(m) }
+func (*BatchInsertReply) ProtoMessage()               {}
+func (*BatchInsertReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *BatchInsertReply) GetItems() []*InsertReply {
+	if m != nil {
+		return m.Items
+	}
+	return nil
 }
 
-type gokuServerClient struct {
-	cc *grpc.ClientConn
+type BatchDeleteRequest struct {
+	Items []*DeleteRequest `protobuf:"bytes,1,rep,name=items" json:"items,omitempty"`
 }
 
-func NewGokuServerClient(cc *grpc.ClientConn) GokuServerClient {
-	return &gokuServerClient{cc}
+func (m *BatchDeleteRequest) Reset()                    { *m = BatchDeleteRequest{} }
+func (m *BatchDeleteRequest) String() string            { return proto.CompactTextString(m) }
+func (*BatchDeleteRequest) ProtoMessage()               {}
+func (*BatchDeleteRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *BatchDeleteRequest) GetItems() []*DeleteRequest {
+	if m != nil {
+		return m.Items
+	}
+	return nil
 }
 
-func (c *gokuServerClient) Insert(ctx context.Context, in *InsertRequest, opts ...grpc.CallOption) (*InsertReply, error) {
-	out := new(InsertReply)
-	err := grpc.Invoke(ctx, "/pb.GokuServer/Insert", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+type BatchDeleteReply struct {
+	Items []*DeleteReply `protobuf:"bytes,1,rep,name=items" json:"items,omitempty"`
+}
+
+func (m *BatchDeleteReply) Reset()                    { *m = BatchDeleteReply{} }
+func (m *BatchDeleteReply) String() string            { return proto.CompactTextString(m) }
+func (*BatchDeleteReply) ProtoMessage()               {}
+func (*BatchDeleteReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+func (m *BatchDeleteReply) GetItems() []*DeleteReply {
+	if m != nil {
+		return m.Items
 	}
-	return out, nil
+	return nil
 }
 
-func (c *gokuServerClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteReply, error) {
-	out := new(DeleteReply)
-	err := grpc.Invoke(ctx, "/pb.GokuServer/Delete", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+type MultiSelectRequest struct {
+	Items []*SelectRequest `protobuf:"bytes,1,rep,name=items" json:"items,omitempty"`
+}
+
+func (m *MultiSelectRequest) Reset()                    { *m = MultiSelectRequest{} }
+func (m *MultiSelectRequest) String() string            { return proto.CompactTextString(m) }
+func (*MultiSelectRequest) ProtoMessage()               {}
+func (*MultiSelectRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *MultiSelectRequest) GetItems() []*SelectRequest {
+	if m != nil {
+		return m.Items
 	}
-	return out, nil
+	return nil
 }
 
-func (c *gokuServerClient) Select(ctx context.Context, in *SelectRequest, opts ...grpc.CallOption) (*SelectReply, error) {
-	out := new(SelectReply)
-	err := grpc.Invoke(ctx, "/pb.GokuServer/Select", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+type MultiSelectReply struct {
+	Items []*SelectReply `protobuf:"bytes,1,rep,name=items" json:"items,omitempty"`
+}
+
+func (m *MultiSelectReply) Reset()                    { *m = MultiSelectReply{} }
+func (m *MultiSelectReply) String() string            { return proto.CompactTextString(m) }
+func (*MultiSelectReply) ProtoMessage()               {}
+func (*MultiSelectReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+func (m *MultiSelectReply) GetItems() []*SelectReply {
+	if m != nil {
+		return m.Items
 	}
-	return out, nil
+	return nil
 }
 
-// Server API for GokuServer service
+type DumpRequest struct {
+	SlotId      int64  `protobuf:"varint,1,opt,name=slot_id,json=slotId" json:"slot_id,omitempty"`
+	Key         string `protobuf:"bytes,2,opt,name=key" json:"key,omitempty"`
+	TimestampNs int64  `protobuf:"varint,3,opt,name=timestamp_ns,json=timestampNs" json:"timestamp_ns,omitempty"`
+}
 
-type GokuServerServer interface {
-	Insert(context.Context, *InsertRequest) (*InsertReply, error)
-	Delete(context.Context, *DeleteRequest) (*DeleteReply, error)
-	Select(context.Context, *SelectRequest) (*SelectReply, error)
+func (m *DumpRequest) Reset()                    { *m = DumpRequest{} }
+func (m *DumpRequest) String() string            { return proto.CompactTextString(m) }
+func (*DumpRequest) ProtoMessage()               {}
+func (*DumpRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *DumpRequest) GetSlotId() int64 {
+	if m != nil {
+		return m.SlotId
+	}
+	return 0
 }
 
-func RegisterGokuServerServer(s *grpc.Server, srv GokuServerServer) {
-	s.RegisterService(&_GokuServer_serviceDesc, srv)
+func (m *DumpRequest) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
 }
 
-func _GokuServer_Insert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(InsertRequest)
-	if err := dec(in); err != nil {
-		return nil, err
+func (m *DumpRequest) GetTimestampNs() int64 {
+	if m != nil {
+		return m.TimestampNs
 	}
-	if interceptor == nil {
-		return srv.(GokuServerServer).Insert(ctx, in)
+	return 0
+}
+
+type DumpReply struct {
+	Elements []*Element `protobuf:"bytes,1,rep,name=elements" json:"elements,omitempty"`
+	Error    *Error     `protobuf:"bytes,2,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *DumpReply) Reset()                    { *m = DumpReply{} }
+func (m *DumpReply) String() string            { return proto.CompactTextString(m) }
+func (*DumpReply) ProtoMessage()               {}
+func (*DumpReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+func (m *DumpReply) GetElements() []*Element {
+	if m != nil {
+		return m.Elements
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/pb.GokuServer/Insert",
+	return nil
+}
+
+func (m *DumpReply) GetError() *Error {
+	if m != nil {
+		return m.Error
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(GokuServerServer).Insert(ctx, req.(*InsertRequest))
+	return nil
+}
+
+type PurgeRequest struct {
+	SlotId   int64      `protobuf:"varint,1,opt,name=slot_id,json=slotId" json:"slot_id,omitempty"`
+	Key      string     `protobuf:"bytes,2,opt,name=key" json:"key,omitempty"`
+	Elements []*Element `protobuf:"bytes,3,rep,name=elements" json:"elements,omitempty"`
+}
+
+func (m *PurgeRequest) Reset()                    { *m = PurgeRequest{} }
+func (m *PurgeRequest) String() string            { return proto.CompactTextString(m) }
+func (*PurgeRequest) ProtoMessage()               {}
+func (*PurgeRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *PurgeRequest) GetSlotId() int64 {
+	if m != nil {
+		return m.SlotId
 	}
-	return interceptor(ctx, in, info, handler)
+	return 0
 }
 
-func _GokuServer_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DeleteRequest)
-	if err := dec(in); err != nil {
-		return nil, err
+func (m *PurgeRequest) GetKey() string {
+	if m != nil {
+		return m.Key
 	}
-	if interceptor == nil {
-		return srv.(GokuServerServer).Delete(ctx, in)
+	return ""
+}
+
+func (m *PurgeRequest) GetElements() []*Element {
+	if m != nil {
+		return m.Elements
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/pb.GokuServer/Delete",
+	return nil
+}
+
+type PurgeReply struct {
+	Purged int64  `protobuf:"varint,1,opt,name=purged" json:"purged,omitempty"`
+	Error  *Error `protobuf:"bytes,2,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *PurgeReply) Reset()                    { *m = PurgeReply{} }
+func (m *PurgeReply) String() string            { return proto.CompactTextString(m) }
+func (*PurgeReply) ProtoMessage()               {}
+func (*PurgeReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+
+func (m *PurgeReply) GetPurged() int64 {
+	if m != nil {
+		return m.Purged
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(GokuServerServer).Delete(ctx, req.(*DeleteRequest))
+	return 0
+}
+
+func (m *PurgeReply) GetError() *Error {
+	if m != nil {
+		return m.Error
 	}
-	return interceptor(ctx, in, info, handler)
+	return nil
 }
 
-func _GokuServer_Select_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(SelectRequest)
-	if err := dec(in); err != nil {
-		return nil, err
+type KeysRequest struct {
+	SlotId    int64 `protobuf:"varint,1,opt,name=slot_id,json=slotId" json:"slot_id,omitempty"`
+	BatchSize int64 `protobuf:"varint,2,opt,name=batch_size,json=batchSize" json:"batch_size,omitempty"`
+}
+
+func (m *KeysRequest) Reset()                    { *m = KeysRequest{} }
+func (m *KeysRequest) String() string            { return proto.CompactTextString(m) }
+func (*KeysRequest) ProtoMessage()               {}
+func (*KeysRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+
+func (m *KeysRequest) GetSlotId() int64 {
+	if m != nil {
+		return m.SlotId
 	}
-	if interceptor == nil {
-		return srv.(GokuServerServer).Select(ctx, in)
+	return 0
+}
+
+func (m *KeysRequest) GetBatchSize() int64 {
+	if m != nil {
+		return m.BatchSize
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/pb.GokuServer/Select",
+	return 0
+}
+
+type KeysReply struct {
+	Keys  []string `protobuf:"bytes,1,rep,name=keys" json:"keys,omitempty"`
+	Error *Error   `protobuf:"bytes,2,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *KeysReply) Reset()                    { *m = KeysReply{} }
+func (m *KeysReply) String() string            { return proto.CompactTextString(m) }
+func (*KeysReply) ProtoMessage()               {}
+func (*KeysReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+
+func (m *KeysReply) GetKeys() []string {
+	if m != nil {
+		return m.Keys
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(GokuServerServer).Select(ctx, req.(*SelectRequest))
+	return nil
+}
+
+func (m *KeysReply) GetError() *Error {
+	if m != nil {
+		return m.Error
 	}
-	return interceptor(ctx, in, info, handler)
+	return nil
 }
 
-var _GokuServer_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "pb.GokuServer",
-	HandlerType: (*GokuServerServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "Insert",
-			Handler:    _GokuServer_Insert_Handler,
-		},
-		{
-			MethodName: "Delete",
-			Handler:    _GokuServer_Delete_Handler,
-		},
-		{
-			MethodName: "Select",
-			Handler:    _GokuServer_Select_Handler,
+type Entry struct {
+	Key     string   `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
+	Element *Element `protobuf:"bytes,2,opt,name=element" json:"element,omitempty"`
+}
+
+func (m *Entry) Reset()                    { *m = Entry{} }
+func (m *Entry) String() string            { return proto.CompactTextString(m) }
+func (*Entry) ProtoMessage()               {}
+func (*Entry) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+
+func (m *Entry) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *Entry) GetElement() *Element {
+	if m != nil {
+		return m.Element
+	}
+	return nil
+}
+
+type ExportSlotRequest struct {
+	SlotId    int64  `protobuf:"varint,1,opt,name=slot_id,json=slotId" json:"slot_id,omitempty"`
+	Cursor    []byte `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	BatchSize int64  `protobuf:"varint,3,opt,name=batch_size,json=batchSize" json:"batch_size,omitempty"`
+}
+
+func (m *ExportSlotRequest) Reset()                    { *m = ExportSlotRequest{} }
+func (m *ExportSlotRequest) String() string            { return proto.CompactTextString(m) }
+func (*ExportSlotRequest) ProtoMessage()               {}
+func (*ExportSlotRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
+
+func (m *ExportSlotRequest) GetSlotId() int64 {
+	if m != nil {
+		return m.SlotId
+	}
+	return 0
+}
+
+func (m *ExportSlotRequest) GetCursor() []byte {
+	if m != nil {
+		return m.Cursor
+	}
+	return nil
+}
+
+func (m *ExportSlotRequest) GetBatchSize() int64 {
+	if m != nil {
+		return m.BatchSize
+	}
+	return 0
+}
+
+type ExportSlotReply struct {
+	Entries []*Entry `protobuf:"bytes,1,rep,name=entries" json:"entries,omitempty"`
+	Cursor  []byte   `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Error   *Error   `protobuf:"bytes,3,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *ExportSlotReply) Reset()                    { *m = ExportSlotReply{} }
+func (m *ExportSlotReply) String() string            { return proto.CompactTextString(m) }
+func (*ExportSlotReply) ProtoMessage()               {}
+func (*ExportSlotReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+
+func (m *ExportSlotReply) GetEntries() []*Entry {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+func (m *ExportSlotReply) GetCursor() []byte {
+	if m != nil {
+		return m.Cursor
+	}
+	return nil
+}
+
+func (m *ExportSlotReply) GetError() *Error {
+	if m != nil {
+		return m.Error
+	}
+	return nil
+}
+
+type ImportSlotRequest struct {
+	SlotId    int64  `protobuf:"varint,1,opt,name=slot_id,json=slotId" json:"slot_id,omitempty"`
+	Source    string `protobuf:"bytes,2,opt,name=source" json:"source,omitempty"`
+	Cursor    []byte `protobuf:"bytes,3,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	BatchSize int64  `protobuf:"varint,4,opt,name=batch_size,json=batchSize" json:"batch_size,omitempty"`
+}
+
+func (m *ImportSlotRequest) Reset()                    { *m = ImportSlotRequest{} }
+func (m *ImportSlotRequest) String() string            { return proto.CompactTextString(m) }
+func (*ImportSlotRequest) ProtoMessage()               {}
+func (*ImportSlotRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+
+func (m *ImportSlotRequest) GetSlotId() int64 {
+	if m != nil {
+		return m.SlotId
+	}
+	return 0
+}
+
+func (m *ImportSlotRequest) GetSource() string {
+	if m != nil {
+		return m.Source
+	}
+	return ""
+}
+
+func (m *ImportSlotRequest) GetCursor() []byte {
+	if m != nil {
+		return m.Cursor
+	}
+	return nil
+}
+
+func (m *ImportSlotRequest) GetBatchSize() int64 {
+	if m != nil {
+		return m.BatchSize
+	}
+	return 0
+}
+
+type ImportSlotReply struct {
+	Imported int64  `protobuf:"varint,1,opt,name=imported" json:"imported,omitempty"`
+	Cursor   []byte `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Error    *Error `protobuf:"bytes,3,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *ImportSlotReply) Reset()                    { *m = ImportSlotReply{} }
+func (m *ImportSlotReply) String() string            { return proto.CompactTextString(m) }
+func (*ImportSlotReply) ProtoMessage()               {}
+func (*ImportSlotReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
+
+func (m *ImportSlotReply) GetImported() int64 {
+	if m != nil {
+		return m.Imported
+	}
+	return 0
+}
+
+func (m *ImportSlotReply) GetCursor() []byte {
+	if m != nil {
+		return m.Cursor
+	}
+	return nil
+}
+
+func (m *ImportSlotReply) GetError() *Error {
+	if m != nil {
+		return m.Error
+	}
+	return nil
+}
+
+type DropSlotRequest struct {
+	SlotId int64 `protobuf:"varint,1,opt,name=slot_id,json=slotId" json:"slot_id,omitempty"`
+}
+
+func (m *DropSlotRequest) Reset()                    { *m = DropSlotRequest{} }
+func (m *DropSlotRequest) String() string            { return proto.CompactTextString(m) }
+func (*DropSlotRequest) ProtoMessage()               {}
+func (*DropSlotRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
+
+func (m *DropSlotRequest) GetSlotId() int64 {
+	if m != nil {
+		return m.SlotId
+	}
+	return 0
+}
+
+type DropSlotReply struct {
+	Error *Error `protobuf:"bytes,1,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *DropSlotReply) Reset()                    { *m = DropSlotReply{} }
+func (m *DropSlotReply) String() string            { return proto.CompactTextString(m) }
+func (*DropSlotReply) ProtoMessage()               {}
+func (*DropSlotReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
+
+func (m *DropSlotReply) GetError() *Error {
+	if m != nil {
+		return m.Error
+	}
+	return nil
+}
+
+type TreeRequest struct {
+	SlotId int64   `protobuf:"varint,1,opt,name=slot_id,json=slotId" json:"slot_id,omitempty"`
+	Nodes  []int64 `protobuf:"varint,2,rep,packed,name=nodes" json:"nodes,omitempty"`
+}
+
+func (m *TreeRequest) Reset()                    { *m = TreeRequest{} }
+func (m *TreeRequest) String() string            { return proto.CompactTextString(m) }
+func (*TreeRequest) ProtoMessage()               {}
+func (*TreeRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
+
+func (m *TreeRequest) GetSlotId() int64 {
+	if m != nil {
+		return m.SlotId
+	}
+	return 0
+}
+
+func (m *TreeRequest) GetNodes() []int64 {
+	if m != nil {
+		return m.Nodes
+	}
+	return nil
+}
+
+type TreeReply struct {
+	Hashes []uint64 `protobuf:"varint,1,rep,packed,name=hashes" json:"hashes,omitempty"`
+	Leaves int64    `protobuf:"varint,2,opt,name=leaves" json:"leaves,omitempty"`
+	Error  *Error   `protobuf:"bytes,3,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *TreeReply) Reset()                    { *m = TreeReply{} }
+func (m *TreeReply) String() string            { return proto.CompactTextString(m) }
+func (*TreeReply) ProtoMessage()               {}
+func (*TreeReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{28} }
+
+func (m *TreeReply) GetHashes() []uint64 {
+	if m != nil {
+		return m.Hashes
+	}
+	return nil
+}
+
+func (m *TreeReply) GetLeaves() int64 {
+	if m != nil {
+		return m.Leaves
+	}
+	return 0
+}
+
+func (m *TreeReply) GetError() *Error {
+	if m != nil {
+		return m.Error
+	}
+	return nil
+}
+
+type TreeKeysRequest struct {
+	SlotId    int64   `protobuf:"varint,1,opt,name=slot_id,json=slotId" json:"slot_id,omitempty"`
+	Leaves    []int64 `protobuf:"varint,2,rep,packed,name=leaves" json:"leaves,omitempty"`
+	BatchSize int64   `protobuf:"varint,3,opt,name=batch_size,json=batchSize" json:"batch_size,omitempty"`
+}
+
+func (m *TreeKeysRequest) Reset()                    { *m = TreeKeysRequest{} }
+func (m *TreeKeysRequest) String() string            { return proto.CompactTextString(m) }
+func (*TreeKeysRequest) ProtoMessage()               {}
+func (*TreeKeysRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{29} }
+
+func (m *TreeKeysRequest) GetSlotId() int64 {
+	if m != nil {
+		return m.SlotId
+	}
+	return 0
+}
+
+func (m *TreeKeysRequest) GetLeaves() []int64 {
+	if m != nil {
+		return m.Leaves
+	}
+	return nil
+}
+
+func (m *TreeKeysRequest) GetBatchSize() int64 {
+	if m != nil {
+		return m.BatchSize
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*Error)(nil), "pb.Error")
+	proto.RegisterType((*Element)(nil), "pb.Element")
+	proto.RegisterType((*InsertRequest)(nil), "pb.InsertRequest")
+	proto.RegisterType((*InsertReply)(nil), "pb.InsertReply")
+	proto.RegisterType((*DeleteRequest)(nil), "pb.DeleteRequest")
+	proto.RegisterType((*DeleteReply)(nil), "pb.DeleteReply")
+	proto.RegisterType((*SelectRequest)(nil), "pb.SelectRequest")
+	proto.RegisterType((*SelectReply)(nil), "pb.SelectReply")
+	proto.RegisterType((*BatchInsertRequest)(nil), "pb.BatchInsertRequest")
+	proto.RegisterType((*BatchInsertReply)(nil), "pb.BatchInsertReply")
+	proto.RegisterType((*BatchDeleteRequest)(nil), "pb.BatchDeleteRequest")
+	proto.RegisterType((*BatchDeleteReply)(nil), "pb.BatchDeleteReply")
+	proto.RegisterType((*MultiSelectRequest)(nil), "pb.MultiSelectRequest")
+	proto.RegisterType((*MultiSelectReply)(nil), "pb.MultiSelectReply")
+	proto.RegisterType((*DumpRequest)(nil), "pb.DumpRequest")
+	proto.RegisterType((*DumpReply)(nil), "pb.DumpReply")
+	proto.RegisterType((*PurgeRequest)(nil), "pb.PurgeRequest")
+	proto.RegisterType((*PurgeReply)(nil), "pb.PurgeReply")
+	proto.RegisterType((*KeysRequest)(nil), "pb.KeysRequest")
+	proto.RegisterType((*KeysReply)(nil), "pb.KeysReply")
+	proto.RegisterType((*Entry)(nil), "pb.Entry")
+	proto.RegisterType((*ExportSlotRequest)(nil), "pb.ExportSlotRequest")
+	proto.RegisterType((*ExportSlotReply)(nil), "pb.ExportSlotReply")
+	proto.RegisterType((*ImportSlotRequest)(nil), "pb.ImportSlotRequest")
+	proto.RegisterType((*ImportSlotReply)(nil), "pb.ImportSlotReply")
+	proto.RegisterType((*DropSlotRequest)(nil), "pb.DropSlotRequest")
+	proto.RegisterType((*DropSlotReply)(nil), "pb.DropSlotReply")
+	proto.RegisterType((*TreeRequest)(nil), "pb.TreeRequest")
+	proto.RegisterType((*TreeReply)(nil), "pb.TreeReply")
+	proto.RegisterType((*TreeKeysRequest)(nil), "pb.TreeKeysRequest")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion3
+
+// Client API for GokuServer service
+
+type GokuServerClient interface {
+	Insert(ctx context.Context, in *InsertRequest, opts ...grpc.CallOption) (*InsertReply, error)
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteReply, error)
+	Select(ctx context.Context, in *SelectRequest, opts ...grpc.CallOption) (*SelectReply, error)
+	BatchInsert(ctx context.Context, in *BatchInsertRequest, opts ...grpc.CallOption) (*BatchInsertReply, error)
+	BatchDelete(ctx context.Context, in *BatchDeleteRequest, opts ...grpc.CallOption) (*BatchDeleteReply, error)
+	MultiSelect(ctx context.Context, in *MultiSelectRequest, opts ...grpc.CallOption) (*MultiSelectReply, error)
+	Dump(ctx context.Context, in *DumpRequest, opts ...grpc.CallOption) (*DumpReply, error)
+	Purge(ctx context.Context, in *PurgeRequest, opts ...grpc.CallOption) (*PurgeReply, error)
+	Keys(ctx context.Context, in *KeysRequest, opts ...grpc.CallOption) (GokuServer_KeysClient, error)
+	ExportSlot(ctx context.Context, in *ExportSlotRequest, opts ...grpc.CallOption) (GokuServer_ExportSlotClient, error)
+	ImportSlot(ctx context.Context, in *ImportSlotRequest, opts ...grpc.CallOption) (*ImportSlotReply, error)
+	DropSlot(ctx context.Context, in *DropSlotRequest, opts ...grpc.CallOption) (*DropSlotReply, error)
+	Tree(ctx context.Context, in *TreeRequest, opts ...grpc.CallOption) (*TreeReply, error)
+	TreeKeys(ctx context.Context, in *TreeKeysRequest, opts ...grpc.CallOption) (GokuServer_TreeKeysClient, error)
+}
+
+type gokuServerClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewGokuServerClient(cc *grpc.ClientConn) GokuServerClient {
+	return &gokuServerClient{cc}
+}
+
+func (c *gokuServerClient) Insert(ctx context.Context, in *InsertRequest, opts ...grpc.CallOption) (*InsertReply, error) {
+	out := new(InsertReply)
+	err := grpc.Invoke(ctx, "/pb.GokuServer/Insert", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gokuServerClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteReply, error) {
+	out := new(DeleteReply)
+	err := grpc.Invoke(ctx, "/pb.GokuServer/Delete", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gokuServerClient) Select(ctx context.Context, in *SelectRequest, opts ...grpc.CallOption) (*SelectReply, error) {
+	out := new(SelectReply)
+	err := grpc.Invoke(ctx, "/pb.GokuServer/Select", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gokuServerClient) BatchInsert(ctx context.Context, in *BatchInsertRequest, opts ...grpc.CallOption) (*BatchInsertReply, error) {
+	out := new(BatchInsertReply)
+	err := grpc.Invoke(ctx, "/pb.GokuServer/BatchInsert", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gokuServerClient) BatchDelete(ctx context.Context, in *BatchDeleteRequest, opts ...grpc.CallOption) (*BatchDeleteReply, error) {
+	out := new(BatchDeleteReply)
+	err := grpc.Invoke(ctx, "/pb.GokuServer/BatchDelete", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gokuServerClient) MultiSelect(ctx context.Context, in *MultiSelectRequest, opts ...grpc.CallOption) (*MultiSelectReply, error) {
+	out := new(MultiSelectReply)
+	err := grpc.Invoke(ctx, "/pb.GokuServer/MultiSelect", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gokuServerClient) Dump(ctx context.Context, in *DumpRequest, opts ...grpc.CallOption) (*DumpReply, error) {
+	out := new(DumpReply)
+	err := grpc.Invoke(ctx, "/pb.GokuServer/Dump", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gokuServerClient) Purge(ctx context.Context, in *PurgeRequest, opts ...grpc.CallOption) (*PurgeReply, error) {
+	out := new(PurgeReply)
+	err := grpc.Invoke(ctx, "/pb.GokuServer/Purge", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gokuServerClient) Keys(ctx context.Context, in *KeysRequest, opts ...grpc.CallOption) (GokuServer_KeysClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_GokuServer_serviceDesc.Streams[0], c.cc, "/pb.GokuServer/Keys", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &gokuServerKeysClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type GokuServer_KeysClient interface {
+	Recv() (*KeysReply, error)
+	grpc.ClientStream
+}
+
+type gokuServerKeysClient struct {
+	grpc.ClientStream
+}
+
+func (x *gokuServerKeysClient) Recv() (*KeysReply, error) {
+	m := new(KeysReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *gokuServerClient) ExportSlot(ctx context.Context, in *ExportSlotRequest, opts ...grpc.CallOption) (GokuServer_ExportSlotClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_GokuServer_serviceDesc.Streams[1], c.cc, "/pb.GokuServer/ExportSlot", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &gokuServerExportSlotClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type GokuServer_ExportSlotClient interface {
+	Recv() (*ExportSlotReply, error)
+	grpc.ClientStream
+}
+
+type gokuServerExportSlotClient struct {
+	grpc.ClientStream
+}
+
+func (x *gokuServerExportSlotClient) Recv() (*ExportSlotReply, error) {
+	m := new(ExportSlotReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *gokuServerClient) ImportSlot(ctx context.Context, in *ImportSlotRequest, opts ...grpc.CallOption) (*ImportSlotReply, error) {
+	out := new(ImportSlotReply)
+	err := grpc.Invoke(ctx, "/pb.GokuServer/ImportSlot", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gokuServerClient) DropSlot(ctx context.Context, in *DropSlotRequest, opts ...grpc.CallOption) (*DropSlotReply, error) {
+	out := new(DropSlotReply)
+	err := grpc.Invoke(ctx, "/pb.GokuServer/DropSlot", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gokuServerClient) Tree(ctx context.Context, in *TreeRequest, opts ...grpc.CallOption) (*TreeReply, error) {
+	out := new(TreeReply)
+	err := grpc.Invoke(ctx, "/pb.GokuServer/Tree", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gokuServerClient) TreeKeys(ctx context.Context, in *TreeKeysRequest, opts ...grpc.CallOption) (GokuServer_TreeKeysClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_GokuServer_serviceDesc.Streams[2], c.cc, "/pb.GokuServer/TreeKeys", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &gokuServerTreeKeysClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type GokuServer_TreeKeysClient interface {
+	Recv() (*KeysReply, error)
+	grpc.ClientStream
+}
+
+type gokuServerTreeKeysClient struct {
+	grpc.ClientStream
+}
+
+func (x *gokuServerTreeKeysClient) Recv() (*KeysReply, error) {
+	m := new(KeysReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for GokuServer service
+
+type GokuServerServer interface {
+	Insert(context.Context, *InsertRequest) (*InsertReply, error)
+	Delete(context.Context, *DeleteRequest) (*DeleteReply, error)
+	Select(context.Context, *SelectRequest) (*SelectReply, error)
+	BatchInsert(context.Context, *BatchInsertRequest) (*BatchInsertReply, error)
+	BatchDelete(context.Context, *BatchDeleteRequest) (*BatchDeleteReply, error)
+	MultiSelect(context.Context, *MultiSelectRequest) (*MultiSelectReply, error)
+	Dump(context.Context, *DumpRequest) (*DumpReply, error)
+	Purge(context.Context, *PurgeRequest) (*PurgeReply, error)
+	Keys(*KeysRequest, GokuServer_KeysServer) error
+	ExportSlot(*ExportSlotRequest, GokuServer_ExportSlotServer) error
+	ImportSlot(context.Context, *ImportSlotRequest) (*ImportSlotReply, error)
+	DropSlot(context.Context, *DropSlotRequest) (*DropSlotReply, error)
+	Tree(context.Context, *TreeRequest) (*TreeReply, error)
+	TreeKeys(*TreeKeysRequest, GokuServer_TreeKeysServer) error
+}
+
+func RegisterGokuServerServer(s *grpc.Server, srv GokuServerServer) {
+	s.RegisterService(&_GokuServer_serviceDesc, srv)
+}
+
+func _GokuServer_Insert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InsertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GokuServerServer).Insert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GokuServer/Insert",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GokuServerServer).Insert(ctx, req.(*InsertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GokuServer_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GokuServerServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GokuServer/Delete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GokuServerServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GokuServer_Select_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SelectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GokuServerServer).Select(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GokuServer/Select",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GokuServerServer).Select(ctx, req.(*SelectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GokuServer_BatchInsert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchInsertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GokuServerServer).BatchInsert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GokuServer/BatchInsert",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GokuServerServer).BatchInsert(ctx, req.(*BatchInsertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GokuServer_BatchDelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchDeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GokuServerServer).BatchDelete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GokuServer/BatchDelete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GokuServerServer).BatchDelete(ctx, req.(*BatchDeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GokuServer_MultiSelect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MultiSelectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GokuServerServer).MultiSelect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GokuServer/MultiSelect",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GokuServerServer).MultiSelect(ctx, req.(*MultiSelectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GokuServer_Dump_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DumpRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GokuServerServer).Dump(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GokuServer/Dump",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GokuServerServer).Dump(ctx, req.(*DumpRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GokuServer_Purge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GokuServerServer).Purge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GokuServer/Purge",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GokuServerServer).Purge(ctx, req.(*PurgeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GokuServer_Keys_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(KeysRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GokuServerServer).Keys(m, &gokuServerKeysServer{stream})
+}
+
+type GokuServer_KeysServer interface {
+	Send(*KeysReply) error
+	grpc.ServerStream
+}
+
+type gokuServerKeysServer struct {
+	grpc.ServerStream
+}
+
+func (x *gokuServerKeysServer) Send(m *KeysReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _GokuServer_ExportSlot_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportSlotRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GokuServerServer).ExportSlot(m, &gokuServerExportSlotServer{stream})
+}
+
+type GokuServer_ExportSlotServer interface {
+	Send(*ExportSlotReply) error
+	grpc.ServerStream
+}
+
+type gokuServerExportSlotServer struct {
+	grpc.ServerStream
+}
+
+func (x *gokuServerExportSlotServer) Send(m *ExportSlotReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _GokuServer_ImportSlot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportSlotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GokuServerServer).ImportSlot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GokuServer/ImportSlot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GokuServerServer).ImportSlot(ctx, req.(*ImportSlotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GokuServer_DropSlot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DropSlotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GokuServerServer).DropSlot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GokuServer/DropSlot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GokuServerServer).DropSlot(ctx, req.(*DropSlotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GokuServer_Tree_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TreeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GokuServerServer).Tree(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.GokuServer/Tree",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GokuServerServer).Tree(ctx, req.(*TreeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GokuServer_TreeKeys_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TreeKeysRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GokuServerServer).TreeKeys(m, &gokuServerTreeKeysServer{stream})
+}
+
+type GokuServer_TreeKeysServer interface {
+	Send(*KeysReply) error
+	grpc.ServerStream
+}
+
+type gokuServerTreeKeysServer struct {
+	grpc.ServerStream
+}
+
+func (x *gokuServerTreeKeysServer) Send(m *KeysReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _GokuServer_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.GokuServer",
+	HandlerType: (*GokuServerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Insert",
+			Handler:    _GokuServer_Insert_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _GokuServer_Delete_Handler,
+		},
+		{
+			MethodName: "Select",
+			Handler:    _GokuServer_Select_Handler,
+		},
+		{
+			MethodName: "BatchInsert",
+			Handler:    _GokuServer_BatchInsert_Handler,
+		},
+		{
+			MethodName: "BatchDelete",
+			Handler:    _GokuServer_BatchDelete_Handler,
+		},
+		{
+			MethodName: "MultiSelect",
+			Handler:    _GokuServer_MultiSelect_Handler,
+		},
+		{
+			MethodName: "Dump",
+			Handler:    _GokuServer_Dump_Handler,
+		},
+		{
+			MethodName: "Purge",
+			Handler:    _GokuServer_Purge_Handler,
+		},
+		{
+			MethodName: "ImportSlot",
+			Handler:    _GokuServer_ImportSlot_Handler,
+		},
+		{
+			MethodName: "DropSlot",
+			Handler:    _GokuServer_DropSlot_Handler,
+		},
+		{
+			MethodName: "Tree",
+			Handler:    _GokuServer_Tree_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Keys",
+			Handler:       _GokuServer_Keys_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ExportSlot",
+			Handler:       _GokuServer_ExportSlot_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "TreeKeys",
+			Handler:       _GokuServer_TreeKeys_Handler,
+			ServerStreams: true,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "gokuserver.proto",
 }
 
 func init() { proto.RegisterFile("gokuserver.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 381 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x93, 0xc1, 0x4e, 0xc2, 0x30,
-	0x1c, 0xc6, 0x19, 0x63, 0x03, 0xfe, 0x93, 0x88, 0x4d, 0xd4, 0xc5, 0x8b, 0xb8, 0x8b, 0x9c, 0x76,
-	0xc0, 0xf8, 0x02, 0x46, 0xa2, 0x5c, 0x38, 0x8c, 0x83, 0x89, 0xc6, 0x10, 0xc6, 0xfe, 0x21, 0x84,
-	0x8e, 0xd6, 0xb6, 0x33, 0xe1, 0x1d, 0x7c, 0x04, 0x1f, 0xd6, 0xb4, 0xdd, 0x08, 0xe8, 0x81, 0xc4,
-	0x44, 0x6f, 0xfd, 0xfe, 0xfd, 0xd2, 0xfd, 0xbe, 0xaf, 0x1d, 0x74, 0x17, 0x6c, 0x55, 0x48, 0x14,
-	0xef, 0x28, 0x62, 0x2e, 0x98, 0x62, 0xa4, 0xce, 0xd3, 0xe8, 0x16, 0xbc, 0xa1, 0x10, 0x4c, 0x10,
-	0x02, 0x8d, 0x39, 0xcb, 0x30, 0x74, 0x7a, 0x4e, 0xdf, 0x4d, 0xcc, 0x9a, 0x84, 0xd0, 0xcc, 0x51,
-	0xca, 0xd9, 0x02, 0xc3, 0x7a, 0xcf, 0xe9, 0xb7, 0x93, 0x4a, 0x46, 0x2f, 0xd0, 0x1c, 0x52, 0xcc,
-	0x71, 0xad, 0xc8, 0x19, 0xf8, 0x39, 0xe6, 0x29, 0x8a, 0xd0, 0x35, 0x9e, 0x52, 0x91, 0x2b, 0x38,
-	0x52, 0xcb, 0x1c, 0xa5, 0x9a, 0xe5, 0x7c, 0xba, 0x96, 0x61, 0xc3, 0x1c, 0x1c, 0x6c, 0x67, 0x63,
-	0x49, 0x4e, 0xc1, 0x57, 0x8a, 0xea, 0x4d, 0xcf, 0x6c, 0x7a, 0x4a, 0xd1, 0xb1, 0x8c, 0x3e, 0x1c,
-	0xe8, 0x8c, 0xd6, 0x12, 0x85, 0x4a, 0xf0, 0xad, 0x40, 0xa9, 0xc8, 0x39, 0x34, 0x25, 0x65, 0x6a,
-	0xba, 0xcc, 0x4a, 0x3e, 0x5f, 0xcb, 0x51, 0x46, 0xba, 0xe0, 0xae, 0x70, 0x53, 0xd2, 0xe9, 0xe5,
-	0x1f, 0xe0, 0x3c, 0x42, 0x50, 0xd1, 0x70, 0xba, 0xd1, 0xa5, 0x14, 0x3c, 0x9b, 0x29, 0xb4, 0x2c,
-	0xad, 0xa4, 0x92, 0xe4, 0x12, 0x3c, 0xd4, 0x5d, 0x1a, 0x9c, 0x60, 0xd0, 0x8e, 0x79, 0x1a, 0x9b,
-	0x72, 0x13, 0x3b, 0x8f, 0x0a, 0xe8, 0xdc, 0x23, 0x45, 0x85, 0xff, 0x9a, 0x4b, 0x07, 0xa8, 0x3e,
-	0x5b, 0x06, 0xc8, 0x8c, 0xdc, 0x06, 0x28, 0xe5, 0xe1, 0x00, 0xaf, 0xd0, 0x99, 0x20, 0xc5, 0xf9,
-	0x6f, 0x2e, 0xe6, 0x3b, 0xa8, 0xfb, 0x13, 0xf4, 0x09, 0x82, 0xea, 0x78, 0x0d, 0x7a, 0x0d, 0x2d,
-	0xb4, 0x8f, 0x4c, 0x86, 0x4e, 0xcf, 0xed, 0x07, 0x83, 0xc0, 0x10, 0xd9, 0x59, 0xb2, 0xdd, 0x3c,
-	0xc8, 0x3d, 0xf8, 0x74, 0x00, 0x1e, 0xd8, 0xaa, 0x98, 0x98, 0xe7, 0x4f, 0x62, 0xf0, 0xed, 0x8d,
-	0x92, 0x13, 0x6d, 0xdd, 0x7b, 0x6b, 0x17, 0xc7, 0xbb, 0x23, 0x4e, 0x37, 0x51, 0x4d, 0xfb, 0x6d,
-	0x81, 0xd6, 0xbf, 0x77, 0x87, 0xd6, 0xbf, 0xd3, 0xaf, 0xf5, 0xdb, 0x1c, 0xd6, 0xbf, 0x57, 0x99,
-	0xf5, 0xef, 0xc4, 0x8c, 0x6a, 0x77, 0x8d, 0xe7, 0x3a, 0x4f, 0x53, 0xdf, 0xfc, 0x95, 0x37, 0x5f,
-	0x01, 0x00, 0x00, 0xff, 0xff, 0x37, 0xb0, 0xf2, 0x3f, 0xa9, 0x03, 0x00, 0x00,
+	// 932 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x57, 0x6d, 0x6f, 0xe3, 0x44,
+	0x10, 0xae, 0xeb, 0xd8, 0x49, 0xc6, 0x0d, 0x69, 0x97, 0x5e, 0xb1, 0x2c, 0x21, 0x82, 0xd1, 0xe9,
+	0xa2, 0x43, 0xaa, 0x4e, 0x05, 0x3e, 0x80, 0xee, 0xa4, 0xd3, 0xa9, 0x11, 0x44, 0x88, 0x13, 0x72,
+	0x41, 0x48, 0xbc, 0xa8, 0xca, 0xcb, 0xd2, 0x5a, 0xb5, 0x63, 0xb3, 0xbb, 0x3e, 0x91, 0xe3, 0x2f,
+	0xf0, 0x4b, 0xf9, 0x15, 0x68, 0x77, 0xbd, 0xf6, 0xae, 0x9d, 0xd6, 0x47, 0x75, 0xdc, 0x37, 0xcf,
+	0xec, 0xbc, 0x3c, 0xcf, 0xec, 0xcc, 0x4e, 0x02, 0x87, 0x57, 0xd9, 0x4d, 0x41, 0x31, 0x79, 0x85,
+	0xc9, 0x69, 0x4e, 0x32, 0x96, 0xa1, 0xfd, 0x7c, 0x19, 0x7e, 0x01, 0xce, 0x8c, 0x90, 0x8c, 0x20,
+	0x04, 0xbd, 0x55, 0xb6, 0xc6, 0xbe, 0x35, 0xb1, 0xa6, 0x76, 0x24, 0xbe, 0x91, 0x0f, 0xfd, 0x14,
+	0x53, 0xba, 0xb8, 0xc2, 0xfe, 0xfe, 0xc4, 0x9a, 0x0e, 0x23, 0x25, 0x86, 0x05, 0xf4, 0x67, 0x09,
+	0x4e, 0xf1, 0x86, 0xa1, 0x13, 0x70, 0x53, 0x9c, 0x2e, 0x31, 0xf1, 0x6d, 0x61, 0x53, 0x4a, 0xe8,
+	0x63, 0x38, 0x60, 0x71, 0x8a, 0x29, 0x5b, 0xa4, 0xf9, 0xe5, 0x86, 0xfa, 0x3d, 0x11, 0xd8, 0xab,
+	0x74, 0x2f, 0x29, 0x7a, 0x00, 0x2e, 0x63, 0x09, 0x3f, 0x74, 0xc4, 0xa1, 0xc3, 0x58, 0xf2, 0x92,
+	0xf2, 0xb4, 0x6b, 0x9c, 0x60, 0x86, 0xd7, 0xbe, 0x3b, 0xb1, 0xa6, 0x83, 0x48, 0x89, 0xe1, 0xdf,
+	0x16, 0x8c, 0xe6, 0x1b, 0x8a, 0x09, 0x8b, 0xf0, 0x1f, 0x05, 0xa6, 0x0c, 0x7d, 0x00, 0x7d, 0x9a,
+	0x64, 0xec, 0x32, 0x5e, 0x97, 0xc8, 0x5d, 0x2e, 0xce, 0xd7, 0xe8, 0x10, 0xec, 0x1b, 0xbc, 0x2d,
+	0x71, 0xf3, 0xcf, 0xb7, 0x0f, 0x34, 0xfc, 0x06, 0x3c, 0x85, 0x26, 0x4f, 0xb6, 0x1c, 0x77, 0x91,
+	0xaf, 0x17, 0x0c, 0x4b, 0x2c, 0x83, 0x48, 0x89, 0xe8, 0x23, 0x70, 0x30, 0xaf, 0xb2, 0x80, 0xe3,
+	0x9d, 0x0d, 0x4f, 0xf3, 0xe5, 0xa9, 0x28, 0x7b, 0x24, 0xf5, 0x61, 0x01, 0xa3, 0x73, 0xc1, 0xf1,
+	0x9d, 0xf2, 0xe2, 0x04, 0x54, 0xda, 0x92, 0x80, 0x2a, 0xbc, 0x65, 0x14, 0xbe, 0x9b, 0xc0, 0x6f,
+	0x30, 0xba, 0xc0, 0x09, 0x5e, 0xdd, 0xe7, 0x62, 0x9a, 0x40, 0xed, 0x36, 0xd0, 0x9f, 0xc0, 0x53,
+	0xe1, 0x39, 0xd0, 0x47, 0x30, 0xc0, 0xb2, 0xfd, 0xa8, 0x6f, 0x4d, 0xec, 0xa9, 0x77, 0xe6, 0x09,
+	0x44, 0x52, 0x17, 0x55, 0x87, 0xdd, 0xb8, 0x9f, 0x01, 0x7a, 0xb1, 0x60, 0xab, 0x6b, 0xb3, 0xab,
+	0x1e, 0x81, 0x13, 0x33, 0x9c, 0xaa, 0xe0, 0x47, 0xdc, 0xcd, 0xb0, 0x88, 0xe4, 0x79, 0xf8, 0x25,
+	0x1c, 0x1a, 0xee, 0x1c, 0xdc, 0x43, 0xd3, 0x79, 0xac, 0x3b, 0xe7, 0xc9, 0x56, 0xb9, 0xaa, 0xcc,
+	0xe6, 0xbd, 0xef, 0xca, 0x6c, 0x58, 0x34, 0x33, 0xeb, 0xf7, 0xb7, 0x2b, 0xb3, 0x76, 0xae, 0x65,
+	0xfe, 0xae, 0x48, 0x58, 0x6c, 0x5e, 0xd8, 0xae, 0xcc, 0x86, 0x85, 0x96, 0xd9, 0x70, 0xbf, 0x2d,
+	0xb3, 0x76, 0xae, 0x5c, 0x7f, 0x01, 0xef, 0xbc, 0x48, 0xf3, 0xff, 0xa7, 0x47, 0x7e, 0x84, 0xa1,
+	0x0c, 0xfe, 0x76, 0x3b, 0x64, 0x09, 0x07, 0xdf, 0x17, 0xe4, 0xea, 0x3e, 0x93, 0xa9, 0x83, 0xb0,
+	0xef, 0x00, 0x11, 0xce, 0x00, 0xca, 0x1c, 0x1c, 0xfb, 0x09, 0xb8, 0x39, 0x97, 0xaa, 0x04, 0x52,
+	0xea, 0x86, 0x3a, 0x03, 0xef, 0x5b, 0xbc, 0xa5, 0x9d, 0x48, 0x3f, 0x04, 0x58, 0xf2, 0xde, 0xb9,
+	0xa4, 0xf1, 0x6b, 0xf9, 0xb4, 0xdb, 0xd1, 0x50, 0x68, 0x2e, 0xe2, 0xd7, 0x38, 0x7c, 0x0e, 0x43,
+	0x19, 0x86, 0x83, 0x41, 0xd0, 0xbb, 0xc1, 0x5b, 0x59, 0xc4, 0x61, 0x24, 0xbe, 0xbb, 0x81, 0x3c,
+	0x07, 0x67, 0xb6, 0x61, 0x64, 0xab, 0x6a, 0x62, 0xd5, 0x35, 0x79, 0x08, 0xfd, 0x92, 0x76, 0xe9,
+	0x6d, 0x94, 0x44, 0x9d, 0x85, 0x2b, 0x38, 0x9a, 0xfd, 0x99, 0x67, 0x84, 0x5d, 0x24, 0x59, 0xf7,
+	0x9b, 0x72, 0x02, 0xee, 0xaa, 0x20, 0xb4, 0x44, 0x74, 0x10, 0x95, 0x52, 0x83, 0xa8, 0xdd, 0x24,
+	0x9a, 0xc1, 0x58, 0x4f, 0xc2, 0xe9, 0x7e, 0x02, 0x7d, 0xbc, 0x61, 0x24, 0xc6, 0xaa, 0x6d, 0x24,
+	0x39, 0x4e, 0x26, 0x52, 0x27, 0xb7, 0xa6, 0xab, 0xea, 0x62, 0xdf, 0x52, 0x97, 0xbf, 0xe0, 0x68,
+	0x9e, 0xfe, 0x17, 0x56, 0x34, 0x2b, 0xc8, 0x4a, 0x6d, 0xdf, 0x52, 0xd2, 0xd2, 0xdb, 0x77, 0xb0,
+	0xed, 0x35, 0xd9, 0xfe, 0x0e, 0xe3, 0x79, 0x6a, 0xb2, 0x0d, 0x60, 0x10, 0x0b, 0x55, 0xd5, 0x6b,
+	0x95, 0x7c, 0x7f, 0x92, 0x8f, 0x61, 0x7c, 0x4e, 0xb2, 0xfc, 0x4d, 0x28, 0x86, 0x4f, 0x60, 0x54,
+	0xdb, 0x72, 0x44, 0x55, 0x74, 0xeb, 0x96, 0xe8, 0x4f, 0xc1, 0xfb, 0x81, 0xe0, 0xee, 0x69, 0x3c,
+	0x06, 0x67, 0x93, 0xad, 0x31, 0xf5, 0xf7, 0x27, 0x36, 0xdf, 0xd8, 0x42, 0x08, 0x7f, 0x85, 0xa1,
+	0xf4, 0x2e, 0xe7, 0xec, 0x7a, 0x41, 0xaf, 0xcb, 0xab, 0xee, 0x45, 0xa5, 0xc4, 0xf5, 0x09, 0x5e,
+	0xbc, 0x12, 0xbe, 0x22, 0xa4, 0x94, 0xba, 0x99, 0x2f, 0x60, 0xcc, 0xa3, 0xbf, 0xd1, 0x0c, 0xea,
+	0x49, 0x6c, 0x2d, 0xc9, 0xdd, 0x2d, 0x7b, 0xf6, 0x8f, 0x03, 0xf0, 0x75, 0x76, 0x53, 0x5c, 0x88,
+	0x1f, 0x72, 0xe8, 0x14, 0x5c, 0xb9, 0x5a, 0x50, 0x7b, 0x47, 0x05, 0xcd, 0xcd, 0x13, 0xee, 0x71,
+	0x7b, 0xb9, 0x10, 0x50, 0x7b, 0xb3, 0x04, 0xcd, 0x7d, 0x21, 0xed, 0xe5, 0x33, 0x8e, 0xda, 0xfb,
+	0x20, 0x68, 0xbe, 0xf2, 0xe1, 0x1e, 0x7a, 0x06, 0x9e, 0xb6, 0x0f, 0xd1, 0x09, 0xb7, 0x68, 0xef,
+	0xd7, 0xe0, 0xb8, 0xa5, 0x37, 0xdd, 0x4b, 0x8c, 0xb5, 0xbb, 0x09, 0xf4, 0xb8, 0xa5, 0xaf, 0xdc,
+	0xb5, 0xcd, 0x24, 0xdd, 0xdb, 0x9b, 0x2e, 0x38, 0x6e, 0xe9, 0xa5, 0xfb, 0x14, 0x7a, 0x7c, 0x81,
+	0x20, 0x59, 0x87, 0x7a, 0x4f, 0x05, 0xa3, 0x5a, 0x21, 0x2d, 0x3f, 0x05, 0x47, 0xbc, 0xd7, 0xe8,
+	0x90, 0x9f, 0xe8, 0xeb, 0x21, 0x78, 0x4f, 0xd3, 0x48, 0xe3, 0xc7, 0xd0, 0xe3, 0x1d, 0x21, 0xc3,
+	0x6a, 0xbd, 0x11, 0x8c, 0x6a, 0x85, 0xb0, 0x7c, 0x62, 0xa1, 0xa7, 0x00, 0xf5, 0x8b, 0x84, 0x1e,
+	0x88, 0x0e, 0x6b, 0x3e, 0x83, 0xc1, 0xfb, 0x4d, 0xb5, 0xf2, 0xfe, 0x0a, 0x60, 0x9e, 0x9a, 0xde,
+	0xf3, 0x74, 0xa7, 0x77, 0xe3, 0x21, 0x08, 0xf7, 0xd0, 0xe7, 0x30, 0x50, 0x93, 0x88, 0x84, 0x49,
+	0x63, 0x86, 0x83, 0x23, 0x53, 0x59, 0x95, 0x8c, 0x77, 0xbc, 0xe4, 0xa6, 0xcd, 0x65, 0x30, 0xaa,
+	0x15, 0xd2, 0xf2, 0x0c, 0x06, 0x6a, 0x36, 0x64, 0xfc, 0xc6, 0xa4, 0xec, 0xa8, 0xc6, 0x8b, 0xde,
+	0xcf, 0xfb, 0xf9, 0x72, 0xe9, 0x8a, 0x7f, 0x2b, 0x9f, 0xfd, 0x3b, 0x00, 0xc6, 0x88, 0x08, 0xc7,
+	0xc1, 0x0c, 0x00, 0x00,
 }
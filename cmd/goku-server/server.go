@@ -1,14 +1,21 @@
 package main
 
 import (
+	"errors"
+	"io"
 	"time"
 
 	"golang.org/x/net/context"
+	"google.golang.org/grpc"
 
 	"github.com/RussellLuo/goku/cmd/goku-server/pb"
+	"github.com/RussellLuo/goku/common"
 	"github.com/RussellLuo/goku/server"
 )
 
+// The default number of items in a batch of a streaming reply.
+const defaultBatchSize = 100
+
 type Server struct {
 	server *server.Server
 }
@@ -55,3 +62,249 @@ func (s *Server) Select(ctx context.Context, in *pb.SelectRequest) (*pb.SelectRe
 	}
 	return out, nil
 }
+
+func (s *Server) BatchInsert(ctx context.Context, in *pb.BatchInsertRequest) (*pb.BatchInsertReply, error) {
+	out := &pb.BatchInsertReply{Items: make([]*pb.InsertReply, len(in.Items))}
+	for i, item := range in.Items {
+		out.Items[i], _ = s.Insert(ctx, item)
+	}
+	return out, nil
+}
+
+func (s *Server) BatchDelete(ctx context.Context, in *pb.BatchDeleteRequest) (*pb.BatchDeleteReply, error) {
+	out := &pb.BatchDeleteReply{Items: make([]*pb.DeleteReply, len(in.Items))}
+	for i, item := range in.Items {
+		out.Items[i], _ = s.Delete(ctx, item)
+	}
+	return out, nil
+}
+
+func (s *Server) MultiSelect(ctx context.Context, in *pb.MultiSelectRequest) (*pb.MultiSelectReply, error) {
+	out := &pb.MultiSelectReply{Items: make([]*pb.SelectReply, len(in.Items))}
+	for i, item := range in.Items {
+		out.Items[i], _ = s.Select(ctx, item)
+	}
+	return out, nil
+}
+
+func (s *Server) Dump(ctx context.Context, in *pb.DumpRequest) (*pb.DumpReply, error) {
+	elements, err := s.server.Dump(int(in.SlotId), in.Key, in.TimestampNs)
+
+	out := &pb.DumpReply{}
+	if err != nil {
+		out.Error = &pb.Error{Message: err.Error()}
+	} else {
+		out.Elements = make([]*pb.Element, len(elements))
+		for i, e := range elements {
+			out.Elements[i] = toPBElement(e)
+		}
+	}
+	return out, nil
+}
+
+func (s *Server) Purge(ctx context.Context, in *pb.PurgeRequest) (*pb.PurgeReply, error) {
+	elements := make([]common.Element, len(in.Elements))
+	for i, e := range in.Elements {
+		elements[i] = fromPBElement(e)
+	}
+	purged, err := s.server.Purge(int(in.SlotId), in.Key, elements)
+
+	out := &pb.PurgeReply{Purged: int64(purged)}
+	if err != nil {
+		out.Error = &pb.Error{Message: err.Error()}
+	}
+	return out, nil
+}
+
+func (s *Server) Keys(in *pb.KeysRequest, stream pb.GokuServer_KeysServer) error {
+	batchSize := int(in.BatchSize)
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	cursor := ""
+	for {
+		keys, next, err := s.server.ScanKeys(int(in.SlotId), cursor, batchSize)
+		if err != nil {
+			return stream.Send(&pb.KeysReply{Error: &pb.Error{Message: err.Error()}})
+		}
+		if len(keys) > 0 {
+			if err := stream.Send(&pb.KeysReply{Keys: keys}); err != nil {
+				return err
+			}
+		}
+		if next == "" {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// ExportSlot streams the entries in the slot in batches, starting from the
+// given cursor. Each batch carries the cursor to resume from after it, and
+// the last batch carries an empty cursor. Since a batch is only exported
+// after the previous one has been sent, a slow receiver slows down the
+// export through the flow control of the stream.
+func (s *Server) ExportSlot(in *pb.ExportSlotRequest, stream pb.GokuServer_ExportSlotServer) error {
+	batchSize := int(in.BatchSize)
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	cursor := string(in.Cursor)
+	for {
+		entries, next, err := s.server.ExportSlot(int(in.SlotId), cursor, batchSize, time.Now().UnixNano())
+		if err != nil {
+			return stream.Send(&pb.ExportSlotReply{Error: &pb.Error{Message: err.Error()}})
+		}
+
+		out := &pb.ExportSlotReply{
+			Entries: make([]*pb.Entry, len(entries)),
+			Cursor:  []byte(next),
+		}
+		for i, e := range entries {
+			out.Entries[i] = &pb.Entry{Key: e.Key, Element: toPBElement(e.Element)}
+		}
+		if err := stream.Send(out); err != nil {
+			return err
+		}
+
+		if next == "" {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// ImportSlot pulls the entries in the slot from the source server, starting
+// from the given cursor, and applies them locally. If the import fails, the
+// reply carries the cursor to resume from, which is after the last batch
+// that has been applied.
+func (s *Server) ImportSlot(ctx context.Context, in *pb.ImportSlotRequest) (*pb.ImportSlotReply, error) {
+	out := &pb.ImportSlotReply{Cursor: in.Cursor}
+	if err := s.importSlot(ctx, in, out); err != nil {
+		out.Error = &pb.Error{Message: err.Error()}
+	}
+	return out, nil
+}
+
+func (s *Server) importSlot(ctx context.Context, in *pb.ImportSlotRequest, out *pb.ImportSlotReply) error {
+	conn, err := grpc.Dial(in.Source, grpc.WithInsecure())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	stream, err := pb.NewGokuServerClient(conn).ExportSlot(ctx, &pb.ExportSlotRequest{
+		SlotId:    in.SlotId,
+		Cursor:    in.Cursor,
+		BatchSize: in.BatchSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		reply, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err == nil && reply.Error != nil {
+			err = errors.New(reply.Error.Message)
+		}
+		if err != nil {
+			return err
+		}
+
+		entries := make([]server.Entry, len(reply.Entries))
+		for i, e := range reply.Entries {
+			entries[i] = server.Entry{Key: e.Key, Element: fromPBElement(e.Element)}
+		}
+		if err := s.server.ImportSlot(int(in.SlotId), entries); err != nil {
+			return err
+		}
+
+		out.Imported += int64(len(entries))
+		out.Cursor = reply.Cursor
+	}
+}
+
+func (s *Server) DropSlot(ctx context.Context, in *pb.DropSlotRequest) (*pb.DropSlotReply, error) {
+	out := &pb.DropSlotReply{}
+	if err := s.server.DropSlot(int(in.SlotId)); err != nil {
+		out.Error = &pb.Error{Message: err.Error()}
+	}
+	return out, nil
+}
+
+func (s *Server) Tree(ctx context.Context, in *pb.TreeRequest) (*pb.TreeReply, error) {
+	nodes := make([]int, len(in.Nodes))
+	for i, n := range in.Nodes {
+		nodes[i] = int(n)
+	}
+	return &pb.TreeReply{
+		Hashes: s.server.Tree(int(in.SlotId), nodes),
+		Leaves: server.TreeLeaves,
+	}, nil
+}
+
+// TreeKeys streams the keys of the sets belonging to the given leaves of the
+// hash tree of the slot in batches.
+func (s *Server) TreeKeys(in *pb.TreeKeysRequest, stream pb.GokuServer_TreeKeysServer) error {
+	batchSize := int(in.BatchSize)
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	leaves := make(map[int]bool, len(in.Leaves))
+	for _, l := range in.Leaves {
+		leaves[int(l)] = true
+	}
+
+	var (
+		batch  []string
+		cursor string
+	)
+	for {
+		keys, next, err := s.server.ScanKeys(int(in.SlotId), cursor, batchSize)
+		if err != nil {
+			return stream.Send(&pb.KeysReply{Error: &pb.Error{Message: err.Error()}})
+		}
+		for _, key := range keys {
+			if leaves[server.TreeLeaf(key)] {
+				batch = append(batch, key)
+			}
+		}
+		if len(batch) >= batchSize || (next == "" && len(batch) > 0) {
+			if err := stream.Send(&pb.KeysReply{Keys: batch}); err != nil {
+				return err
+			}
+			batch = nil
+		}
+		if next == "" {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+func toPBElement(e common.Element) *pb.Element {
+	return &pb.Element{
+		Member:      e.Member,
+		TimestampNs: e.Timestamp,
+		TtlNs:       int64(e.TTL),
+		Deleted:     e.Deleted,
+	}
+}
+
+func fromPBElement(e *pb.Element) common.Element {
+	if e == nil {
+		return common.Element{}
+	}
+	return common.Element{
+		Member:    e.Member,
+		Timestamp: e.TimestampNs,
+		TTL:       time.Duration(e.TtlNs),
+		Deleted:   e.Deleted,
+	}
+}
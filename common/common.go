@@ -1,6 +1,7 @@
 package common
 
 import (
+	"context"
 	"time"
 )
 
@@ -13,20 +14,41 @@ type Element struct {
 	//     zero: means persistent
 	//     negative value: invalid
 	TTL time.Duration
+	// Whether this member has been deleted, which is only reported by
+	// Dumper to carry the tombstones.
+	Deleted bool
 }
 
 type Inserter interface {
-	Insert(slotID int, key, member string, timestamp int64, ttl time.Duration) (bool, error)
+	Insert(ctx context.Context, slotID int, key, member string, timestamp int64, ttl time.Duration) (bool, error)
 }
 
 type Deleter interface {
-	Delete(slotID int, key, member string, timestamp int64) (bool, error)
+	Delete(ctx context.Context, slotID int, key, member string, timestamp int64) (bool, error)
 }
 
 type Selector interface {
-	Select(slotID int, key string, timestamp int64) ([]Element, error)
+	Select(ctx context.Context, slotID int, key string, timestamp int64) ([]Element, error)
 }
 
+// Scanner sends the keys in the slot identified by slotID in batches. The
+// channel is closed once the scan is done, fails, or ctx is done, and the
+// consumer that stops reading early must cancel ctx. After the channel is
+// closed, the returned function reports why the scan ended, which is nil
+// only if all the keys have been sent.
 type Scanner interface {
-	Keys(slotID, batchSize int) <-chan []string
+	Keys(ctx context.Context, slotID, batchSize int) (<-chan []string, func() error)
+}
+
+// Dumper returns all the elements of the set at key, including the
+// tombstones, which is used to copy the set elsewhere.
+type Dumper interface {
+	Dump(ctx context.Context, slotID int, key string, timestamp int64) ([]Element, error)
+}
+
+// Purger physically removes the given elements of the set at key, without
+// leaving any tombstone. An element is only removed if it has not been
+// changed since it was dumped, i.e. its timestamp is not newer.
+type Purger interface {
+	Purge(ctx context.Context, slotID int, key string, elements []Element) (int, error)
 }
@@ -0,0 +1,217 @@
+package group
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/RussellLuo/goku/cluster"
+	"github.com/RussellLuo/goku/internal/worker"
+)
+
+// The number of keys fetched in a batch by the anti-entropy.
+const syncBatchSize = 100
+
+// SyncSlot makes the replicas of the slot identified by slotID converge.
+//
+// The hash trees of the slot are compared among the servers level by level,
+// by only descending into the nodes that differ, until the differing leaves
+// are found. Then only the sets in those leaves are merged from all the
+// servers, and the merged elements are pushed to the stale servers in the
+// same way as the read repair.
+//
+// The servers that fail are skipped. SyncSlot returns the number of the
+// sets that have been repaired.
+func (g *group) SyncSlot(slotID int) (int, error) {
+	servers, hashes, leaves, err := g.trees(g.servers, slotID, []int{1})
+	if err != nil {
+		return 0, err
+	}
+
+	nodes := []int{1}
+	for len(nodes) > 0 && nodes[0] < leaves {
+		// Descend into the children of the differing nodes.
+		var children []int
+		for i, n := range nodes {
+			if differ(hashes, i) {
+				children = append(children, 2*n, 2*n+1)
+			}
+		}
+		if len(children) == 0 {
+			return 0, nil
+		}
+		nodes = children
+		if servers, hashes, _, err = g.trees(servers, slotID, nodes); err != nil {
+			return 0, err
+		}
+	}
+
+	var diff []int
+	for i, n := range nodes {
+		if differ(hashes, i) {
+			diff = append(diff, n)
+		}
+	}
+	if len(diff) == 0 {
+		return 0, nil
+	}
+
+	// Collect the keys in the differing leaves from all the servers. The
+	// keys missed by a failed scan would be left diverged, so the sync is
+	// aborted rather than reported as done.
+	keys := make(map[string]bool)
+	for _, s := range servers {
+		c, errFn := s.TreeKeys(slotID, diff, syncBatchSize)
+		for batch := range c {
+			for _, key := range batch {
+				keys[key] = true
+			}
+		}
+		if err := errFn(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := 0
+	for key := range keys {
+		repaired, err := g.syncKey(servers, slotID, key)
+		if err != nil {
+			return n, fmt.Errorf("failed to sync key %q: %s", key, err)
+		}
+		if repaired {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// trees fetches the hashes of the given nodes from the servers, and only
+// returns the servers that succeed, along with their hashes. There must be
+// at least two such servers to compare.
+func (g *group) trees(servers []Server, slotID int, nodes []int) ([]Server, [][]uint64, int, error) {
+	type result struct {
+		server Server
+		hashes []uint64
+		leaves int
+		err    error
+	}
+	// Scatter
+	resultChan := make(chan result, len(servers))
+	for _, s := range servers {
+		go func(s Server) {
+			hashes, leaves, err := s.Tree(slotID, nodes)
+			resultChan <- result{server: s, hashes: hashes, leaves: leaves, err: err}
+		}(s)
+	}
+
+	// Gather
+	var (
+		ok     []Server
+		hashes [][]uint64
+		leaves int
+		errs   []string
+	)
+	for i := 0; i < cap(resultChan); i++ {
+		result := <-resultChan
+		if result.err == nil && len(result.hashes) != len(nodes) {
+			result.err = fmt.Errorf("%s: got %d hashes for %d nodes", result.server.Addr(), len(result.hashes), len(nodes))
+		}
+		if result.err == nil && leaves != 0 && result.leaves != leaves {
+			result.err = fmt.Errorf("%s: got %d leaves while others have %d", result.server.Addr(), result.leaves, leaves)
+		}
+		if result.err != nil {
+			errs = append(errs, result.err.Error())
+			continue
+		}
+		ok = append(ok, result.server)
+		hashes = append(hashes, result.hashes)
+		leaves = result.leaves
+	}
+
+	// Report
+	if len(ok) < 2 {
+		return nil, nil, 0, fmt.Errorf("not enough servers (%s)", strings.Join(errs, "; "))
+	}
+	return ok, hashes, leaves, nil
+}
+
+// differ reports whether the servers have different hashes of the i-th node.
+func differ(hashes [][]uint64, i int) bool {
+	for _, h := range hashes[1:] {
+		if h[i] != hashes[0][i] {
+			return true
+		}
+	}
+	return false
+}
+
+// syncKey merges the set at key from the servers, and pushes the merged
+// elements to the stale ones. It reports whether any element is pushed.
+func (g *group) syncKey(servers []Server, slotID int, key string) (bool, error) {
+	ctx := context.Background()
+	now := time.Now().UnixNano()
+	replicas := make([]replica, 0, len(servers))
+	for _, s := range servers {
+		elements, err := s.Dump(ctx, slotID, key, now)
+		if err != nil {
+			// Skip the failed server.
+			continue
+		}
+		replicas = append(replicas, replica{server: s, elements: elements})
+	}
+	if len(replicas) < 2 {
+		return false, fmt.Errorf("not enough servers")
+	}
+
+	return g.repair(ctx, slotID, key, replicas, merge(replicas)) > 0, nil
+}
+
+// AntiEntropy is a background worker that periodically makes the replicas
+// of all the online slots in a cluster converge. It complements the read
+// repair by also healing the sets that are never read.
+type AntiEntropy struct {
+	*worker.Ticker
+
+	cluster *cluster.Cluster
+	synced  uint64 // The total number of repaired sets (atomic)
+}
+
+// NewAntiEntropy creates an AntiEntropy, which synchronizes the slots in c
+// every interval.
+func NewAntiEntropy(c *cluster.Cluster, interval time.Duration) *AntiEntropy {
+	a := &AntiEntropy{cluster: c}
+	a.Ticker = worker.NewTicker(interval, func() { a.Sync() })
+	return a
+}
+
+// Synced returns the total number of the sets that have been repaired.
+func (a *AntiEntropy) Synced() uint64 {
+	return atomic.LoadUint64(&a.synced)
+}
+
+// Sync synchronizes all the online slots once. It returns the number of the
+// sets that have been repaired.
+func (a *AntiEntropy) Sync() int {
+	total := 0
+	for id, slot := range a.cluster.Slots() {
+		// The slots in migration are left to the migration.
+		if slot.State() != cluster.SlotStateOnline {
+			continue
+		}
+		g, ok := slot.Group().(*group)
+		if !ok || len(g.servers) < 2 {
+			continue
+		}
+
+		n, err := g.SyncSlot(id)
+		if err != nil {
+			log.Printf("failed to sync slot %d in group %d: %v", id, g.id, err)
+		}
+		total += n
+	}
+	atomic.AddUint64(&a.synced, uint64(total))
+	return total
+}
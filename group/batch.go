@@ -0,0 +1,301 @@
+package group
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/RussellLuo/goku/common"
+)
+
+// Op is an operation on the set at key in a batch. Member and TTL are only
+// used by the operations that need them.
+type Op struct {
+	SlotID    int
+	Key       string
+	Member    string
+	Timestamp int64
+	TTL       time.Duration
+}
+
+// OpResult is the outcome of a write in a batch on a server.
+type OpResult struct {
+	Status bool
+	Err    error
+}
+
+// SelectResult is the outcome of a select in a batch.
+type SelectResult struct {
+	Elements []common.Element
+	Err      error
+}
+
+// Batcher is the server that applies the operations in batches. The results
+// are in the same order as the operations, and the error is only returned
+// if the whole batch fails.
+type Batcher interface {
+	BatchInsert(ctx context.Context, ops []Op) ([]OpResult, error)
+	BatchDelete(ctx context.Context, ops []Op) ([]OpResult, error)
+	MultiSelect(ctx context.Context, ops []Op) ([]SelectResult, error)
+}
+
+// batchInsert applies ops to s in a batch if s is a Batcher, or one by one
+// otherwise.
+func batchInsert(ctx context.Context, s Server, ops []Op) ([]OpResult, error) {
+	if b, ok := s.(Batcher); ok {
+		return b.BatchInsert(ctx, ops)
+	}
+	results := make([]OpResult, len(ops))
+	for i, op := range ops {
+		results[i].Status, results[i].Err = s.Insert(ctx, op.SlotID, op.Key, op.Member, op.Timestamp, op.TTL)
+	}
+	return results, nil
+}
+
+// batchDelete applies ops to s in a batch if s is a Batcher, or one by one
+// otherwise.
+func batchDelete(ctx context.Context, s Server, ops []Op) ([]OpResult, error) {
+	if b, ok := s.(Batcher); ok {
+		return b.BatchDelete(ctx, ops)
+	}
+	results := make([]OpResult, len(ops))
+	for i, op := range ops {
+		results[i].Status, results[i].Err = s.Delete(ctx, op.SlotID, op.Key, op.Member, op.Timestamp)
+	}
+	return results, nil
+}
+
+// multiSelect applies ops to s in a batch if s is a Batcher, or one by one
+// otherwise.
+func multiSelect(ctx context.Context, s Server, ops []Op) ([]SelectResult, error) {
+	if b, ok := s.(Batcher); ok {
+		return b.MultiSelect(ctx, ops)
+	}
+	results := make([]SelectResult, len(ops))
+	for i, op := range ops {
+		results[i].Elements, results[i].Err = s.Select(ctx, op.SlotID, op.Key, op.Timestamp)
+	}
+	return results, nil
+}
+
+// ItemResult is the result of an item in a batch write to a group, which is
+// the same as that of a single write.
+type ItemResult struct {
+	Result *WriteResult
+	Err    error
+}
+
+// BatchInsert is like InsertWithResult, but inserts all the members in ops
+// with one request per server. The results are in the same order as ops.
+func (g *group) BatchInsert(ctx context.Context, ops []Op) []ItemResult {
+	hints := make([]Hint, len(ops))
+	for i, op := range ops {
+		hints[i] = Hint{Op: hintInsert, SlotID: op.SlotID, Key: op.Key, Member: op.Member, Timestamp: op.Timestamp, TTL: op.TTL}
+	}
+	return g.writeBatch(ctx, hints, func(ctx context.Context, s Server) ([]OpResult, error) {
+		return batchInsert(ctx, s, ops)
+	})
+}
+
+// BatchDelete is like DeleteWithResult, but deletes all the members in ops
+// with one request per server. The results are in the same order as ops.
+func (g *group) BatchDelete(ctx context.Context, ops []Op) []ItemResult {
+	hints := make([]Hint, len(ops))
+	for i, op := range ops {
+		hints[i] = Hint{Op: hintDelete, SlotID: op.SlotID, Key: op.Key, Member: op.Member, Timestamp: op.Timestamp}
+	}
+	return g.writeBatch(ctx, hints, func(ctx context.Context, s Server) ([]OpResult, error) {
+		return batchDelete(ctx, s, ops)
+	})
+}
+
+// MultiSelect is like Select, but selects all the sets in ops. The results
+// are in the same order as ops.
+//
+// With ReadQuorum, the sets are read concurrently one by one, since they
+// must be dumped from multiple servers. Otherwise, they are read with one
+// request, and only the failed ones are retried on the following servers.
+func (g *group) MultiSelect(ctx context.Context, ops []Op) []SelectResult {
+	results := make([]SelectResult, len(ops))
+
+	if g.readStrategy == ReadQuorum {
+		var wg sync.WaitGroup
+		for i, op := range ops {
+			wg.Add(1)
+			go func(i int, op Op) {
+				defer wg.Done()
+				results[i].Elements, results[i].Err = g.selectQuorum(ctx, op.SlotID, op.Key, op.Timestamp)
+			}(i, op)
+		}
+		wg.Wait()
+		return results
+	}
+
+	pending := make([]int, len(ops))
+	for i := range ops {
+		pending[i] = i
+		results[i].Err = fmt.Errorf("no healthy server in group %d", g.id)
+	}
+	for _, s := range g.candidates() {
+		if len(pending) == 0 {
+			break
+		}
+
+		batch := make([]Op, len(pending))
+		for j, i := range pending {
+			batch[j] = ops[i]
+		}
+		rs, err := multiSelect(ctx, s, batch)
+		if err == nil && len(rs) != len(batch) {
+			err = fmt.Errorf("%s: got %d results for %d items", s.Addr(), len(rs), len(batch))
+		}
+
+		var failed []int
+		for j, i := range pending {
+			if err != nil {
+				results[i].Err = err
+			} else {
+				results[i] = rs[j]
+			}
+			if results[i].Err != nil {
+				failed = append(failed, i)
+			}
+		}
+		pending = failed
+	}
+	return results
+}
+
+// writeBatch applies action, which writes a batch of items, to all the
+// servers, and returns as soon as every item reaches the write quorum. The
+// replicas that have not answered by then are marked as pending in the
+// results. If an item fails on a server, its hint in hints is kept to be
+// handed off to the server later, if g.hints is set.
+//
+// The writes are canceled along with ctx only until the quorum is reached,
+// after which the pending ones are left to complete within the deadline of
+// ctx, if any. The writes canceled before the quorum is reached are
+// abandoned, which are not handed off, while those timed out after that
+// (i.e. on the slow replicas) are.
+func (g *group) writeBatch(ctx context.Context, hints []Hint, action func(ctx context.Context, s Server) ([]OpResult, error)) []ItemResult {
+	n := len(hints)
+	if n == 0 {
+		return nil
+	}
+
+	wctx, cancel := detach(ctx)
+	quorumC := make(chan struct{})
+	// Whether the writes are canceled before the quorum, and the results
+	// gathered from each server, which are only settled once quorumC is
+	// closed. Since wctx shares the deadline of ctx, a timed-out write cannot
+	// tell whether it is abandoned by itself.
+	var abandoned bool
+	gathered := make([][]OpResult, len(g.servers))
+	go func() {
+		select {
+		case <-ctx.Done():
+			cancel()
+		case <-quorumC:
+		}
+	}()
+
+	type result struct {
+		i       int
+		results []OpResult
+		latency time.Duration
+	}
+	// Scatter
+	var wg sync.WaitGroup
+	resultChan := make(chan result, len(g.servers))
+	for i, s := range g.servers {
+		wg.Add(1)
+		go func(i int, s Server) {
+			defer wg.Done()
+			start := time.Now()
+			results, err := action(wctx, s)
+			if err == nil && len(results) != n {
+				err = fmt.Errorf("%s: got %d results for %d items", s.Addr(), len(results), n)
+			}
+			if err != nil {
+				results = make([]OpResult, n)
+				for j := range results {
+					results[j].Err = err
+				}
+			}
+			resultChan <- result{i: i, results: results, latency: time.Since(start)}
+
+			// The results that come after the gathering are handed off
+			// here, unless abandoned.
+			if g.hints == nil {
+				return
+			}
+			<-quorumC
+			if !abandoned && gathered[i] == nil {
+				g.handOff(s, hints, results)
+			}
+		}(i, s)
+	}
+	go func() {
+		wg.Wait()
+		cancel()
+	}()
+
+	// Gather
+	items := make([]ItemResult, n)
+	for j := range items {
+		wr := &WriteResult{Replicas: make([]ReplicaResult, len(g.servers))}
+		for i, s := range g.servers {
+			wr.Replicas[i] = ReplicaResult{Addr: s.Addr(), Pending: true, Joining: g.joining[s.Addr()]}
+		}
+		items[j].Result = wr
+	}
+	reached := 0 // The number of the items that have reached the quorum.
+	for k := 0; k < cap(resultChan) && reached < n; k++ {
+		result := <-resultChan
+		gathered[result.i] = result.results
+		for j, r := range result.results {
+			wr := items[j].Result
+			before := wr.Succeeded() >= g.writeQuorum
+			wr.Replicas[result.i] = ReplicaResult{
+				Addr:    wr.Replicas[result.i].Addr,
+				Joining: wr.Replicas[result.i].Joining,
+				Status:  r.Status,
+				Err:     r.Err,
+				Latency: result.latency,
+			}
+			if !before && wr.Succeeded() >= g.writeQuorum {
+				reached++
+			}
+		}
+	}
+	abandoned = reached < n && ctx.Err() != nil
+	if g.hints != nil && !abandoned {
+		for i, s := range g.servers {
+			g.handOff(s, hints, gathered[i])
+		}
+	}
+	close(quorumC)
+
+	// Report
+	for j := range items {
+		if wr := items[j].Result; wr.Succeeded() < g.writeQuorum {
+			items[j].Err = &PartialWriteError{Result: wr, Quorum: g.writeQuorum}
+		}
+	}
+	return items
+}
+
+// handOff keeps the failed writes on the server s as hints, to be delivered
+// once s is back.
+func (g *group) handOff(s Server, hints []Hint, results []OpResult) {
+	for j, r := range results {
+		if r.Err == nil {
+			continue
+		}
+		if err := g.hints.Add(s.Addr(), hints[j]); err != nil {
+			log.Printf("failed to add hint for %s: %v", s.Addr(), err)
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package group
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"strings"
 	"time"
 
@@ -13,30 +15,106 @@ type Server interface {
 	common.Inserter
 	common.Deleter
 	common.Selector
+	common.Scanner
+	common.Dumper
+	common.Purger
+	SlotMover
+	HashTree
 
 	Addr() string
+	Close() error
 }
 
+// SlotMover moves whole slots between servers directly.
+type SlotMover interface {
+	// ImportSlot makes the server pull the slot identified by slotID from
+	// the server at source, starting from cursor. It returns the cursor to
+	// resume from if the import fails.
+	ImportSlot(slotID int, source, cursor string) (string, error)
+	// DropSlot removes the slot identified by slotID from the server.
+	DropSlot(slotID int) error
+}
+
+// HashTree exposes the hash trees of the slots in a server, which are used
+// to find the differences between the replicas.
+type HashTree interface {
+	// Tree returns the hashes of the given nodes in the hash tree of the
+	// slot identified by slotID, as well as the number of the leaves.
+	Tree(slotID int, nodes []int) (hashes []uint64, leaves int, err error)
+	// TreeKeys sends the keys of the sets belonging to the given leaves of
+	// the hash tree of the slot identified by slotID in batches. Like
+	// common.Scanner, the returned function reports why the scan ended
+	// after the channel is closed.
+	TreeKeys(slotID int, leaves []int, batchSize int) (<-chan []string, func() error)
+}
+
+// The maximum number of attempts to import a slot from a server, each of
+// which resumes from where the last one failed.
+const maxImportAttempts = 3
+
 type group struct {
-	id           int
-	servers      []Server
-	writeQuorum  int
-	readStrategy string
+	// The counters, which are the first fields to ensure their 64-bit
+	// alignment for the atomic operations.
+	reads       uint64 // For the round-robin read strategy.
+	repairStats RepairStats
+	hedgeStats  HedgeStats
+
+	id              int
+	servers         []Server
+	voters          []Server        // The servers that are not joining
+	joining         map[string]bool // The addresses of the joining servers
+	writeQuorum     int
+	readStrategy    string
+	readRepair      ReadRepair
+	hedgePercentile float64
+	latencies       map[string]*latency // The read latencies per server
+	hints           *HintStore
 }
 
-func NewGroup(id int, servers []Server, writeQuorum int, readStrategy string) *group {
+// NewGroup creates a group of servers. The read strategy defaults to
+// ReadPrimary if it is empty, and an unknown one will be rejected.
+func NewGroup(id int, servers []Server, writeQuorum int, readStrategy string) (*group, error) {
+	switch readStrategy {
+	case "":
+		readStrategy = ReadPrimary
+	case ReadPrimary, ReadRoundRobin, ReadRandom, ReadQuorum:
+	default:
+		return nil, fmt.Errorf("unknown read strategy: %s", readStrategy)
+	}
+
+	latencies := make(map[string]*latency, len(servers))
+	for _, s := range servers {
+		latencies[s.Addr()] = newLatency()
+	}
+
 	return &group{
 		id:           id,
 		servers:      servers,
+		voters:       servers,
 		writeQuorum:  writeQuorum,
 		readStrategy: readStrategy,
-	}
+		latencies:    latencies,
+	}, nil
 }
 
 func (g *group) ID() int {
 	return g.id
 }
 
+// Close closes all the servers in the group, which is called once the group
+// is removed from the cluster.
+func (g *group) Close() error {
+	var firstErr error
+	for _, s := range g.servers {
+		if err := s.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Servers returns the addresses of all the servers in the group, including
+// the joining ones.
 func (g *group) Servers() []cluster.Server {
 	addrs := make([]cluster.Server, len(g.servers))
 	for i, s := range g.servers {
@@ -45,70 +123,307 @@ func (g *group) Servers() []cluster.Server {
 	return addrs
 }
 
-func (g *group) write(action func(s Server) (bool, error)) (bool, error) {
+// SetJoining marks the servers at addrs as joining. A joining server
+// receives all the writes, but it neither counts toward the write quorum
+// nor serves any read, until its data has been backfilled from the other
+// servers (see Backfill). It must be called before the group is used.
+func (g *group) SetJoining(addrs ...string) {
+	g.joining = make(map[string]bool, len(addrs))
+	for _, addr := range addrs {
+		g.joining[addr] = true
+	}
+
+	g.voters = nil
+	for _, s := range g.servers {
+		if !g.joining[s.Addr()] {
+			g.voters = append(g.voters, s)
+		}
+	}
+}
+
+// Options returns the settings of the group.
+func (g *group) Options() cluster.GroupOptions {
+	return cluster.GroupOptions{HedgePercentile: g.hedgePercentile}
+}
+
+// WriteQuorum returns the number of the servers, which are not joining,
+// that a write must succeed on.
+func (g *group) WriteQuorum() int {
+	return g.writeQuorum
+}
+
+// Joining returns the addresses of the joining servers in the group.
+func (g *group) Joining() []cluster.Server {
+	var addrs []cluster.Server
+	for _, s := range g.servers {
+		if g.joining[s.Addr()] {
+			addrs = append(addrs, cluster.Server(s.Addr()))
+		}
+	}
+	return addrs
+}
+
+// Backfill makes the joining server at addr import the slot identified by
+// slotID from the other servers of g.
+//
+// The writes to the slot during the backfill are also sent to the joining
+// server, and the ones it has missed are repaired by the anti-entropy.
+func (g *group) Backfill(slotID int, addr cluster.Server) error {
+	for _, s := range g.servers {
+		if s.Addr() == string(addr) {
+			if !g.joining[s.Addr()] {
+				return fmt.Errorf("%s is not joining group %d", addr, g.id)
+			}
+			return g.importSlot(s, slotID)
+		}
+	}
+	return fmt.Errorf("%s not found in group %d", addr, g.id)
+}
+
+// write applies action to all the servers, and returns as soon as the write
+// quorum is reached, which is a batch write of one item (see writeBatch).
+func (g *group) write(ctx context.Context, hint Hint, action func(ctx context.Context, s Server) (bool, error)) (*WriteResult, error) {
+	items := g.writeBatch(ctx, []Hint{hint}, func(ctx context.Context, s Server) ([]OpResult, error) {
+		status, err := action(ctx, s)
+		return []OpResult{{Status: status, Err: err}}, nil
+	})
+	return items[0].Result, items[0].Err
+}
+
+// detach returns a context, which has the same deadline as ctx, but is not
+// canceled along with ctx.
+func detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(context.Background(), deadline)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// InsertWithResult is like Insert, but returns the outcomes on all the
+// replicas.
+func (g *group) InsertWithResult(ctx context.Context, slotID int, key, member string, timestamp int64, ttl time.Duration) (*WriteResult, error) {
+	hint := Hint{Op: hintInsert, SlotID: slotID, Key: key, Member: member, Timestamp: timestamp, TTL: ttl}
+	return g.write(ctx, hint, func(ctx context.Context, s Server) (bool, error) {
+		return s.Insert(ctx, slotID, key, member, timestamp, ttl)
+	})
+}
+
+// DeleteWithResult is like Delete, but returns the outcomes on all the
+// replicas.
+func (g *group) DeleteWithResult(ctx context.Context, slotID int, key, member string, timestamp int64) (*WriteResult, error) {
+	hint := Hint{Op: hintDelete, SlotID: slotID, Key: key, Member: member, Timestamp: timestamp}
+	return g.write(ctx, hint, func(ctx context.Context, s Server) (bool, error) {
+		return s.Delete(ctx, slotID, key, member, timestamp)
+	})
+}
+
+func (g *group) Insert(ctx context.Context, slotID int, key, member string, timestamp int64, ttl time.Duration) (bool, error) {
+	wr, err := g.InsertWithResult(ctx, slotID, key, member, timestamp, ttl)
+	if err != nil {
+		return false, err
+	}
+	return wr.Status(), nil
+}
+
+func (g *group) Delete(ctx context.Context, slotID int, key, member string, timestamp int64) (bool, error) {
+	wr, err := g.DeleteWithResult(ctx, slotID, key, member, timestamp)
+	if err != nil {
+		return false, err
+	}
+	return wr.Status(), nil
+}
+
+func (g *group) Select(ctx context.Context, slotID int, key string, timestamp int64) ([]common.Element, error) {
+	if g.readStrategy == ReadQuorum {
+		return g.selectQuorum(ctx, slotID, key, timestamp)
+	}
+	return g.selectOne(ctx, slotID, key, timestamp)
+}
+
+// MigrateKeys moves the sets at keys in the slot identified by slotID from
+// g to the group to. All the elements, including the tombstones, are copied
+// with their original timestamps and TTLs, and then purged from g.
+//
+// Since the elements are written into to as ordinary LWW operations, the
+// copies never override any newer write that has been made to to. Thus
+// the migration of a key is idempotent, and it is safe to migrate the same
+// key concurrently (e.g. by MigrateSlot and Cluster.MapToSlot).
+func (g *group) MigrateKeys(ctx context.Context, to cluster.Group, slotID int, keys ...string) error {
+	dst, ok := to.(writer)
+	if !ok {
+		return fmt.Errorf("cannot migrate keys to group %d of type %T", to.ID(), to)
+	}
+
+	for _, key := range keys {
+		if err := g.migrateKey(ctx, dst, slotID, key); err != nil {
+			return fmt.Errorf("failed to migrate key %q: %s", key, err)
+		}
+	}
+	return nil
+}
+
+// MigrateSlot moves all the sets in the slot identified by slotID from g to
+// the group to. Every server of to pulls the slot directly from the servers
+// of g, and then the slot is dropped from g.
+//
+// A server of to succeeds if it has imported the slot from any R servers of
+// g, where R + g.writeQuorum > len(g.voters), and the migration succeeds if
+// at least to.writeQuorum servers, which are not joining, succeed.
+func (g *group) MigrateSlot(to cluster.Group, slotID int) error {
+	dst, ok := to.(*group)
+	if !ok {
+		return fmt.Errorf("cannot migrate slot to group %d of type %T", to.ID(), to)
+	}
+
 	type result struct {
-		status bool
+		server Server
 		err    error
 	}
 	// Scatter
-	resultChan := make(chan result, len(g.servers))
-	for _, s := range g.servers {
+	resultChan := make(chan result, len(dst.servers))
+	for _, s := range dst.servers {
 		go func(s Server) {
-			status, err := action(s)
-			resultChan <- result{status: status, err: err}
+			resultChan <- result{server: s, err: g.importSlot(s, slotID)}
 		}(s)
 	}
 
 	// Gather
 	var (
-		status     = false
-		errs       = []string(nil)
-		got        = 0
-		need       = g.writeQuorum
-		haveQuorum = func() bool { return (got - len(errs)) >= need }
+		errs   []string
+		failed int // The number of the failed servers that are not joining.
 	)
 	for i := 0; i < cap(resultChan); i++ {
-		result := <-resultChan
-		status = result.status
-		if result.err != nil {
-			errs = append(errs, result.err.Error())
+		r := <-resultChan
+		if r.err == nil {
+			continue
 		}
-
-		got++
-		if haveQuorum() {
-			break
+		errs = append(errs, r.err.Error())
+		if !dst.joining[r.server.Addr()] {
+			failed++
 		}
 	}
-
-	// Report
-	if !haveQuorum() {
-		return false, fmt.Errorf("no quorum (%s)", strings.Join(errs, "; "))
+	if len(dst.voters)-failed < dst.writeQuorum {
+		return fmt.Errorf("no quorum (%s)", strings.Join(errs, "; "))
 	}
 
-	return status, nil
+	// Drop. The leftovers on the failed servers are harmless, since the
+	// slot no longer belongs to g once the migration is done.
+	for _, s := range g.servers {
+		if err := s.DropSlot(slotID); err != nil {
+			log.Printf("failed to drop slot %d from %s: %v", slotID, s.Addr(), err)
+		}
+	}
+	return nil
 }
 
-func (g *group) Insert(slotID int, key, member string, timestamp int64, ttl time.Duration) (bool, error) {
-	return g.write(func(s Server) (bool, error) {
-		return s.Insert(slotID, key, member, timestamp, ttl)
-	})
+// importSlot makes dst import the slot identified by slotID from all the
+// servers of g, which are not joining.
+func (g *group) importSlot(dst Server, slotID int) error {
+	var errs []string
+	for _, src := range g.voters {
+		if err := importSlot(dst, src, slotID); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if need := g.readQuorum(); len(g.voters)-len(errs) < need {
+		return fmt.Errorf("%s: no quorum (%s)", dst.Addr(), strings.Join(errs, "; "))
+	}
+	return nil
 }
 
-func (g *group) Delete(slotID int, key, member string, timestamp int64) (bool, error) {
-	return g.write(func(s Server) (bool, error) {
-		return s.Delete(slotID, key, member, timestamp)
-	})
+// importSlot makes dst import the slot identified by slotID from src, which
+// is resumed on failures for at most maxImportAttempts times.
+func importSlot(dst, src Server, slotID int) (err error) {
+	cursor := ""
+	for i := 0; i < maxImportAttempts; i++ {
+		if cursor, err = dst.ImportSlot(slotID, src.Addr(), cursor); err == nil {
+			return nil
+		}
+		log.Printf("failed to import slot %d from %s to %s: %v", slotID, src.Addr(), dst.Addr(), err)
+	}
+	return err
 }
 
-func (g *group) Select(slotID int, key string, timestamp int64) ([]common.Element, error) {
-	// TODO: select according to g.readStrategy
-	return g.servers[0].Select(slotID, key, timestamp)
+// writer is the group that the elements can be migrated to.
+type writer interface {
+	common.Inserter
+	common.Deleter
 }
 
-func (g *group) MigrateKeys(to cluster.Group, slotID int, keys ...string) error {
+// migrateKey moves the set at key from g to the group to.
+//
+// The set is dumped from all the servers of g, which are not joining, and
+// the dumps are merged by their timestamps. It is enough to have the dumps
+// from any R servers, where R + writeQuorum > len(g.voters), since they must have seen all the
+// successful writes. The elements are only purged from the servers that
+// have been dumped, and the leftovers on the other servers are harmless
+// since the slot no longer belongs to g once the migration is done.
+func (g *group) migrateKey(ctx context.Context, to writer, slotID int, key string) error {
+	type dump struct {
+		server   Server
+		elements []common.Element
+		err      error
+	}
+	now := time.Now().UnixNano()
+
+	dumpChan := make(chan dump, len(g.voters))
+	for _, s := range g.voters {
+		go func(s Server) {
+			elements, err := s.Dump(ctx, slotID, key, now)
+			dumpChan <- dump{server: s, elements: elements, err: err}
+		}(s)
+	}
+
+	var (
+		dumps  []dump
+		errs   []string
+		merged = make(map[string]common.Element)
+	)
+	for i := 0; i < cap(dumpChan); i++ {
+		d := <-dumpChan
+		if d.err != nil {
+			errs = append(errs, d.err.Error())
+			continue
+		}
+		dumps = append(dumps, d)
+		for _, e := range d.elements {
+			if old, ok := merged[e.Member]; !ok || newer(e, old) {
+				merged[e.Member] = e
+			}
+		}
+	}
+	if need := g.readQuorum(); len(dumps) < need {
+		return fmt.Errorf("no quorum (%s)", strings.Join(errs, "; "))
+	}
+
+	// Copy
+	for _, e := range merged {
+		var err error
+		if e.Deleted {
+			_, err = to.Delete(ctx, slotID, key, e.Member, e.Timestamp)
+		} else {
+			_, err = to.Insert(ctx, slotID, key, e.Member, e.Timestamp, e.TTL)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// Purge
+	for _, d := range dumps {
+		if len(d.elements) == 0 {
+			continue
+		}
+		if _, err := d.server.Purge(ctx, slotID, key, d.elements); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-func (g *group) MigrateSlot(to cluster.Group, slotID int) error {
-	return nil
+// newer reports whether the element a wins over b, according to the LWW
+// rules where a deletion wins over an insertion with the same timestamp.
+func newer(a, b common.Element) bool {
+	return a.Timestamp > b.Timestamp || (a.Timestamp == b.Timestamp && a.Deleted && !b.Deleted)
 }
@@ -1,13 +1,24 @@
 package group_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/RussellLuo/goku/cluster"
 	"github.com/RussellLuo/goku/common"
 	"github.com/RussellLuo/goku/group"
+	"github.com/RussellLuo/goku/server"
 )
 
 type mockServer struct {
@@ -15,33 +26,160 @@ type mockServer struct {
 	insertFn func(slotID int, key, member string, timestamp int64, ttl time.Duration) (bool, error)
 	deleteFn func(slotID int, key, member string, timestamp int64) (bool, error)
 	selectFn func(slotID int, key string, timestamp int64) ([]common.Element, error)
+	dumpFn   func(slotID int, key string, timestamp int64) ([]common.Element, error)
 }
 
 func (s *mockServer) Addr() string {
 	return s.addr
 }
 
-func (s *mockServer) Insert(slotID int, key, member string, timestamp int64, ttl time.Duration) (bool, error) {
+func (s *mockServer) Close() error {
+	return nil
+}
+
+func (s *mockServer) Insert(ctx context.Context, slotID int, key, member string, timestamp int64, ttl time.Duration) (bool, error) {
 	if s.insertFn == nil {
 		return false, nil
 	}
 	return s.insertFn(slotID, key, member, timestamp, ttl)
 }
 
-func (s *mockServer) Delete(slotID int, key, member string, timestamp int64) (bool, error) {
+func (s *mockServer) Delete(ctx context.Context, slotID int, key, member string, timestamp int64) (bool, error) {
 	if s.deleteFn == nil {
 		return false, nil
 	}
 	return s.deleteFn(slotID, key, member, timestamp)
 }
 
-func (s *mockServer) Select(slotID int, key string, timestamp int64) ([]common.Element, error) {
+func (s *mockServer) Select(ctx context.Context, slotID int, key string, timestamp int64) ([]common.Element, error) {
 	if s.selectFn == nil {
 		return nil, nil
 	}
 	return s.selectFn(slotID, key, timestamp)
 }
 
+func (s *mockServer) Keys(ctx context.Context, slotID, batchSize int) (<-chan []string, func() error) {
+	c := make(chan []string)
+	close(c)
+	return c, func() error { return nil }
+}
+
+func (s *mockServer) Dump(ctx context.Context, slotID int, key string, timestamp int64) ([]common.Element, error) {
+	if s.dumpFn == nil {
+		return nil, nil
+	}
+	return s.dumpFn(slotID, key, timestamp)
+}
+
+func (s *mockServer) Purge(ctx context.Context, slotID int, key string, elements []common.Element) (int, error) {
+	return 0, nil
+}
+
+func (s *mockServer) ImportSlot(slotID int, source, cursor string) (string, error) {
+	return "", nil
+}
+
+func (s *mockServer) DropSlot(slotID int) error {
+	return nil
+}
+
+func (s *mockServer) Tree(slotID int, nodes []int) ([]uint64, int, error) {
+	return make([]uint64, len(nodes)), server.TreeLeaves, nil
+}
+
+func (s *mockServer) TreeKeys(slotID int, leaves []int, batchSize int) (<-chan []string, func() error) {
+	c := make(chan []string)
+	close(c)
+	return c, func() error { return nil }
+}
+
+// localServer is a group.Server backed by an in-process server, which can
+// import slots from its peers.
+type localServer struct {
+	*server.Server
+	addr  string
+	peers map[string]*localServer
+}
+
+func newLocalServers(addrs ...string) []*localServer {
+	peers := make(map[string]*localServer)
+	servers := make([]*localServer, len(addrs))
+	for i, addr := range addrs {
+		servers[i] = &localServer{Server: server.NewServer(), addr: addr, peers: peers}
+		peers[addr] = servers[i]
+	}
+	return servers
+}
+
+func (s *localServer) Addr() string {
+	return s.addr
+}
+
+func (s *localServer) Insert(ctx context.Context, slotID int, key, member string, timestamp int64, ttl time.Duration) (bool, error) {
+	return s.Server.Insert(slotID, key, member, timestamp, ttl)
+}
+
+func (s *localServer) Delete(ctx context.Context, slotID int, key, member string, timestamp int64) (bool, error) {
+	return s.Server.Delete(slotID, key, member, timestamp)
+}
+
+func (s *localServer) Select(ctx context.Context, slotID int, key string, timestamp int64) ([]common.Element, error) {
+	return s.Server.Select(slotID, key, timestamp)
+}
+
+func (s *localServer) Dump(ctx context.Context, slotID int, key string, timestamp int64) ([]common.Element, error) {
+	return s.Server.Dump(slotID, key, timestamp)
+}
+
+func (s *localServer) Purge(ctx context.Context, slotID int, key string, elements []common.Element) (int, error) {
+	return s.Server.Purge(slotID, key, elements)
+}
+
+func (s *localServer) Tree(slotID int, nodes []int) ([]uint64, int, error) {
+	return s.Server.Tree(slotID, nodes), server.TreeLeaves, nil
+}
+
+func (s *localServer) TreeKeys(slotID int, leaves []int, batchSize int) (<-chan []string, func() error) {
+	in := make(map[int]bool)
+	for _, l := range leaves {
+		in[l] = true
+	}
+	c := make(chan []string, 1)
+	var keys []string
+	all, errFn := s.Keys(context.Background(), slotID, batchSize)
+	for batch := range all {
+		for _, key := range batch {
+			if in[server.TreeLeaf(key)] {
+				keys = append(keys, key)
+			}
+		}
+	}
+	c <- keys
+	close(c)
+	return c, errFn
+}
+
+func (s *localServer) ImportSlot(slotID int, source, cursor string) (string, error) {
+	src, ok := s.peers[source]
+	if !ok {
+		return cursor, fmt.Errorf("unknown server %s", source)
+	}
+	for {
+		// Export one entry at a time to exercise the cursors.
+		entries, next, err := src.ExportSlot(slotID, cursor, 1, time.Now().UnixNano())
+		if err != nil {
+			return cursor, err
+		}
+		if err := s.Server.ImportSlot(slotID, entries); err != nil {
+			return cursor, err
+		}
+		if next == "" {
+			return "", nil
+		}
+		cursor = next
+	}
+}
+
 func TestGroup_Insert(t *testing.T) {
 	slotID := 0
 	key := "key"
@@ -191,11 +329,17 @@ func TestGroup_Insert(t *testing.T) {
 		t.Run("", func(t *testing.T) {
 			t.Parallel()
 
-			g := group.NewGroup(groupID, c.in.servers, c.in.writeQuorum, c.in.readStrategy)
-			updated, err := g.Insert(slotID, key, member, ts, ttl)
-			if !reflect.DeepEqual(err, c.want.err) {
+			g, err := group.NewGroup(groupID, c.in.servers, c.in.writeQuorum, c.in.readStrategy)
+			if err != nil {
+				t.Fatalf("err: got(%+v) != want(<nil>)", err)
+			}
+			updated, err := g.Insert(context.Background(), slotID, key, member, ts, ttl)
+			if fmt.Sprint(err) != fmt.Sprint(c.want.err) {
 				t.Errorf("err: got(%+v) != want(%+v)", err, c.want.err)
 			}
+			if c.want.err != nil && !group.IsNoQuorum(err) {
+				t.Errorf("err: got(%+v) != want(no quorum)", err)
+			}
 			if updated != c.want.updated {
 				t.Errorf("updated: got(%+v) != want(%+v)", updated, c.want.updated)
 			}
@@ -203,6 +347,54 @@ func TestGroup_Insert(t *testing.T) {
 	}
 }
 
+func TestGroup_InsertWithResult(t *testing.T) {
+	servers := []group.Server{
+		&mockServer{
+			addr: "server1",
+			insertFn: func(slotID int, key, member string, timestamp int64, ttl time.Duration) (bool, error) {
+				return true, nil
+			},
+		},
+		&mockServer{
+			addr: "server2",
+			insertFn: func(slotID int, key, member string, timestamp int64, ttl time.Duration) (bool, error) {
+				return false, fmt.Errorf("fail to insert at server2")
+			},
+		},
+		&mockServer{
+			addr: "server3",
+			insertFn: func(slotID int, key, member string, timestamp int64, ttl time.Duration) (bool, error) {
+				return false, nil
+			},
+		},
+	}
+	g, _ := group.NewGroup(1, servers, 3, "")
+
+	wr, err := g.InsertWithResult(context.Background(), 0, "key", "member", time.Now().UnixNano(), 0)
+	pwe, ok := err.(*group.PartialWriteError)
+	if !ok || pwe.Result != wr || pwe.Quorum != 3 {
+		t.Fatalf("err: got(%+v) != want(partial write error)", err)
+	}
+
+	for i := range wr.Replicas {
+		wr.Replicas[i].Latency = 0
+	}
+	want := []group.ReplicaResult{
+		{Addr: "server1", Status: true},
+		{Addr: "server2", Err: fmt.Errorf("fail to insert at server2")},
+		{Addr: "server3"},
+	}
+	if !reflect.DeepEqual(wr.Replicas, want) {
+		t.Errorf("replicas: got(%+v) != want(%+v)", wr.Replicas, want)
+	}
+	if n := wr.Succeeded(); n != 2 {
+		t.Errorf("succeeded: got(%+v) != want(%+v)", n, 2)
+	}
+	if !wr.Status() {
+		t.Errorf("status: got(%+v) != want(%+v)", false, true)
+	}
+}
+
 func TestGroup_Delete(t *testing.T) {
 	slotID := 0
 	key := "key"
@@ -351,11 +543,17 @@ func TestGroup_Delete(t *testing.T) {
 		t.Run("", func(t *testing.T) {
 			t.Parallel()
 
-			g := group.NewGroup(groupID, c.in.servers, c.in.writeQuorum, c.in.readStrategy)
-			deleted, err := g.Delete(slotID, key, member, ts)
-			if !reflect.DeepEqual(err, c.want.err) {
+			g, err := group.NewGroup(groupID, c.in.servers, c.in.writeQuorum, c.in.readStrategy)
+			if err != nil {
+				t.Fatalf("err: got(%+v) != want(<nil>)", err)
+			}
+			deleted, err := g.Delete(context.Background(), slotID, key, member, ts)
+			if fmt.Sprint(err) != fmt.Sprint(c.want.err) {
 				t.Errorf("err: got(%+v) != want(%+v)", err, c.want.err)
 			}
+			if c.want.err != nil && !group.IsNoQuorum(err) {
+				t.Errorf("err: got(%+v) != want(no quorum)", err)
+			}
 			if deleted != c.want.deleted {
 				t.Errorf("updated: got(%+v) != want(%+v)", deleted, c.want.deleted)
 			}
@@ -410,6 +608,93 @@ func TestGroup_Select(t *testing.T) {
 				err: nil,
 			},
 		},
+		{
+			in: inType{
+				servers: []group.Server{
+					&mockServer{
+						addr: "server1",
+						selectFn: func(slotID int, key string, timestamp int64) ([]common.Element, error) {
+							return nil, fmt.Errorf("fail to select at server1")
+						},
+					},
+					&mockServer{
+						addr: "server2",
+						selectFn: func(slotID int, key string, timestamp int64) ([]common.Element, error) {
+							return []common.Element{
+								{Member: "member", Timestamp: ts},
+							}, nil
+						},
+					},
+				},
+				readStrategy: group.ReadPrimary,
+			},
+			want: wantType{
+				elements: []common.Element{
+					{Member: "member", Timestamp: ts},
+				},
+				err: nil,
+			},
+		},
+		{
+			in: inType{
+				servers: []group.Server{
+					&mockServer{
+						addr: "server1",
+						dumpFn: func(slotID int, key string, timestamp int64) ([]common.Element, error) {
+							return []common.Element{
+								{Member: "member2", Timestamp: ts},
+								{Member: "member3", Timestamp: ts},
+							}, nil
+						},
+					},
+					&mockServer{
+						addr: "server2",
+						dumpFn: func(slotID int, key string, timestamp int64) ([]common.Element, error) {
+							return []common.Element{
+								{Member: "member1", Timestamp: ts, TTL: 2 * time.Second},
+								{Member: "member2", Timestamp: ts, Deleted: true},
+								{Member: "member3", Timestamp: ts + 1},
+							}, nil
+						},
+					},
+				},
+				writeQuorum:  1,
+				readStrategy: group.ReadQuorum,
+			},
+			want: wantType{
+				elements: []common.Element{
+					{Member: "member1", Timestamp: ts, TTL: 2 * time.Second},
+					{Member: "member3", Timestamp: ts + 1},
+				},
+				err: nil,
+			},
+		},
+		{
+			in: inType{
+				servers: []group.Server{
+					&mockServer{
+						addr: "server1",
+						dumpFn: func(slotID int, key string, timestamp int64) ([]common.Element, error) {
+							return []common.Element{
+								{Member: "member", Timestamp: ts},
+							}, nil
+						},
+					},
+					&mockServer{
+						addr: "server2",
+						dumpFn: func(slotID int, key string, timestamp int64) ([]common.Element, error) {
+							return nil, fmt.Errorf("fail to dump at server2")
+						},
+					},
+				},
+				writeQuorum:  1,
+				readStrategy: group.ReadQuorum,
+			},
+			want: wantType{
+				elements: nil,
+				err:      fmt.Errorf("no quorum (fail to dump at server2)"),
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -417,8 +702,11 @@ func TestGroup_Select(t *testing.T) {
 		t.Run("", func(t *testing.T) {
 			t.Parallel()
 
-			g := group.NewGroup(groupID, c.in.servers, c.in.writeQuorum, c.in.readStrategy)
-			elements, err := g.Select(slotID, "key1", ts)
+			g, err := group.NewGroup(groupID, c.in.servers, c.in.writeQuorum, c.in.readStrategy)
+			if err != nil {
+				t.Fatalf("err: got(%+v) != want(<nil>)", err)
+			}
+			elements, err := g.Select(context.Background(), slotID, "key1", ts)
 			if !reflect.DeepEqual(err, c.want.err) {
 				t.Errorf("err: got(%+v) != want(%+v)", err, c.want.err)
 			}
@@ -428,3 +716,746 @@ func TestGroup_Select(t *testing.T) {
 		})
 	}
 }
+
+func TestNewGroup(t *testing.T) {
+	cases := []struct {
+		readStrategy string
+		wantErr      error
+	}{
+		{readStrategy: ""},
+		{readStrategy: group.ReadPrimary},
+		{readStrategy: group.ReadRoundRobin},
+		{readStrategy: group.ReadRandom},
+		{readStrategy: group.ReadQuorum},
+		{readStrategy: "nearest", wantErr: fmt.Errorf("unknown read strategy: nearest")},
+	}
+	for _, c := range cases {
+		_, err := group.NewGroup(1, nil, 1, c.readStrategy)
+		if !reflect.DeepEqual(err, c.wantErr) {
+			t.Errorf("err: got(%+v) != want(%+v)", err, c.wantErr)
+		}
+	}
+}
+
+func TestGroup_MigrateSlot(t *testing.T) {
+	slotID := 0
+	ts := time.Now().UnixNano()
+	ttl := time.Hour
+
+	servers := newLocalServers("server1", "server2", "server3")
+	src1, src2, dst := servers[0], servers[1], servers[2]
+
+	src1.Insert(context.Background(), slotID, "key1", "member1", ts, 0)
+	src2.Insert(context.Background(), slotID, "key1", "member1", ts, 0)
+	src1.Insert(context.Background(), slotID, "key1", "member2", ts, ttl)
+	// The deletion has only reached server2.
+	src2.Delete(context.Background(), slotID, "key1", "member2", ts+1)
+	src1.Insert(context.Background(), slotID, "key2", "member1", ts, 0)
+	src2.Insert(context.Background(), slotID, "key2", "member1", ts, 0)
+	// A newer insertion has been made to the destination.
+	dst.Insert(context.Background(), slotID, "key2", "member1", ts+1, ttl)
+
+	from, _ := group.NewGroup(1, []group.Server{src1, src2}, 1, "")
+	to, _ := group.NewGroup(2, []group.Server{dst}, 1, "")
+	if err := from.MigrateSlot(to, slotID); err != nil {
+		t.Fatalf("err: got(%+v) != want(<nil>)", err)
+	}
+
+	cases := []struct {
+		s    *localServer
+		key  string
+		want []common.Element
+	}{
+		{
+			s:   dst,
+			key: "key1",
+			want: []common.Element{
+				{Member: "member1", Timestamp: ts},
+				{Member: "member2", Timestamp: ts + 1, Deleted: true},
+			},
+		},
+		{
+			s:   dst,
+			key: "key2",
+			want: []common.Element{
+				{Member: "member1", Timestamp: ts + 1, TTL: ttl},
+			},
+		},
+		{
+			s:   src1,
+			key: "key1",
+		},
+		{
+			s:   src2,
+			key: "key2",
+		},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run("", func(t *testing.T) {
+			elements, err := c.s.Dump(context.Background(), slotID, c.key, ts)
+			if err != nil {
+				t.Fatalf("err: got(%+v) != want(<nil>)", err)
+			}
+			if !reflect.DeepEqual(elements, c.want) {
+				t.Errorf("elements of %s at %s: got(%+v) != want(%+v)", c.key, c.s.Addr(), elements, c.want)
+			}
+		})
+	}
+
+	// Migrating again does nothing.
+	if err := from.MigrateKeys(context.Background(), to, slotID, "key1", "key2"); err != nil {
+		t.Fatalf("err: got(%+v) != want(<nil>)", err)
+	}
+}
+
+func TestGroup_Joining(t *testing.T) {
+	slotID := 0
+	ts := time.Now().UnixNano()
+
+	servers := newLocalServers("server1", "server2", "server3")
+	s1, s2, s3 := servers[0], servers[1], servers[2]
+
+	// The member has only reached the serving servers.
+	s1.Insert(context.Background(), slotID, "key", "member1", ts, 0)
+	s2.Insert(context.Background(), slotID, "key", "member1", ts, 0)
+
+	// The joining server is the primary, which is not read.
+	g, _ := group.NewGroup(1, []group.Server{s3, s1, s2}, 2, "")
+	g.SetJoining("server3")
+
+	wr, err := g.InsertWithResult(context.Background(), slotID, "key", "member2", ts, 0)
+	if err != nil {
+		t.Fatalf("err: got(%+v) != want(<nil>)", err)
+	}
+	if !wr.Replicas[0].Joining || wr.Replicas[0].Err != nil {
+		t.Errorf("replica: got(%+v) != want(joining)", wr.Replicas[0])
+	}
+	if n := wr.Succeeded(); n != 2 {
+		t.Errorf("succeeded: got(%+v) != want(%+v)", n, 2)
+	}
+
+	elements, err := g.Select(context.Background(), slotID, "key", ts)
+	want := []common.Element{{Member: "member1", Timestamp: ts}, {Member: "member2", Timestamp: ts}}
+	if err != nil || !reflect.DeepEqual(elements, want) {
+		t.Errorf("elements: got(%+v, %+v) != want(%+v)", elements, err, want)
+	}
+
+	if err := g.Backfill(slotID, "server1"); err == nil {
+		t.Errorf("err: got(<nil>) != want(not joining)")
+	}
+	if err := g.Backfill(slotID, "server3"); err != nil {
+		t.Fatalf("err: got(%+v) != want(<nil>)", err)
+	}
+	elements, _ = s3.Dump(context.Background(), slotID, "key", ts)
+	if !reflect.DeepEqual(elements, want) {
+		t.Errorf("elements of server3: got(%+v) != want(%+v)", elements, want)
+	}
+}
+
+func TestGroup_ReadRepair(t *testing.T) {
+	slotID := 0
+	ts := time.Now().UnixNano()
+
+	servers := newLocalServers("server1", "server2", "server3")
+	servers[0].Insert(context.Background(), slotID, "key", "member1", ts, 0)
+	servers[0].Insert(context.Background(), slotID, "key", "member2", ts, time.Hour)
+	servers[1].Delete(context.Background(), slotID, "key", "member1", ts+1)
+	servers[1].Insert(context.Background(), slotID, "key", "member2", ts, time.Hour)
+
+	g, _ := group.NewGroup(1, []group.Server{servers[0], servers[1], servers[2]}, 1, group.ReadQuorum)
+	g.SetReadRepair(group.ReadRepairBlocking)
+
+	elements, err := g.Select(context.Background(), slotID, "key", ts)
+	if err != nil {
+		t.Fatalf("err: got(%+v) != want(<nil>)", err)
+	}
+	wantElements := []common.Element{{Member: "member2", Timestamp: ts, TTL: time.Hour}}
+	if !reflect.DeepEqual(elements, wantElements) {
+		t.Errorf("elements: got(%+v) != want(%+v)", elements, wantElements)
+	}
+
+	want := []common.Element{
+		{Member: "member1", Timestamp: ts + 1, Deleted: true},
+		{Member: "member2", Timestamp: ts, TTL: time.Hour},
+	}
+	for _, s := range servers {
+		elements, _ := s.Dump(context.Background(), slotID, "key", ts)
+		if !reflect.DeepEqual(elements, want) {
+			t.Errorf("elements at %s: got(%+v) != want(%+v)", s.Addr(), elements, want)
+		}
+	}
+
+	wantStats := group.RepairStats{Repaired: 3}
+	if stats := g.RepairStats(); stats != wantStats {
+		t.Errorf("stats: got(%+v) != want(%+v)", stats, wantStats)
+	}
+}
+
+func TestGroup_SyncSlot(t *testing.T) {
+	slotID := 0
+	ts := time.Now().UnixNano()
+
+	servers := newLocalServers("server1", "server2", "server3")
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("key%d", i)
+		for _, s := range servers {
+			s.Insert(context.Background(), slotID, key, "member", ts, 0)
+		}
+	}
+	// Diverge in three sets.
+	servers[0].Insert(context.Background(), slotID, "key1", "member", ts+1, time.Hour)
+	servers[1].Delete(context.Background(), slotID, "key2", "member", ts+1)
+	servers[2].Insert(context.Background(), slotID, "key100", "member", ts, 0)
+
+	// A failed scan of the keys aborts the sync instead of skipping them.
+	broken := &brokenScanServer{servers[2]}
+	g, _ := group.NewGroup(1, []group.Server{servers[0], servers[1], broken}, 2, "")
+	if n, err := g.SyncSlot(slotID); err == nil || n != 0 {
+		t.Errorf("synced: got(%+v, %v) != want(0, <error>)", n, err)
+	}
+	if got := servers[2].Server.Tree(slotID, []int{1}); reflect.DeepEqual(got, servers[0].Server.Tree(slotID, []int{1})) {
+		t.Errorf("root at %s: got(%+v) != want(<diverged>)", servers[2].Addr(), got)
+	}
+
+	g, _ = group.NewGroup(1, []group.Server{servers[0], servers[1], servers[2]}, 2, "")
+	n, err := g.SyncSlot(slotID)
+	if err != nil {
+		t.Fatalf("err: got(%+v) != want(<nil>)", err)
+	}
+	if n != 3 {
+		t.Errorf("synced: got(%+v) != want(%+v)", n, 3)
+	}
+
+	root := servers[0].Server.Tree(slotID, []int{1})
+	for _, s := range servers[1:] {
+		if got := s.Server.Tree(slotID, []int{1}); !reflect.DeepEqual(got, root) {
+			t.Errorf("root at %s: got(%+v) != want(%+v)", s.Addr(), got, root)
+		}
+	}
+
+	// Nothing to do once the replicas have converged.
+	if n, _ := g.SyncSlot(slotID); n != 0 {
+		t.Errorf("synced: got(%+v) != want(%+v)", n, 0)
+	}
+}
+
+// brokenScanServer is a group.Server whose scans of the keys fail.
+type brokenScanServer struct {
+	*localServer
+}
+
+func (s *brokenScanServer) TreeKeys(slotID int, leaves []int, batchSize int) (<-chan []string, func() error) {
+	c := make(chan []string)
+	close(c)
+	return c, func() error { return errors.New("connection reset") }
+}
+
+// flakyServer is a group.Server that fails all the writes while down, and
+// hangs the inserts until ctx is done while slow.
+type flakyServer struct {
+	group.Server
+	down bool
+	slow bool
+}
+
+func (s *flakyServer) Insert(ctx context.Context, slotID int, key, member string, timestamp int64, ttl time.Duration) (bool, error) {
+	if s.down {
+		return false, fmt.Errorf("%s is down", s.Addr())
+	}
+	if s.slow {
+		<-ctx.Done()
+		return false, ctx.Err()
+	}
+	return s.Server.Insert(ctx, slotID, key, member, timestamp, ttl)
+}
+
+func (s *flakyServer) Delete(ctx context.Context, slotID int, key, member string, timestamp int64) (bool, error) {
+	if s.down {
+		return false, fmt.Errorf("%s is down", s.Addr())
+	}
+	return s.Server.Delete(ctx, slotID, key, member, timestamp)
+}
+
+func TestGroup_HintedHandoff(t *testing.T) {
+	slotID := 0
+	ts := time.Now().UnixNano()
+
+	dir, err := ioutil.TempDir("", "goku-group")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	hints, err := group.OpenHintStore(filepath.Join(dir, "hints.db"), 2, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hints.Close()
+
+	servers := newLocalServers("server1", "server2")
+	s2 := &flakyServer{Server: servers[1], down: true}
+	// Wait for both servers, to ensure that the hint has been added.
+	g, _ := group.NewGroup(1, []group.Server{servers[0], s2}, 2, "")
+	g.SetHints(hints)
+
+	g.Insert(context.Background(), slotID, "key", "member1", ts, 0)
+	g.Delete(context.Background(), slotID, "key", "member2", ts)
+	if n := hints.Backlog("server2"); n != 2 {
+		t.Fatalf("backlog: got(%+v) != want(%+v)", n, 2)
+	}
+
+	// The store is full.
+	if err := hints.Add("server2", group.Hint{}); err != group.ErrHintsFull {
+		t.Errorf("err: got(%+v) != want(%+v)", err, group.ErrHintsFull)
+	}
+
+	// Still down.
+	if n, err := hints.Deliver(s2); n != 0 || err == nil {
+		t.Errorf("delivered: got(%+v, %+v) != want(0, <error>)", n, err)
+	}
+
+	s2.down = false
+	if n, err := hints.Deliver(s2); n != 2 || err != nil {
+		t.Errorf("delivered: got(%+v, %+v) != want(2, <nil>)", n, err)
+	}
+	elements, _ := servers[1].Dump(context.Background(), slotID, "key", ts)
+	want := []common.Element{
+		{Member: "member1", Timestamp: ts},
+		{Member: "member2", Timestamp: ts, Deleted: true},
+	}
+	if !reflect.DeepEqual(elements, want) {
+		t.Errorf("elements: got(%+v) != want(%+v)", elements, want)
+	}
+
+	wantStats := group.HintStats{Delivered: 2, Dropped: 1}
+	if stats := hints.Stats(); stats != wantStats {
+		t.Errorf("stats: got(%+v) != want(%+v)", stats, wantStats)
+	}
+
+	// The expired hints are dropped.
+	hints.Add("server2", group.Hint{Created: time.Now().Add(-2 * time.Hour).UnixNano()})
+	if n, err := hints.Deliver(s2); n != 0 || err != nil {
+		t.Errorf("delivered: got(%+v, %+v) != want(0, <nil>)", n, err)
+	}
+	if stats := hints.Stats(); stats.Backlog != 0 || stats.Expired != 1 {
+		t.Errorf("stats: got(%+v) != want(backlog 0, expired 1)", stats)
+	}
+}
+
+func TestGroup_HintedHandoffOnTimeout(t *testing.T) {
+	slotID := 0
+	ts := time.Now().UnixNano()
+
+	dir, err := ioutil.TempDir("", "goku-group")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	hints, err := group.OpenHintStore(filepath.Join(dir, "hints.db"), 10, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hints.Close()
+
+	servers := newLocalServers("server1", "server2", "server3")
+	s2 := &flakyServer{Server: servers[1]}
+	s3 := &flakyServer{Server: servers[2], slow: true}
+	g, _ := group.NewGroup(1, []group.Server{servers[0], s2, s3}, 2, "")
+	g.SetHints(hints)
+
+	insert := func(member string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		_, err := g.Insert(ctx, slotID, "key", member, ts, 0)
+		// Wait for the slow write to time out.
+		time.Sleep(200 * time.Millisecond)
+		return err
+	}
+
+	// The write on the slow replica times out after the quorum is reached,
+	// which is handed off.
+	if err := insert("member1"); err != nil {
+		t.Fatalf("err: got(%+v) != want(<nil>)", err)
+	}
+	if n := hints.Backlog("server3"); n != 1 {
+		t.Errorf("backlog: got(%+v) != want(%+v)", n, 1)
+	}
+
+	// The writes time out before the quorum is reached, which are abandoned.
+	s2.slow = true
+	if err := insert("member2"); err == nil {
+		t.Fatalf("err: got(%+v) != want(<error>)", err)
+	}
+	for _, addr := range []string{"server2", "server3"} {
+		want := 0
+		if addr == "server3" {
+			want = 1
+		}
+		if n := hints.Backlog(addr); n != want {
+			t.Errorf("backlog of %s: got(%+v) != want(%+v)", addr, n, want)
+		}
+	}
+}
+
+// newCluster creates a single-node cluster, whose groups consist of the
+// given servers.
+func newCluster(t *testing.T, servers []*localServer) (*cluster.Cluster, func()) {
+	dir, err := ioutil.TempDir("", "goku-cluster")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newGroup := func(id int, addrs, joining []cluster.Server, opts cluster.GroupOptions) (cluster.Group, error) {
+		var ss []group.Server
+		for _, addr := range addrs {
+			for _, s := range servers {
+				if s.Addr() == string(addr) {
+					ss = append(ss, s)
+				}
+			}
+		}
+		g, err := group.NewGroup(id, ss, 1, "")
+		if err != nil {
+			return nil, err
+		}
+		return g, nil
+	}
+	c := cluster.NewCluster("test", newGroup, "127.0.0.1:12100", dir)
+	if err := c.Open(true, "node0"); err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("failed to open cluster: %s", err)
+	}
+	// Simple way to ensure there is a leader.
+	time.Sleep(2 * time.Second)
+
+	return c, func() {
+		c.Close(true)
+		os.RemoveAll(dir)
+	}
+}
+
+func TestHealthChecker_AddGroup(t *testing.T) {
+	c, cleanup := newCluster(t, newLocalServers("server1", "server2"))
+	defer cleanup()
+
+	// The groups are probed while being added.
+	hc := group.NewHealthChecker(c, time.Millisecond)
+	hc.Start()
+	for id := 1; id <= 10; id++ {
+		if err := c.AddGroup(id, "server1", "server2"); err != nil {
+			t.Fatalf("err: got(%+v) != want(<nil>)", err)
+		}
+	}
+	// Wait for committed log entry to be applied.
+	time.Sleep(500 * time.Millisecond)
+	hc.Stop()
+
+	if n := len(c.Groups()); n != 10 {
+		t.Errorf("groups: got(%+v) != want(%+v)", n, 10)
+	}
+}
+
+func TestHandoff_RemovedServer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goku-group")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	hints, err := group.OpenHintStore(filepath.Join(dir, "hints.db"), 10, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hints.Close()
+
+	c, cleanup := newCluster(t, newLocalServers("server1", "server2"))
+	defer cleanup()
+
+	c.AddGroup(1, "server1")
+	// Wait for committed log entry to be applied.
+	time.Sleep(500 * time.Millisecond)
+
+	// server2 is not in any group.
+	expired := time.Now().Add(-2 * time.Hour).UnixNano()
+	hints.Add("server1", group.Hint{})
+	hints.Add("server2", group.Hint{Created: expired})
+	hints.Add("server2", group.Hint{Created: expired})
+	hints.Add("server2", group.Hint{})
+
+	h := group.NewHandoff(c, hints, time.Minute)
+	if n := h.Handoff(); n != 1 {
+		t.Errorf("delivered: got(%+v) != want(%+v)", n, 1)
+	}
+
+	// Only the expired hints of server2 are dropped.
+	if n := hints.Backlog("server2"); n != 1 {
+		t.Errorf("backlog: got(%+v) != want(%+v)", n, 1)
+	}
+	wantStats := group.HintStats{Backlog: 1, Delivered: 1, Expired: 2}
+	if stats := hints.Stats(); stats != wantStats {
+		t.Errorf("stats: got(%+v) != want(%+v)", stats, wantStats)
+	}
+	if addrs := hints.Addrs(); !reflect.DeepEqual(addrs, []string{"server2"}) {
+		t.Errorf("addrs: got(%+v) != want(%+v)", addrs, []string{"server2"})
+	}
+}
+
+func TestPool(t *testing.T) {
+	p := group.NewPool("127.0.0.1:0", 2, time.Minute)
+
+	// The connections are dialed lazily, and shared in turn.
+	cli1, err := p.Get()
+	if err != nil {
+		t.Fatalf("err: got(%+v) != want(nil)", err)
+	}
+	cli2, err := p.Get()
+	if err != nil {
+		t.Fatalf("err: got(%+v) != want(nil)", err)
+	}
+	if cli1 == cli2 {
+		t.Errorf("clients: got the same client, want different ones")
+	}
+	p.Put(cli1)
+	p.Put(cli2)
+
+	if err := p.CloseAll(); err != nil {
+		t.Errorf("err: got(%+v) != want(nil)", err)
+	}
+	if _, err := p.Get(); err == nil {
+		t.Errorf("err: got(nil) != want(pool closed)")
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	var (
+		down  = true
+		calls int
+	)
+	s := &mockServer{
+		addr: "server1",
+		insertFn: func(slotID int, key, member string, timestamp int64, ttl time.Duration) (bool, error) {
+			calls++
+			if down {
+				return false, status.Error(codes.Unavailable, "down")
+			}
+			return true, nil
+		},
+		selectFn: func(slotID int, key string, timestamp int64) ([]common.Element, error) {
+			return []common.Element{{Member: "server1"}}, nil
+		},
+	}
+	other := &mockServer{
+		addr: "server2",
+		selectFn: func(slotID int, key string, timestamp int64) ([]common.Element, error) {
+			return []common.Element{{Member: "server2"}}, nil
+		},
+	}
+	b := group.NewBreaker(s, 2, 50*time.Millisecond)
+	g, _ := group.NewGroup(0, []group.Server{b, other}, 1, group.ReadPrimary)
+
+	// The server is ejected after 2 failures, and then fails fast.
+	for i := 0; i < 3; i++ {
+		b.Insert(context.Background(), 0, "key", "member", 0, 0)
+	}
+	if calls != 2 {
+		t.Errorf("calls: got(%+v) != want(2)", calls)
+	}
+	if state := g.Health()[0].State; state != group.Unhealthy {
+		t.Errorf("state: got(%+v) != want(%+v)", state, group.Unhealthy)
+	}
+
+	// The unhealthy server is excluded from the reads.
+	elements, err := g.Select(context.Background(), 0, "key", 0)
+	want := []common.Element{{Member: "server2"}}
+	if err != nil || !reflect.DeepEqual(elements, want) {
+		t.Errorf("elements: got(%+v, %+v) != want(%+v, nil)", elements, err, want)
+	}
+
+	// The trial request after the cooldown recovers the server.
+	down = false
+	time.Sleep(60 * time.Millisecond)
+	if _, err := b.Insert(context.Background(), 0, "key", "member", 0, 0); err != nil {
+		t.Errorf("err: got(%+v) != want(nil)", err)
+	}
+	if state := g.Health()[0].State; state != group.Healthy {
+		t.Errorf("state: got(%+v) != want(%+v)", state, group.Healthy)
+	}
+}
+
+// blockingServer is a group.Server whose writes block until canceled.
+type blockingServer struct {
+	group.Server
+}
+
+func (s *blockingServer) Insert(ctx context.Context, slotID int, key, member string, timestamp int64, ttl time.Duration) (bool, error) {
+	<-ctx.Done()
+	return false, ctx.Err()
+}
+
+func TestGroup_Insert_Canceled(t *testing.T) {
+	servers := []group.Server{
+		&mockServer{addr: "server1"},
+		&blockingServer{Server: &mockServer{addr: "server2"}},
+	}
+	g, _ := group.NewGroup(0, servers, 2, group.ReadPrimary)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := g.Insert(ctx, 0, "key", "member", 0, 0)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !group.IsNoQuorum(err) {
+			t.Errorf("err: got(%+v) != want(no quorum)", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("the write is not canceled")
+	}
+}
+
+func TestGroup_BatchInsert(t *testing.T) {
+	newServer := func(addr string, failed ...string) group.Server {
+		return &mockServer{
+			addr: addr,
+			insertFn: func(slotID int, key, member string, timestamp int64, ttl time.Duration) (bool, error) {
+				for _, m := range failed {
+					if m == member {
+						return false, fmt.Errorf("%s: failed to insert %s", addr, member)
+					}
+				}
+				return true, nil
+			},
+		}
+	}
+	ops := []group.Op{
+		{Key: "key", Member: "member1"},
+		{Key: "key", Member: "member2"},
+	}
+
+	cases := []struct {
+		servers    []group.Server
+		wantQuorum []bool
+	}{
+		{
+			servers: []group.Server{
+				newServer("server1"),
+				newServer("server2"),
+				newServer("server3", "member2"),
+			},
+			wantQuorum: []bool{true, true},
+		},
+		{
+			servers: []group.Server{
+				newServer("server1"),
+				newServer("server2", "member2"),
+				newServer("server3", "member2"),
+			},
+			wantQuorum: []bool{true, false},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run("", func(t *testing.T) {
+			g, _ := group.NewGroup(0, c.servers, 2, group.ReadPrimary)
+			results := g.BatchInsert(context.Background(), ops)
+			if len(results) != len(ops) {
+				t.Fatalf("results: got(%d) != want(%d)", len(results), len(ops))
+			}
+			for i, r := range results {
+				if quorum := r.Err == nil; quorum != c.wantQuorum[i] {
+					t.Errorf("quorum of #%d: got(%+v) != want(%+v)", i, quorum, c.wantQuorum[i])
+				}
+				if r.Err != nil && !group.IsNoQuorum(r.Err) {
+					t.Errorf("err of #%d: got(%+v) != want(no quorum)", i, r.Err)
+				}
+				if r.Err == nil && !r.Result.Status() {
+					t.Errorf("status of #%d: got(false) != want(true)", i)
+				}
+			}
+		})
+	}
+}
+
+func TestGroup_MultiSelect(t *testing.T) {
+	servers := []group.Server{
+		&mockServer{
+			addr: "server1",
+			selectFn: func(slotID int, key string, timestamp int64) ([]common.Element, error) {
+				if key == "key2" {
+					return nil, fmt.Errorf("server1: failed to select %s", key)
+				}
+				return []common.Element{{Member: "server1"}}, nil
+			},
+		},
+		&mockServer{
+			addr: "server2",
+			selectFn: func(slotID int, key string, timestamp int64) ([]common.Element, error) {
+				return []common.Element{{Member: "server2"}}, nil
+			},
+		},
+	}
+	g, _ := group.NewGroup(0, servers, 1, group.ReadPrimary)
+
+	// Only the failed items are retried on the next server.
+	results := g.MultiSelect(context.Background(), []group.Op{{Key: "key1"}, {Key: "key2"}})
+	want := []group.SelectResult{
+		{Elements: []common.Element{{Member: "server1"}}},
+		{Elements: []common.Element{{Member: "server2"}}},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("results: got(%+v) != want(%+v)", results, want)
+	}
+}
+
+func TestGroup_HedgedSelect(t *testing.T) {
+	var slow int32
+	servers := []group.Server{
+		&mockServer{
+			addr: "server1",
+			selectFn: func(slotID int, key string, timestamp int64) ([]common.Element, error) {
+				if atomic.LoadInt32(&slow) == 1 {
+					time.Sleep(time.Second)
+				} else {
+					time.Sleep(time.Millisecond)
+				}
+				return []common.Element{{Member: "server1"}}, nil
+			},
+		},
+		&mockServer{
+			addr: "server2",
+			selectFn: func(slotID int, key string, timestamp int64) ([]common.Element, error) {
+				return []common.Element{{Member: "server2"}}, nil
+			},
+		},
+	}
+	g, _ := group.NewGroup(0, servers, 1, group.ReadPrimary)
+	g.SetHedge(90)
+
+	// Warm up the latencies of server1.
+	for i := 0; i < 20; i++ {
+		g.Select(context.Background(), 0, "key", 0)
+	}
+	before := g.HedgeStats()
+
+	// The hedged read to server2 wins over the slow server1.
+	atomic.StoreInt32(&slow, 1)
+	start := time.Now()
+	elements, err := g.Select(context.Background(), 0, "key", 0)
+	want := []common.Element{{Member: "server2"}}
+	if err != nil || !reflect.DeepEqual(elements, want) {
+		t.Errorf("elements: got(%+v, %+v) != want(%+v, nil)", elements, err, want)
+	}
+	if d := time.Since(start); d >= time.Second {
+		t.Errorf("latency: got(%s) >= want(1s)", d)
+	}
+	if stats := g.HedgeStats(); stats.Sent != before.Sent+1 || stats.Won != before.Won+1 {
+		t.Errorf("stats: got(%+v) != want(one more sent and won than %+v)", stats, before)
+	}
+}
@@ -0,0 +1,355 @@
+package group
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/RussellLuo/goku/cluster"
+	"github.com/RussellLuo/goku/common"
+	"github.com/RussellLuo/goku/internal/worker"
+)
+
+// HealthState is the health state of a replica, which follows the states of
+// a circuit breaker.
+type HealthState int
+
+const (
+	// Healthy replicas serve all the requests (i.e. the closed circuit).
+	Healthy HealthState = iota
+	// Unhealthy replicas are ejected, and the requests to them fail fast
+	// until the cooldown elapses (i.e. the open circuit).
+	Unhealthy
+	// Probing replicas are ejected, except for a single trial request,
+	// whose outcome decides the next state (i.e. the half-open circuit).
+	Probing
+)
+
+func (s HealthState) String() string {
+	switch s {
+	case Healthy:
+		return "healthy"
+	case Unhealthy:
+		return "unhealthy"
+	case Probing:
+		return "probing"
+	default:
+		return "unknown"
+	}
+}
+
+// Checker is the server whose health can be checked actively.
+type Checker interface {
+	Check() error
+}
+
+// ReplicaHealth is the health of a replica.
+type ReplicaHealth struct {
+	Addr  string
+	State HealthState
+	// The number of the consecutive failures.
+	Failures int
+	// The last failure, which is nil if there is none.
+	LastErr error
+	// The time when the replica entered the current state.
+	Since time.Time
+}
+
+// breaker wraps a server with a circuit breaker. A healthy server that
+// fails threshold consecutive times is ejected for cooldown, after which a
+// single trial request (or probe) is let through to decide whether the
+// server is healthy again.
+//
+// Only the failures of the transport (i.e. an unavailable server or a
+// timeout) are counted, while the errors returned by a live server are not.
+type breaker struct {
+	Server
+
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    HealthState
+	failures int
+	lastErr  error
+	since    time.Time
+	retryAt  time.Time
+}
+
+// NewBreaker wraps s with a circuit breaker, which ejects s after threshold
+// consecutive failures for cooldown.
+func NewBreaker(s Server, threshold int, cooldown time.Duration) *breaker {
+	return &breaker{
+		Server:    s,
+		threshold: threshold,
+		cooldown:  cooldown,
+		since:     time.Now(),
+	}
+}
+
+// Health returns the health of the server.
+func (b *breaker) Health() ReplicaHealth {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return ReplicaHealth{
+		Addr:     b.Addr(),
+		State:    b.state,
+		Failures: b.failures,
+		LastErr:  b.lastErr,
+		Since:    b.since,
+	}
+}
+
+// Probe checks the health of the server actively, if it is a Checker. The
+// probes are counted as the requests, which means that a dead server can be
+// ejected without any traffic, and an ejected one can be recovered by a
+// probe as the trial request.
+func (b *breaker) Probe() {
+	c, ok := b.Server.(Checker)
+	if !ok {
+		return
+	}
+	if err := b.allow(); err != nil {
+		return
+	}
+	b.record(context.Background(), c.Check())
+}
+
+// allow reports whether a request can be sent to the server. The first
+// request after the cooldown becomes the trial request.
+func (b *breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case Unhealthy:
+		if time.Now().Before(b.retryAt) {
+			return status.Errorf(codes.Unavailable, "%s is unhealthy", b.Addr())
+		}
+		b.setState(Probing)
+		return nil
+	case Probing:
+		return status.Errorf(codes.Unavailable, "%s is being probed", b.Addr())
+	default:
+		return nil
+	}
+}
+
+// record records the outcome of a request bound to ctx. The request that
+// is abandoned by the caller is not counted.
+func (b *breaker) record(ctx context.Context, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if ctx.Err() != nil {
+		if b.state == Probing {
+			// Let the next request be the trial.
+			b.setState(Unhealthy)
+			b.retryAt = time.Now()
+		}
+		return
+	}
+
+	if !isFailure(err) {
+		b.failures = 0
+		if b.state == Probing {
+			b.setState(Healthy)
+			log.Printf("%s is healthy again", b.Addr())
+		}
+		return
+	}
+
+	b.failures++
+	b.lastErr = err
+	if b.state == Probing || (b.state == Healthy && b.failures >= b.threshold) {
+		b.setState(Unhealthy)
+		b.retryAt = time.Now().Add(b.cooldown)
+		log.Printf("%s is ejected after %d failures: %v", b.Addr(), b.failures, err)
+	}
+}
+
+func (b *breaker) setState(state HealthState) {
+	b.state = state
+	b.since = time.Now()
+}
+
+// isFailure reports whether err indicates that the server is unavailable.
+func isFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+func (b *breaker) Insert(ctx context.Context, slotID int, key, member string, timestamp int64, ttl time.Duration) (bool, error) {
+	if err := b.allow(); err != nil {
+		return false, err
+	}
+	ok, err := b.Server.Insert(ctx, slotID, key, member, timestamp, ttl)
+	b.record(ctx, err)
+	return ok, err
+}
+
+func (b *breaker) Delete(ctx context.Context, slotID int, key, member string, timestamp int64) (bool, error) {
+	if err := b.allow(); err != nil {
+		return false, err
+	}
+	ok, err := b.Server.Delete(ctx, slotID, key, member, timestamp)
+	b.record(ctx, err)
+	return ok, err
+}
+
+func (b *breaker) Select(ctx context.Context, slotID int, key string, timestamp int64) ([]common.Element, error) {
+	if err := b.allow(); err != nil {
+		return nil, err
+	}
+	elements, err := b.Server.Select(ctx, slotID, key, timestamp)
+	b.record(ctx, err)
+	return elements, err
+}
+
+func (b *breaker) BatchInsert(ctx context.Context, ops []Op) ([]OpResult, error) {
+	if err := b.allow(); err != nil {
+		return nil, err
+	}
+	results, err := batchInsert(ctx, b.Server, ops)
+	b.record(ctx, err)
+	return results, err
+}
+
+func (b *breaker) BatchDelete(ctx context.Context, ops []Op) ([]OpResult, error) {
+	if err := b.allow(); err != nil {
+		return nil, err
+	}
+	results, err := batchDelete(ctx, b.Server, ops)
+	b.record(ctx, err)
+	return results, err
+}
+
+func (b *breaker) MultiSelect(ctx context.Context, ops []Op) ([]SelectResult, error) {
+	if err := b.allow(); err != nil {
+		return nil, err
+	}
+	results, err := multiSelect(ctx, b.Server, ops)
+	b.record(ctx, err)
+	return results, err
+}
+
+func (b *breaker) Dump(ctx context.Context, slotID int, key string, timestamp int64) ([]common.Element, error) {
+	if err := b.allow(); err != nil {
+		return nil, err
+	}
+	elements, err := b.Server.Dump(ctx, slotID, key, timestamp)
+	b.record(ctx, err)
+	return elements, err
+}
+
+func (b *breaker) Purge(ctx context.Context, slotID int, key string, elements []common.Element) (int, error) {
+	if err := b.allow(); err != nil {
+		return 0, err
+	}
+	n, err := b.Server.Purge(ctx, slotID, key, elements)
+	b.record(ctx, err)
+	return n, err
+}
+
+func (b *breaker) ImportSlot(slotID int, source, cursor string) (string, error) {
+	if err := b.allow(); err != nil {
+		return cursor, err
+	}
+	next, err := b.Server.ImportSlot(slotID, source, cursor)
+	b.record(context.Background(), err)
+	return next, err
+}
+
+func (b *breaker) DropSlot(slotID int) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+	err := b.Server.DropSlot(slotID)
+	b.record(context.Background(), err)
+	return err
+}
+
+func (b *breaker) Tree(slotID int, nodes []int) ([]uint64, int, error) {
+	if err := b.allow(); err != nil {
+		return nil, 0, err
+	}
+	hashes, leaves, err := b.Server.Tree(slotID, nodes)
+	b.record(context.Background(), err)
+	return hashes, leaves, err
+}
+
+// healthReporter is the server that reports its health.
+type healthReporter interface {
+	Health() ReplicaHealth
+}
+
+// prober is the server that can be probed by the HealthChecker.
+type prober interface {
+	Probe()
+}
+
+// health returns the health of s, which is always healthy if s does not
+// report its health.
+func health(s Server) ReplicaHealth {
+	if r, ok := s.(healthReporter); ok {
+		return r.Health()
+	}
+	return ReplicaHealth{Addr: s.Addr(), State: Healthy}
+}
+
+// Health returns the health of all the servers in the group.
+func (g *group) Health() []ReplicaHealth {
+	replicas := make([]ReplicaHealth, len(g.servers))
+	for i, s := range g.servers {
+		replicas[i] = health(s)
+	}
+	return replicas
+}
+
+// HealthChecker is a background worker that periodically probes the
+// servers of all the groups in a cluster.
+type HealthChecker struct {
+	*worker.Ticker
+
+	cluster *cluster.Cluster
+}
+
+// NewHealthChecker creates a HealthChecker, which probes the servers in c
+// every interval.
+func NewHealthChecker(c *cluster.Cluster, interval time.Duration) *HealthChecker {
+	h := &HealthChecker{cluster: c}
+	h.Ticker = worker.NewTicker(interval, h.Probe)
+	return h
+}
+
+// Probe probes all the servers, which can be probed, once concurrently.
+func (h *HealthChecker) Probe() {
+	var wg sync.WaitGroup
+	for _, cg := range h.cluster.Groups() {
+		g, ok := cg.(*group)
+		if !ok {
+			continue
+		}
+		for _, s := range g.servers {
+			p, ok := s.(prober)
+			if !ok {
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				p.Probe()
+			}()
+		}
+	}
+	wg.Wait()
+}
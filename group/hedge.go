@@ -0,0 +1,92 @@
+package group
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// The number of the recent reads, whose latencies are tracked per replica,
+// and the least number of them to estimate a percentile.
+const (
+	latencySamples    = 128
+	minLatencySamples = 16
+)
+
+// latency tracks the latencies of the recent successful reads from a
+// replica in a ring.
+type latency struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+func newLatency() *latency {
+	return &latency{samples: make([]time.Duration, 0, latencySamples)}
+}
+
+func (l *latency) add(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.samples) < latencySamples {
+		l.samples = append(l.samples, d)
+		return
+	}
+	l.samples[l.next] = d
+	l.next = (l.next + 1) % latencySamples
+}
+
+// percentile returns the p-th percentile of the tracked latencies. It also
+// reports whether there are enough samples for the estimation.
+func (l *latency) percentile(p float64) (time.Duration, bool) {
+	l.mu.Lock()
+	samples := append([]time.Duration(nil), l.samples...)
+	l.mu.Unlock()
+
+	if len(samples) < minLatencySamples {
+		return 0, false
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	i := int(p / 100 * float64(len(samples)))
+	if i >= len(samples) {
+		i = len(samples) - 1
+	}
+	return samples[i], true
+}
+
+// HedgeStats is the counters of the hedged reads of a group.
+type HedgeStats struct {
+	// The number of the hedged requests that have been sent.
+	Sent uint64
+	// The number of the hedged requests that have answered first.
+	Won uint64
+}
+
+// SetHedge enables the hedged reads of the non-quorum read strategies. If
+// the first replica has not answered a read within the percentile-th
+// percentile of its recent latencies, the read is also sent to the next
+// replica, and the first successful reply wins. The hedged reads are
+// disabled if percentile is not positive, which is the default. It must be
+// called before the group is used.
+func (g *group) SetHedge(percentile float64) {
+	g.hedgePercentile = percentile
+}
+
+// HedgeStats returns the counters of the hedged reads.
+func (g *group) HedgeStats() HedgeStats {
+	return HedgeStats{
+		Sent: atomic.LoadUint64(&g.hedgeStats.Sent),
+		Won:  atomic.LoadUint64(&g.hedgeStats.Won),
+	}
+}
+
+// hedgeDelay returns the delay before hedging a read sent to s. It also
+// reports whether the read should be hedged.
+func (g *group) hedgeDelay(s Server) (time.Duration, bool) {
+	if g.hedgePercentile <= 0 {
+		return 0, false
+	}
+	return g.latencies[s.Addr()].percentile(g.hedgePercentile)
+}
@@ -0,0 +1,383 @@
+package group
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/boltdb/bolt"
+
+	"github.com/RussellLuo/goku/cluster"
+	"github.com/RussellLuo/goku/internal/worker"
+)
+
+// ErrHintsFull is returned when a hint is added to a full HintStore.
+var ErrHintsFull = errors.New("hints full")
+
+// The operations of the hints.
+const (
+	hintInsert = "insert"
+	hintDelete = "delete"
+)
+
+// Hint is a write that has failed on a server, which is kept to be handed
+// off to the server once it is reachable again.
+type Hint struct {
+	Op        string        `json:"op"`
+	SlotID    int           `json:"slot_id"`
+	Key       string        `json:"key"`
+	Member    string        `json:"member"`
+	Timestamp int64         `json:"timestamp"`
+	TTL       time.Duration `json:"ttl,omitempty"`
+	// The time when the hint was created.
+	Created int64 `json:"created"`
+}
+
+// apply applies the hint to s.
+func (h *Hint) apply(ctx context.Context, s Server) (err error) {
+	switch h.Op {
+	case hintInsert:
+		_, err = s.Insert(ctx, h.SlotID, h.Key, h.Member, h.Timestamp, h.TTL)
+	case hintDelete:
+		_, err = s.Delete(ctx, h.SlotID, h.Key, h.Member, h.Timestamp)
+	}
+	return err
+}
+
+// SetHints sets the store of the hints for the failed writes, which are
+// discarded by default. It must be called before the group is used.
+func (g *group) SetHints(hints *HintStore) {
+	g.hints = hints
+}
+
+// HintStats is the counters of a HintStore.
+type HintStats struct {
+	// The number of the hints that are waiting to be handed off.
+	Backlog uint64
+	// The number of the hints that have been handed off.
+	Delivered uint64
+	// The number of the hints that have expired before being handed off.
+	Expired uint64
+	// The number of the hints that have been dropped since the store is
+	// full.
+	Dropped uint64
+}
+
+// HintStore keeps the hints on the disk, by using BoltDB, where the hints
+// of each server are kept in a separate bucket in their creation order.
+//
+// The number of the hints is bounded, and the hints older than the window
+// expire, since a server that has been down for long should rather be
+// repaired by the anti-entropy.
+type HintStore struct {
+	stats HintStats // The first field to ensure its 64-bit alignment.
+
+	db       *bolt.DB
+	maxHints int
+	window   time.Duration
+
+	mu      sync.Mutex
+	backlog map[string]int // The number of hints per server.
+}
+
+// OpenHintStore opens the hint store at path, which will be created if it
+// does not exist. At most maxHints hints are kept, and a hint expires after
+// window.
+func OpenHintStore(path string, maxHints int, window time.Duration) (*HintStore, error) {
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	h := &HintStore{
+		db:       db,
+		maxHints: maxHints,
+		window:   window,
+		backlog:  make(map[string]int),
+	}
+	err = db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			n := b.Stats().KeyN
+			h.backlog[string(name)] = n
+			h.stats.Backlog += uint64(n)
+			return nil
+		})
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return h, nil
+}
+
+// Close closes the store.
+func (h *HintStore) Close() error {
+	return h.db.Close()
+}
+
+// Stats returns the counters of the store.
+func (h *HintStore) Stats() HintStats {
+	return HintStats{
+		Backlog:   atomic.LoadUint64(&h.stats.Backlog),
+		Delivered: atomic.LoadUint64(&h.stats.Delivered),
+		Expired:   atomic.LoadUint64(&h.stats.Expired),
+		Dropped:   atomic.LoadUint64(&h.stats.Dropped),
+	}
+}
+
+// Backlog returns the number of the hints of the server at addr.
+func (h *HintStore) Backlog(addr string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.backlog[addr]
+}
+
+// Add adds the hint for the server at addr. It returns ErrHintsFull if
+// there are already maxHints hints.
+func (h *HintStore) Add(addr string, hint Hint) error {
+	if hint.Created == 0 {
+		hint.Created = time.Now().UnixNano()
+	}
+	v, err := json.Marshal(hint)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if int(atomic.LoadUint64(&h.stats.Backlog)) >= h.maxHints {
+		atomic.AddUint64(&h.stats.Dropped, 1)
+		return ErrHintsFull
+	}
+
+	err = h.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(addr))
+		if err != nil {
+			return err
+		}
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		var k [8]byte
+		binary.BigEndian.PutUint64(k[:], seq)
+		return b.Put(k[:], v)
+	})
+	if err != nil {
+		return err
+	}
+
+	h.backlog[addr]++
+	atomic.AddUint64(&h.stats.Backlog, 1)
+	return nil
+}
+
+// Deliver hands off the hints of s to s in their creation order, and stops
+// at the first failure, which typically means that s is still unreachable.
+// The expired hints are dropped. It returns the number of the delivered
+// hints.
+func (h *HintStore) Deliver(s Server) (int, error) {
+	addr := s.Addr()
+	if h.Backlog(addr) == 0 {
+		return 0, nil
+	}
+
+	delivered := 0
+	for {
+		// Deliver the hints in batches, without holding any transaction
+		// during the delivery.
+		type entry struct {
+			k    []byte
+			hint Hint
+		}
+		var entries []entry
+		err := h.db.View(func(tx *bolt.Tx) error {
+			b := tx.Bucket([]byte(addr))
+			if b == nil {
+				return nil
+			}
+			c := b.Cursor()
+			for k, v := c.First(); k != nil && len(entries) < hintBatchSize; k, v = c.Next() {
+				var e entry
+				if err := json.Unmarshal(v, &e.hint); err != nil {
+					return err
+				}
+				e.k = append([]byte(nil), k...)
+				entries = append(entries, e)
+			}
+			return nil
+		})
+		if err != nil || len(entries) == 0 {
+			return delivered, err
+		}
+
+		var (
+			done      [][]byte
+			expired   int
+			failure   error
+			expiredAt = time.Now().Add(-h.window).UnixNano()
+		)
+		for _, e := range entries {
+			if e.hint.Created < expiredAt {
+				expired++
+			} else if failure = e.hint.apply(context.Background(), s); failure != nil {
+				break
+			}
+			done = append(done, e.k)
+		}
+
+		if err := h.remove(addr, done); err != nil {
+			return delivered, err
+		}
+		atomic.AddUint64(&h.stats.Expired, uint64(expired))
+		atomic.AddUint64(&h.stats.Delivered, uint64(len(done)-expired))
+		delivered += len(done) - expired
+
+		if failure != nil {
+			return delivered, failure
+		}
+	}
+}
+
+// Addrs returns the addresses of the servers that have hints.
+func (h *HintStore) Addrs() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var addrs []string
+	for addr, n := range h.backlog {
+		if n > 0 {
+			addrs = append(addrs, addr)
+		}
+	}
+	sort.Strings(addrs)
+	return addrs
+}
+
+// Expire drops the expired hints of the server at addr, without delivering
+// any hint. It returns the number of the dropped hints.
+func (h *HintStore) Expire(addr string) (int, error) {
+	expiredAt := time.Now().Add(-h.window).UnixNano()
+
+	var keys [][]byte
+	err := h.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(addr))
+		if b == nil {
+			return nil
+		}
+		// The hints are in their creation order.
+		c := b.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			var hint Hint
+			if err := json.Unmarshal(v, &hint); err != nil {
+				return err
+			}
+			if hint.Created >= expiredAt {
+				break
+			}
+			keys = append(keys, append([]byte(nil), k...))
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := h.remove(addr, keys); err != nil {
+		return 0, err
+	}
+	atomic.AddUint64(&h.stats.Expired, uint64(len(keys)))
+	return len(keys), nil
+}
+
+// remove removes the hints at keys of the server at addr.
+func (h *HintStore) remove(addr string, keys [][]byte) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	err := h.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(addr))
+		if b == nil {
+			return nil
+		}
+		for _, k := range keys {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	h.backlog[addr] -= len(keys)
+	atomic.AddUint64(&h.stats.Backlog, ^uint64(len(keys)-1))
+	return nil
+}
+
+// The number of hints delivered in a batch.
+const hintBatchSize = 100
+
+// Handoff is a background worker that periodically hands off the hints to
+// the servers of all the groups in a cluster.
+type Handoff struct {
+	*worker.Ticker
+
+	cluster *cluster.Cluster
+	hints   *HintStore
+}
+
+// NewHandoff creates a Handoff, which hands off the hints in hints to the
+// servers in c every interval.
+func NewHandoff(c *cluster.Cluster, hints *HintStore, interval time.Duration) *Handoff {
+	h := &Handoff{cluster: c, hints: hints}
+	h.Ticker = worker.NewTicker(interval, func() { h.Handoff() })
+	return h
+}
+
+// Handoff hands off the hints once. It returns the number of the delivered
+// hints.
+//
+// The hints of the servers that are no longer in any group can never be
+// delivered, which are dropped once expired, rather than being kept
+// counting toward maxHints.
+func (h *Handoff) Handoff() int {
+	total := 0
+	serving := make(map[string]bool)
+	for _, cg := range h.cluster.Groups() {
+		g, ok := cg.(*group)
+		if !ok {
+			continue
+		}
+		for _, s := range g.servers {
+			serving[s.Addr()] = true
+			n, err := h.hints.Deliver(s)
+			if err != nil {
+				log.Printf("failed to hand off hints to %s: %v", s.Addr(), err)
+			}
+			total += n
+		}
+	}
+
+	for _, addr := range h.hints.Addrs() {
+		if serving[addr] {
+			continue
+		}
+		if _, err := h.hints.Expire(addr); err != nil {
+			log.Printf("failed to expire hints of %s: %v", addr, err)
+		}
+	}
+	return total
+}
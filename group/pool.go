@@ -1,43 +1,223 @@
 package group
 
 import (
+	"context"
+	"errors"
 	"sync"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 
 	"github.com/RussellLuo/goku/group/pb"
+	"github.com/RussellLuo/goku/internal/worker"
 )
 
+// The bounds of the backoff before reconnecting to a failed server.
+const (
+	minBackoff = 100 * time.Millisecond
+	maxBackoff = 10 * time.Second
+)
+
+var errPoolClosed = errors.New("pool closed")
+
+// pool is a pool of at most size connections to a server. Since a gRPC
+// connection multiplexes the concurrent calls, the connections are shared
+// by the callers in turn, rather than being checked out exclusively.
+//
+// A broken connection is closed, and is reconnected with an exponential
+// backoff, within which the callers fail fast. A connection that has not
+// been used for idleTimeout is closed, and will be reconnected on demand.
 type pool struct {
-	mu   sync.Mutex
-	addr string
-	cli  pb.GokuServerClient
+	addr        string
+	idleTimeout time.Duration
+	janitor     *worker.Ticker
+
+	mu     sync.Mutex
+	conns  []*poolConn
+	next   int
+	closed bool
 }
 
-func NewPool(addr string) *pool {
-	return &pool{addr: addr}
+type poolConn struct {
+	conn     *grpc.ClientConn
+	cli      pb.GokuServerClient
+	inUse    int
+	lastUsed time.Time
+
+	// The consecutive failures, and the time to reconnect after.
+	failures int
+	retryAt  time.Time
+}
+
+// NewPool creates a pool of at most size connections to the server at addr,
+// whose idle connections will be closed after idleTimeout.
+func NewPool(addr string, size int, idleTimeout time.Duration) *pool {
+	if size <= 0 {
+		size = 1
+	}
+
+	p := &pool{
+		addr:        addr,
+		idleTimeout: idleTimeout,
+		conns:       make([]*poolConn, size),
+	}
+	for i := range p.conns {
+		p.conns[i] = &poolConn{}
+	}
+
+	if idleTimeout > 0 {
+		p.janitor = worker.NewTicker(idleTimeout/2, p.evict)
+		p.janitor.Start()
+	}
+	return p
 }
 
 func (p *pool) Get() (pb.GokuServerClient, error) {
+	pc, err := p.acquire()
+	if err != nil {
+		return nil, err
+	}
+	return pc.cli, nil
+}
+
+// Check checks the health of the server by the gRPC health protocol, over
+// one of the pooled connections.
+func (p *pool) Check(ctx context.Context) error {
+	pc, err := p.acquire()
+	if err != nil {
+		return err
+	}
+	defer p.release(pc)
+
+	reply, err := healthpb.NewHealthClient(pc.conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		return err
+	}
+	if reply.Status != healthpb.HealthCheckResponse_SERVING {
+		return status.Errorf(codes.Unavailable, "%s is %s", p.addr, reply.Status)
+	}
+	return nil
+}
+
+// acquire returns the next connection in turn, which is reconnected if
+// broken, and must be released after use.
+func (p *pool) acquire() (*poolConn, error) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	if p.cli == nil {
+	if p.closed {
+		return nil, errPoolClosed
+	}
+
+	now := time.Now()
+	pc := p.conns[p.next]
+	p.next = (p.next + 1) % len(p.conns)
+
+	if pc.conn != nil {
+		switch pc.conn.GetState() {
+		case connectivity.Ready:
+			pc.failures = 0
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			pc.conn.Close()
+			pc.conn, pc.cli = nil, nil
+			pc.failures++
+			pc.retryAt = now.Add(backoff(pc.failures))
+		}
+	}
+
+	if pc.conn == nil {
+		if now.Before(pc.retryAt) {
+			return nil, status.Errorf(codes.Unavailable, "%s is unavailable, retry in %s", p.addr, pc.retryAt.Sub(now))
+		}
 		conn, err := grpc.Dial(p.addr, grpc.WithInsecure())
 		if err != nil {
-			return nil, err
+			pc.failures++
+			pc.retryAt = now.Add(backoff(pc.failures))
+			return nil, status.Errorf(codes.Unavailable, "failed to dial %s: %v", p.addr, err)
 		}
-		p.cli = pb.NewGokuServerClient(conn)
+		pc.conn = conn
+		pc.cli = pb.NewGokuServerClient(conn)
 	}
 
-	return p.cli, nil
+	pc.inUse++
+	pc.lastUsed = now
+	return pc, nil
+}
+
+func (p *pool) release(pc *poolConn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if pc.inUse > 0 {
+		pc.inUse--
+		pc.lastUsed = time.Now()
+	}
 }
 
 func (p *pool) Put(cli pb.GokuServerClient) {
-	// TODO: actually put back client.
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, pc := range p.conns {
+		if pc.cli == cli && pc.inUse > 0 {
+			pc.inUse--
+			pc.lastUsed = time.Now()
+			return
+		}
+	}
+}
+
+// evict closes the connections that have been idle for p.idleTimeout.
+func (p *pool) evict() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	deadline := time.Now().Add(-p.idleTimeout)
+	for _, pc := range p.conns {
+		if pc.conn != nil && pc.inUse == 0 && pc.lastUsed.Before(deadline) {
+			pc.conn.Close()
+			pc.conn, pc.cli = nil, nil
+		}
+	}
 }
 
+// CloseAll closes all the connections, after which the pool can no longer
+// be used.
 func (p *pool) CloseAll() error {
-	// TODO: actually close all clients.
-	return nil
+	if p.janitor != nil {
+		p.janitor.Stop()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+	var firstErr error
+	for _, pc := range p.conns {
+		if pc.conn == nil {
+			continue
+		}
+		if err := pc.conn.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		pc.conn, pc.cli = nil, nil
+	}
+	return firstErr
+}
+
+// backoff returns the backoff after the given number of the consecutive
+// failures, which doubles on every failure.
+func backoff(failures int) time.Duration {
+	d := minBackoff
+	for i := 1; i < failures && d < maxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+	return d
 }
@@ -0,0 +1,217 @@
+package group
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/RussellLuo/goku/common"
+)
+
+// The read strategies of a group.
+const (
+	// ReadPrimary reads the first server, or the next one if it fails.
+	ReadPrimary = "primary"
+	// ReadRoundRobin reads the servers in turn.
+	ReadRoundRobin = "round-robin"
+	// ReadRandom reads a random server.
+	ReadRandom = "random"
+	// ReadQuorum reads R servers, where R + writeQuorum > len(servers), and
+	// merges their results by the LWW timestamps. It is the only strategy
+	// that always sees the latest successful writes.
+	ReadQuorum = "quorum"
+)
+
+// replica is the set dumped from a server.
+type replica struct {
+	server   Server
+	elements []common.Element
+	err      error
+}
+
+// merge merges the elements dumped from the replicas by their timestamps.
+func merge(replicas []replica) map[string]common.Element {
+	merged := make(map[string]common.Element)
+	for _, r := range replicas {
+		for _, e := range r.elements {
+			if old, ok := merged[e.Member]; !ok || newer(e, old) {
+				merged[e.Member] = e
+			}
+		}
+	}
+	return merged
+}
+
+// readQuorum returns the number of servers that a quorum read needs, which
+// ensures that the read overlaps with every successful write.
+func (g *group) readQuorum() int {
+	return len(g.voters) - g.writeQuorum + 1
+}
+
+// candidates returns the healthy servers, which are not joining, in the
+// order to be read, which starts from the server chosen according to
+// g.readStrategy.
+func (g *group) candidates() []Server {
+	n := len(g.voters)
+	if n == 0 {
+		return nil
+	}
+
+	var start int
+	switch g.readStrategy {
+	case ReadRoundRobin:
+		start = int((atomic.AddUint64(&g.reads, 1) - 1) % uint64(n))
+	case ReadRandom:
+		start = rand.Intn(n)
+	}
+
+	servers := make([]Server, 0, n)
+	for i := 0; i < n; i++ {
+		if s := g.voters[(start+i)%n]; health(s).State == Healthy {
+			servers = append(servers, s)
+		}
+	}
+	return servers
+}
+
+// selectOne reads one server chosen according to g.readStrategy. If the
+// server fails, the following servers are tried in turn. The unhealthy
+// servers are skipped.
+//
+// If the hedged reads are enabled, and the first server has not answered
+// within the hedge delay, the next server is also read. The first successful
+// reply wins, and the other read is canceled.
+func (g *group) selectOne(ctx context.Context, slotID int, key string, timestamp int64) ([]common.Element, error) {
+	if len(g.voters) == 0 {
+		return nil, fmt.Errorf("no server in group %d", g.id)
+	}
+	candidates := g.candidates()
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no healthy server in group %d", g.id)
+	}
+
+	// Cancel the losing read once there is a winner.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		elements []common.Element
+		err      error
+		hedged   bool
+	}
+	resultChan := make(chan result, len(candidates))
+	next, inflight := 0, 0
+	read := func(hedged bool) {
+		s := candidates[next]
+		next++
+		inflight++
+		go func() {
+			start := time.Now()
+			elements, err := s.Select(ctx, slotID, key, timestamp)
+			if err == nil {
+				g.latencies[s.Addr()].add(time.Since(start))
+			}
+			resultChan <- result{elements: elements, err: err, hedged: hedged}
+		}()
+	}
+
+	var hedgeC <-chan time.Time
+	if delay, ok := g.hedgeDelay(candidates[0]); ok && len(candidates) > 1 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		hedgeC = timer.C
+	}
+	read(false)
+
+	var err error
+	for inflight > 0 {
+		select {
+		case r := <-resultChan:
+			inflight--
+			if r.err == nil {
+				if r.hedged {
+					atomic.AddUint64(&g.hedgeStats.Won, 1)
+				}
+				return r.elements, nil
+			}
+			err = r.err
+			// Fail over to the next server.
+			if inflight == 0 && next < len(candidates) {
+				read(false)
+			}
+		case <-hedgeC:
+			if next < len(candidates) {
+				atomic.AddUint64(&g.hedgeStats.Sent, 1)
+				read(true)
+			}
+		}
+	}
+	return nil, err
+}
+
+// selectQuorum dumps the set at key from all the healthy servers, which are
+// not joining, and
+// merges the first R successful dumps. The tombstones are also merged, to
+// ensure that a member deleted on some servers is not brought back by the
+// others.
+func (g *group) selectQuorum(ctx context.Context, slotID int, key string, timestamp int64) ([]common.Element, error) {
+	// Cancel the slower dumps once the quorum is reached.
+	dctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Scatter
+	resultChan := make(chan replica, len(g.voters))
+	for _, s := range g.voters {
+		if h := health(s); h.State != Healthy {
+			resultChan <- replica{server: s, err: fmt.Errorf("%s is %s", h.Addr, h.State)}
+			continue
+		}
+		go func(s Server) {
+			elements, err := s.Dump(dctx, slotID, key, timestamp)
+			resultChan <- replica{server: s, elements: elements, err: err}
+		}(s)
+	}
+
+	// Gather
+	var (
+		replicas = []replica(nil)
+		errs     = []string(nil)
+		need     = g.readQuorum()
+	)
+	for i := 0; i < cap(resultChan) && len(replicas) < need; i++ {
+		result := <-resultChan
+		if result.err != nil {
+			errs = append(errs, result.err.Error())
+			continue
+		}
+		replicas = append(replicas, result)
+	}
+
+	// Report
+	if len(replicas) < need {
+		return nil, fmt.Errorf("no quorum (%s)", strings.Join(errs, "; "))
+	}
+
+	merged := merge(replicas)
+
+	switch g.readRepair {
+	case ReadRepairAsync:
+		// The repair outlives the request.
+		go g.repair(context.Background(), slotID, key, replicas, merged)
+	case ReadRepairBlocking:
+		g.repair(ctx, slotID, key, replicas, merged)
+	}
+
+	var elements []common.Element
+	for _, e := range merged {
+		if !e.Deleted {
+			elements = append(elements, e)
+		}
+	}
+	sort.Slice(elements, func(i, j int) bool { return elements[i].Member < elements[j].Member })
+	return elements, nil
+}
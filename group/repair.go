@@ -0,0 +1,106 @@
+package group
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync/atomic"
+
+	"github.com/RussellLuo/goku/common"
+)
+
+// ReadRepair decides how the stale replicas found by a quorum read are
+// repaired.
+type ReadRepair int
+
+const (
+	// ReadRepairOff never repairs the replicas.
+	ReadRepairOff ReadRepair = iota
+	// ReadRepairAsync repairs the replicas in the background, after the
+	// read has returned.
+	ReadRepairAsync
+	// ReadRepairBlocking repairs the replicas before the read returns,
+	// which makes the following reads consistent at the cost of latency.
+	ReadRepairBlocking
+)
+
+func (r ReadRepair) String() string {
+	switch r {
+	case ReadRepairOff:
+		return "off"
+	case ReadRepairAsync:
+		return "async"
+	case ReadRepairBlocking:
+		return "blocking"
+	default:
+		return fmt.Sprintf("repair(%d)", r)
+	}
+}
+
+// ParseReadRepair parses a read repair mode from its string representation.
+func ParseReadRepair(s string) (ReadRepair, error) {
+	for _, r := range []ReadRepair{ReadRepairOff, ReadRepairAsync, ReadRepairBlocking} {
+		if r.String() == s {
+			return r, nil
+		}
+	}
+	return 0, fmt.Errorf("unrecognized read repair: %s", s)
+}
+
+// RepairStats is the counters of the read repair of a group.
+type RepairStats struct {
+	// The number of the elements pushed to the stale replicas.
+	Repaired uint64
+	// The number of the elements failed to be pushed.
+	Failed uint64
+}
+
+// SetReadRepair sets the read repair mode of the quorum reads, which is
+// ReadRepairOff by default. It must be called before the group is used.
+func (g *group) SetReadRepair(r ReadRepair) {
+	g.readRepair = r
+}
+
+// RepairStats returns the counters of the read repair.
+func (g *group) RepairStats() RepairStats {
+	return RepairStats{
+		Repaired: atomic.LoadUint64(&g.repairStats.Repaired),
+		Failed:   atomic.LoadUint64(&g.repairStats.Failed),
+	}
+}
+
+// repair pushes the merged elements (including the tombstones) of the set
+// at key to the replicas that have missed them, or only have older ones.
+// Since the elements are pushed as ordinary LWW operations, they never
+// override any newer write made in the meantime.
+//
+// repair returns the number of the elements that have been pushed.
+func (g *group) repair(ctx context.Context, slotID int, key string, replicas []replica, merged map[string]common.Element) (n int) {
+	for _, r := range replicas {
+		elements := make(map[string]common.Element, len(r.elements))
+		for _, e := range r.elements {
+			elements[e.Member] = e
+		}
+
+		for member, m := range merged {
+			if e, ok := elements[member]; ok && !newer(m, e) {
+				continue
+			}
+
+			var err error
+			if m.Deleted {
+				_, err = r.server.Delete(ctx, slotID, key, member, m.Timestamp)
+			} else {
+				_, err = r.server.Insert(ctx, slotID, key, member, m.Timestamp, m.TTL)
+			}
+			if err != nil {
+				atomic.AddUint64(&g.repairStats.Failed, 1)
+				log.Printf("failed to repair %s of %q at %s: %v", member, key, r.server.Addr(), err)
+				continue
+			}
+			atomic.AddUint64(&g.repairStats.Repaired, 1)
+			n++
+		}
+	}
+	return n
+}
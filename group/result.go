@@ -0,0 +1,89 @@
+package group
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrNoQuorum is the cause of the writes that have failed to reach the
+// write quorum.
+var ErrNoQuorum = errors.New("no quorum")
+
+// ReplicaResult is the outcome of a write on a replica.
+type ReplicaResult struct {
+	Addr string
+	// Pending indicates that the replica has not answered yet when the
+	// write returns, in which case the other fields are meaningless.
+	Pending bool
+	// Joining indicates that the replica is joining the group, whose outcome
+	// does not count toward the write quorum.
+	Joining bool
+	// The status reported by the replica, e.g. whether an existing member
+	// has been updated by an insertion.
+	Status  bool
+	Err     error
+	Latency time.Duration
+}
+
+// WriteResult is the outcome of a write on a group, which lists the
+// outcomes on all the replicas in the order of the servers in the group.
+type WriteResult struct {
+	Replicas []ReplicaResult
+}
+
+// Succeeded returns the number of the replicas, which are not joining, on
+// which the write has succeeded.
+func (r *WriteResult) Succeeded() int {
+	n := 0
+	for _, rr := range r.Replicas {
+		if !rr.Pending && !rr.Joining && rr.Err == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// Status returns true if any replica, which is not joining and on which the
+// write has succeeded, reports true. A write with a false status is either stale or has no
+// effect, which is not an error.
+func (r *WriteResult) Status() bool {
+	for _, rr := range r.Replicas {
+		if !rr.Pending && !rr.Joining && rr.Err == nil && rr.Status {
+			return true
+		}
+	}
+	return false
+}
+
+// PartialWriteError is returned if a write has failed to reach the write
+// quorum. The write may still have succeeded on some replicas, which can be
+// found in Result.
+type PartialWriteError struct {
+	Result *WriteResult
+	Quorum int
+}
+
+func (e *PartialWriteError) Error() string {
+	var errs []string
+	for _, rr := range e.Result.Replicas {
+		if rr.Err != nil {
+			errs = append(errs, rr.Err.Error())
+		}
+	}
+	return fmt.Sprintf("%s (%s)", ErrNoQuorum, strings.Join(errs, "; "))
+}
+
+// Cause returns ErrNoQuorum.
+func (e *PartialWriteError) Cause() error {
+	return ErrNoQuorum
+}
+
+// IsNoQuorum reports whether err is caused by ErrNoQuorum.
+func IsNoQuorum(err error) bool {
+	if e, ok := err.(*PartialWriteError); ok {
+		err = e.Cause()
+	}
+	return err == ErrNoQuorum
+}
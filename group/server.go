@@ -2,7 +2,9 @@ package group
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/RussellLuo/goku/common"
@@ -12,6 +14,8 @@ import (
 type Pool interface {
 	Get() (pb.GokuServerClient, error)
 	Put(cli pb.GokuServerClient)
+	// Check checks the health of the server.
+	Check(ctx context.Context) error
 	CloseAll() error
 }
 
@@ -33,9 +37,21 @@ func (s *server) Addr() string {
 	return s.addr
 }
 
-func (s *server) Insert(slotID int, key, member string, timestamp int64, ttl time.Duration) (bool, error) {
+// Check checks the health of the server within the timeout.
+func (s *server) Check() error {
 	ctx, cancelFunc := context.WithTimeout(context.Background(), s.timeout)
 	defer cancelFunc()
+	return s.pool.Check(ctx)
+}
+
+// Close closes all the connections to the server.
+func (s *server) Close() error {
+	return s.pool.CloseAll()
+}
+
+func (s *server) Insert(ctx context.Context, slotID int, key, member string, timestamp int64, ttl time.Duration) (bool, error) {
+	ctx, cancelFunc := context.WithTimeout(ctx, s.timeout)
+	defer cancelFunc()
 
 	cli, err := s.pool.Get()
 	if err != nil {
@@ -44,24 +60,24 @@ func (s *server) Insert(slotID int, key, member string, timestamp int64, ttl tim
 	defer s.pool.Put(cli)
 
 	reply, err := cli.Insert(ctx, &pb.InsertRequest{
-		SlotId:    int64(slotID),
-		Key:       key,
-		Member:    member,
+		SlotId:      int64(slotID),
+		Key:         key,
+		Member:      member,
 		TimestampNs: timestamp,
-		TtlNs:     ttl.Nanoseconds(),
+		TtlNs:       ttl.Nanoseconds(),
 	})
 	if err != nil {
 		return false, err
 	}
 
 	if reply.Error != nil {
-		err = fmt.Errorf(reply.Error.Message)
+		err = errors.New(reply.Error.Message)
 	}
 	return reply.Updated, err
 }
 
-func (s *server) Delete(slotID int, key, member string, timestamp int64) (bool, error) {
-	ctx, cancelFunc := context.WithTimeout(context.Background(), s.timeout)
+func (s *server) Delete(ctx context.Context, slotID int, key, member string, timestamp int64) (bool, error) {
+	ctx, cancelFunc := context.WithTimeout(ctx, s.timeout)
 	defer cancelFunc()
 
 	cli, err := s.pool.Get()
@@ -71,9 +87,9 @@ func (s *server) Delete(slotID int, key, member string, timestamp int64) (bool,
 	defer s.pool.Put(cli)
 
 	reply, err := cli.Delete(ctx, &pb.DeleteRequest{
-		SlotId:    int64(slotID),
-		Key:       key,
-		Member:    member,
+		SlotId:      int64(slotID),
+		Key:         key,
+		Member:      member,
 		TimestampNs: timestamp,
 	})
 	if err != nil {
@@ -81,13 +97,13 @@ func (s *server) Delete(slotID int, key, member string, timestamp int64) (bool,
 	}
 
 	if reply.Error != nil {
-		err = fmt.Errorf(reply.Error.Message)
+		err = errors.New(reply.Error.Message)
 	}
 	return reply.Deleted, err
 }
 
-func (s *server) Select(slotID int, key string, timestamp int64) ([]common.Element, error) {
-	ctx, cancelFunc := context.WithTimeout(context.Background(), s.timeout)
+func (s *server) Select(ctx context.Context, slotID int, key string, timestamp int64) ([]common.Element, error) {
+	ctx, cancelFunc := context.WithTimeout(ctx, s.timeout)
 	defer cancelFunc()
 
 	cli, err := s.pool.Get()
@@ -97,8 +113,150 @@ func (s *server) Select(slotID int, key string, timestamp int64) ([]common.Eleme
 	defer s.pool.Put(cli)
 
 	reply, err := cli.Select(ctx, &pb.SelectRequest{
-		SlotId:    int64(slotID),
-		Key:       key,
+		SlotId:      int64(slotID),
+		Key:         key,
+		TimestampNs: timestamp,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if reply.Error != nil {
+		err = errors.New(reply.Error.Message)
+	}
+	elements := make([]common.Element, len(reply.Elements))
+	for i, e := range reply.Elements {
+		elements[i] = common.Element{
+			Member:    e.Member,
+			Timestamp: e.TimestampNs,
+			TTL:       time.Duration(e.TtlNs),
+		}
+	}
+	return elements, err
+}
+
+func (s *server) BatchInsert(ctx context.Context, ops []Op) ([]OpResult, error) {
+	ctx, cancelFunc := context.WithTimeout(ctx, s.timeout)
+	defer cancelFunc()
+
+	cli, err := s.pool.Get()
+	if err != nil {
+		return nil, err
+	}
+	defer s.pool.Put(cli)
+
+	in := &pb.BatchInsertRequest{Items: make([]*pb.InsertRequest, len(ops))}
+	for i, op := range ops {
+		in.Items[i] = &pb.InsertRequest{
+			SlotId:      int64(op.SlotID),
+			Key:         op.Key,
+			Member:      op.Member,
+			TimestampNs: op.Timestamp,
+			TtlNs:       op.TTL.Nanoseconds(),
+		}
+	}
+	reply, err := cli.BatchInsert(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]OpResult, len(reply.Items))
+	for i, item := range reply.Items {
+		results[i].Status = item.Updated
+		if item.Error != nil {
+			results[i].Err = errors.New(item.Error.Message)
+		}
+	}
+	return results, nil
+}
+
+func (s *server) BatchDelete(ctx context.Context, ops []Op) ([]OpResult, error) {
+	ctx, cancelFunc := context.WithTimeout(ctx, s.timeout)
+	defer cancelFunc()
+
+	cli, err := s.pool.Get()
+	if err != nil {
+		return nil, err
+	}
+	defer s.pool.Put(cli)
+
+	in := &pb.BatchDeleteRequest{Items: make([]*pb.DeleteRequest, len(ops))}
+	for i, op := range ops {
+		in.Items[i] = &pb.DeleteRequest{
+			SlotId:      int64(op.SlotID),
+			Key:         op.Key,
+			Member:      op.Member,
+			TimestampNs: op.Timestamp,
+		}
+	}
+	reply, err := cli.BatchDelete(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]OpResult, len(reply.Items))
+	for i, item := range reply.Items {
+		results[i].Status = item.Deleted
+		if item.Error != nil {
+			results[i].Err = errors.New(item.Error.Message)
+		}
+	}
+	return results, nil
+}
+
+func (s *server) MultiSelect(ctx context.Context, ops []Op) ([]SelectResult, error) {
+	ctx, cancelFunc := context.WithTimeout(ctx, s.timeout)
+	defer cancelFunc()
+
+	cli, err := s.pool.Get()
+	if err != nil {
+		return nil, err
+	}
+	defer s.pool.Put(cli)
+
+	in := &pb.MultiSelectRequest{Items: make([]*pb.SelectRequest, len(ops))}
+	for i, op := range ops {
+		in.Items[i] = &pb.SelectRequest{
+			SlotId:      int64(op.SlotID),
+			Key:         op.Key,
+			TimestampNs: op.Timestamp,
+		}
+	}
+	reply, err := cli.MultiSelect(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]SelectResult, len(reply.Items))
+	for i, item := range reply.Items {
+		if item.Error != nil {
+			results[i].Err = errors.New(item.Error.Message)
+		}
+		results[i].Elements = make([]common.Element, len(item.Elements))
+		for j, e := range item.Elements {
+			results[i].Elements[j] = common.Element{
+				Member:    e.Member,
+				Timestamp: e.TimestampNs,
+				TTL:       time.Duration(e.TtlNs),
+			}
+		}
+	}
+	return results, nil
+}
+
+func (s *server) Dump(ctx context.Context, slotID int, key string, timestamp int64) ([]common.Element, error) {
+	ctx, cancelFunc := context.WithTimeout(ctx, s.timeout)
+	defer cancelFunc()
+
+	cli, err := s.pool.Get()
+	if err != nil {
+		return nil, err
+	}
+	defer s.pool.Put(cli)
+
+	reply, err := cli.Dump(ctx, &pb.DumpRequest{
+		SlotId:      int64(slotID),
+		Key:         key,
 		TimestampNs: timestamp,
 	})
 	if err != nil {
@@ -106,7 +264,7 @@ func (s *server) Select(slotID int, key string, timestamp int64) ([]common.Eleme
 	}
 
 	if reply.Error != nil {
-		err = fmt.Errorf(reply.Error.Message)
+		err = errors.New(reply.Error.Message)
 	}
 	elements := make([]common.Element, len(reply.Elements))
 	for i, e := range reply.Elements {
@@ -114,7 +272,203 @@ func (s *server) Select(slotID int, key string, timestamp int64) ([]common.Eleme
 			Member:    e.Member,
 			Timestamp: e.TimestampNs,
 			TTL:       time.Duration(e.TtlNs),
+			Deleted:   e.Deleted,
 		}
 	}
 	return elements, err
 }
+
+func (s *server) Purge(ctx context.Context, slotID int, key string, elements []common.Element) (int, error) {
+	ctx, cancelFunc := context.WithTimeout(ctx, s.timeout)
+	defer cancelFunc()
+
+	cli, err := s.pool.Get()
+	if err != nil {
+		return 0, err
+	}
+	defer s.pool.Put(cli)
+
+	in := &pb.PurgeRequest{
+		SlotId:   int64(slotID),
+		Key:      key,
+		Elements: make([]*pb.Element, len(elements)),
+	}
+	for i, e := range elements {
+		in.Elements[i] = &pb.Element{
+			Member:      e.Member,
+			TimestampNs: e.Timestamp,
+			TtlNs:       int64(e.TTL),
+			Deleted:     e.Deleted,
+		}
+	}
+	reply, err := cli.Purge(ctx, in)
+	if err != nil {
+		return 0, err
+	}
+
+	if reply.Error != nil {
+		err = errors.New(reply.Error.Message)
+	}
+	return int(reply.Purged), err
+}
+
+// Keys streams the keys of the slot from the server. Since the scan of a
+// whole slot may take long, it is not limited by s.timeout.
+func (s *server) Keys(ctx context.Context, slotID, batchSize int) (<-chan []string, func() error) {
+	c := make(chan []string)
+	var err error
+	go func() {
+		defer close(c)
+
+		ctx, cancelFunc := context.WithCancel(ctx)
+		defer cancelFunc()
+
+		err = s.scan(ctx, slotID, c, func(cli pb.GokuServerClient) (keysStream, error) {
+			return cli.Keys(ctx, &pb.KeysRequest{
+				SlotId:    int64(slotID),
+				BatchSize: int64(batchSize),
+			})
+		})
+	}()
+	return c, func() error { return err }
+}
+
+// ImportSlot makes the server pull the slot from the source server, starting
+// from cursor. It returns the cursor to resume from if the import fails.
+// Since the transfer of a whole slot may take long, it is not limited by
+// s.timeout.
+func (s *server) ImportSlot(slotID int, source, cursor string) (string, error) {
+	cli, err := s.pool.Get()
+	if err != nil {
+		return cursor, err
+	}
+	defer s.pool.Put(cli)
+
+	reply, err := cli.ImportSlot(context.Background(), &pb.ImportSlotRequest{
+		SlotId: int64(slotID),
+		Source: source,
+		Cursor: []byte(cursor),
+	})
+	if err != nil {
+		return cursor, err
+	}
+
+	if reply.Error != nil {
+		err = errors.New(reply.Error.Message)
+	}
+	return string(reply.Cursor), err
+}
+
+// DropSlot removes the whole slot from the server. Like ImportSlot, it is
+// not limited by s.timeout.
+func (s *server) DropSlot(slotID int) error {
+	cli, err := s.pool.Get()
+	if err != nil {
+		return err
+	}
+	defer s.pool.Put(cli)
+
+	reply, err := cli.DropSlot(context.Background(), &pb.DropSlotRequest{
+		SlotId: int64(slotID),
+	})
+	if err != nil {
+		return err
+	}
+
+	if reply.Error != nil {
+		err = errors.New(reply.Error.Message)
+	}
+	return err
+}
+
+func (s *server) Tree(slotID int, nodes []int) ([]uint64, int, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), s.timeout)
+	defer cancelFunc()
+
+	cli, err := s.pool.Get()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer s.pool.Put(cli)
+
+	in := &pb.TreeRequest{
+		SlotId: int64(slotID),
+		Nodes:  make([]int64, len(nodes)),
+	}
+	for i, n := range nodes {
+		in.Nodes[i] = int64(n)
+	}
+	reply, err := cli.Tree(ctx, in)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if reply.Error != nil {
+		err = errors.New(reply.Error.Message)
+	}
+	return reply.Hashes, int(reply.Leaves), err
+}
+
+// TreeKeys streams the keys in the given leaves from the server. Like Keys,
+// it is not limited by s.timeout.
+func (s *server) TreeKeys(slotID int, leaves []int, batchSize int) (<-chan []string, func() error) {
+	c := make(chan []string)
+	var err error
+	go func() {
+		defer close(c)
+
+		ctx, cancelFunc := context.WithCancel(context.Background())
+		defer cancelFunc()
+
+		in := &pb.TreeKeysRequest{
+			SlotId:    int64(slotID),
+			Leaves:    make([]int64, len(leaves)),
+			BatchSize: int64(batchSize),
+		}
+		for i, l := range leaves {
+			in.Leaves[i] = int64(l)
+		}
+		err = s.scan(ctx, slotID, c, func(cli pb.GokuServerClient) (keysStream, error) {
+			return cli.TreeKeys(ctx, in)
+		})
+	}()
+	return c, func() error { return err }
+}
+
+// keysStream is the common part of the streams of Keys and TreeKeys.
+type keysStream interface {
+	Recv() (*pb.KeysReply, error)
+}
+
+// scan sends the keys received from the stream opened by open to c, until
+// the stream ends or ctx is done, and returns why it ends.
+func (s *server) scan(ctx context.Context, slotID int, c chan<- []string, open func(pb.GokuServerClient) (keysStream, error)) error {
+	cli, err := s.pool.Get()
+	if err != nil {
+		return fmt.Errorf("failed to scan keys in slot %d from %s: %v", slotID, s.addr, err)
+	}
+	defer s.pool.Put(cli)
+
+	stream, err := open(cli)
+	if err != nil {
+		return fmt.Errorf("failed to scan keys in slot %d from %s: %v", slotID, s.addr, err)
+	}
+
+	for {
+		reply, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err == nil && reply.Error != nil {
+			err = errors.New(reply.Error.Message)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to scan keys in slot %d from %s: %v", slotID, s.addr, err)
+		}
+		select {
+		case c <- reply.Keys:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
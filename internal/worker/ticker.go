@@ -0,0 +1,67 @@
+// Package worker provides the building blocks of the background workers
+// shared by the server and the groups.
+package worker
+
+import (
+	"sync"
+	"time"
+)
+
+// Ticker is a background worker that calls fn every interval.
+type Ticker struct {
+	interval time.Duration
+	fn       func()
+
+	mu    sync.Mutex
+	stopC chan struct{}
+	doneC chan struct{}
+}
+
+// NewTicker creates a Ticker, which calls fn every interval once started.
+func NewTicker(interval time.Duration, fn func()) *Ticker {
+	return &Ticker{interval: interval, fn: fn}
+}
+
+// Start starts calling fn in the background.
+func (t *Ticker) Start() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.stopC != nil {
+		return
+	}
+	t.stopC = make(chan struct{})
+	t.doneC = make(chan struct{})
+
+	go t.loop(t.stopC, t.doneC)
+}
+
+// Stop stops calling fn and waits for the last call to return.
+func (t *Ticker) Stop() {
+	t.mu.Lock()
+	stopC, doneC := t.stopC, t.doneC
+	t.stopC, t.doneC = nil, nil
+	t.mu.Unlock()
+
+	if stopC == nil {
+		return
+	}
+	close(stopC)
+	<-doneC
+}
+
+func (t *Ticker) loop(stopC, doneC chan struct{}) {
+	defer close(doneC)
+
+	tk := time.NewTicker(t.interval)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-tk.C:
+			t.fn()
+		case <-stopC:
+			return
+		}
+	}
+}
@@ -0,0 +1,320 @@
+package server
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// BoltDB is a disk-bound storage engine built on BoltDB, which keeps the
+// elements of each slot in a separate bucket.
+type BoltDB struct {
+	db *bolt.DB
+}
+
+var (
+	metaBucket = []byte("meta")
+	layoutKey  = []byte("layout")
+)
+
+// The versions of the store key layout.
+const (
+	// The legacy layout fmt.Sprintf("%02d%s%s", slotID, key, member), whose
+	// elements are followed by their members.
+	layoutLegacy = "1"
+	// The layout described in key.go.
+	layoutEscaped = "2"
+)
+
+// OpenBolt opens the BoltDB database at path, which will be created if it
+// does not exist. The database in the legacy layout will be migrated.
+func OpenBolt(path string) (*BoltDB, error) {
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	b := &BoltDB{db: db}
+	if err := b.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return b, nil
+}
+
+// OpenWithBolt creates a durable server, whose slots are stored in the
+// BoltDB database at path. The existing slots are loaded first, so that
+// they can be found by the background workers, which walks all the stored
+// elements (see newSlot). The database is closed when the server is closed.
+func OpenWithBolt(path string) (*Server, error) {
+	db, err := OpenBolt(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := db.SlotIDs()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	s := NewServerWithStore(db.NewStore)
+	for _, id := range ids {
+		s.Slot(id)
+	}
+	s.db = db
+	return s, nil
+}
+
+// migrate migrates all the slots in the database to the current layout.
+func (b *BoltDB) migrate() error {
+	var layout string
+	err := b.db.View(func(tx *bolt.Tx) error {
+		if meta := tx.Bucket(metaBucket); meta != nil {
+			layout = string(meta.Get(layoutKey))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	switch layout {
+	case layoutEscaped:
+		return nil
+	case "", layoutLegacy:
+		// The database is either new or in the legacy layout.
+	default:
+		return fmt.Errorf("unsupported layout: %s", layout)
+	}
+
+	ids, err := b.SlotIDs()
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := b.migrateLegacySlot(id); err != nil {
+			return fmt.Errorf("failed to migrate slot %d: %s", id, err)
+		}
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		meta, err := tx.CreateBucketIfNotExists(metaBucket)
+		if err != nil {
+			return err
+		}
+		return meta.Put(layoutKey, []byte(layoutEscaped))
+	})
+}
+
+// migrateLegacySlot re-keys all the elements of the slot identified by
+// slotID, from the legacy layout to the current one, in one transaction.
+func (b *BoltDB) migrateLegacySlot(slotID int) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		name := []byte(strconv.Itoa(slotID))
+
+		type entry struct {
+			k string
+			v []byte
+		}
+		var entries []entry
+		err := tx.Bucket(name).ForEach(func(k, v []byte) error {
+			e, member, err := decodeLegacyElement(v)
+			if err != nil {
+				return err
+			}
+			key, err := decodeLegacyKey(slotID, string(k), member)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, entry{k: encodeKeyMember(key, member), v: encodeElement(e)})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := tx.DeleteBucket(name); err != nil {
+			return err
+		}
+		bucket, err := tx.CreateBucket(name)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if err := bucket.Put([]byte(e.k), e.v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// NewStore creates the store for the slot identified by slotID, which
+// satisfies the NewStore type.
+func (b *BoltDB) NewStore(slotID int) Store {
+	return &boltStore{db: b.db, bucket: []byte(strconv.Itoa(slotID))}
+}
+
+// SlotIDs returns the ids of the slots that have been stored.
+func (b *BoltDB) SlotIDs() ([]int, error) {
+	var ids []int
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+			id, err := strconv.Atoi(string(name))
+			if err != nil {
+				// Not a slot bucket.
+				return nil
+			}
+			ids = append(ids, id)
+			return nil
+		})
+	})
+	return ids, err
+}
+
+// Close closes the database.
+func (b *BoltDB) Close() error {
+	return b.db.Close()
+}
+
+// boltStore is a disk-bound Store, which is built on a BoltDB bucket.
+//
+// Each write is made in its own transaction, which is synced to the disk
+// before the write returns. Thus no acknowledged write is lost on a crash,
+// at the cost of one fsync per write, which bounds the write throughput.
+// The writes are not batched, since they are made under the lock of the
+// slot, and a batch would hold the lock until it is committed. Use the
+// memory backend, whose write-ahead log can be synced every second, for
+// a higher throughput at the risk of losing the last writes.
+type boltStore struct {
+	db     *bolt.DB
+	bucket []byte
+}
+
+func (s *boltStore) Get(k string) (e Element, ok bool, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(s.bucket)
+		if b == nil {
+			return nil
+		}
+		v := b.Get([]byte(k))
+		if v == nil {
+			return nil
+		}
+		ok = true
+		e, err = decodeElement(v)
+		return err
+	})
+	return e, ok, err
+}
+
+func (s *boltStore) Insert(k string, e Element) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(s.bucket)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(k), encodeElement(e))
+	})
+}
+
+func (s *boltStore) Delete(k string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(s.bucket)
+		if b == nil {
+			return nil
+		}
+		return b.Delete([]byte(k))
+	})
+}
+
+func (s *boltStore) WalkPrefix(prefix string, fn WalkFn) error {
+	return s.walk([]byte(prefix), []byte(prefix), fn)
+}
+
+func (s *boltStore) WalkFrom(start string, fn WalkFn) error {
+	return s.walk([]byte(start), nil, fn)
+}
+
+func (s *boltStore) Walk(fn WalkFn) error {
+	return s.walk(nil, nil, fn)
+}
+
+// walk visits all the elements whose keys start with prefix and are not
+// less than start.
+func (s *boltStore) walk(start, prefix []byte, fn WalkFn) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(s.bucket)
+		if b == nil {
+			return nil
+		}
+
+		c := b.Cursor()
+		for k, v := c.Seek(start); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			e, err := decodeElement(v)
+			if err != nil {
+				return err
+			}
+			if fn(string(k), e) {
+				break
+			}
+		}
+		return nil
+	})
+}
+
+func (s *boltStore) Len() (n int, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		if b := tx.Bucket(s.bucket); b != nil {
+			n = b.Stats().KeyN
+		}
+		return nil
+	})
+	return n, err
+}
+
+func encodeElement(e Element) []byte {
+	buf := make([]byte, 0, 2*binary.MaxVarintLen64+1)
+	buf = appendVarint(buf, e.Timestamp)
+	buf = appendVarint(buf, int64(e.TTL))
+	if e.Deleted {
+		return append(buf, 1)
+	}
+	return append(buf, 0)
+}
+
+func decodeElement(v []byte) (Element, error) {
+	e, rest, err := decodeElementPrefix(v)
+	if err == nil && len(rest) != 0 {
+		err = errCorruptRecord
+	}
+	if err != nil {
+		return Element{}, fmt.Errorf("bad element: %s", err)
+	}
+	return e, nil
+}
+
+// decodeLegacyElement decodes the element in the legacy layout, which is
+// followed by its member.
+func decodeLegacyElement(v []byte) (Element, string, error) {
+	e, rest, err := decodeElementPrefix(v)
+	if err != nil {
+		return Element{}, "", fmt.Errorf("bad element: %s", err)
+	}
+	return e, string(rest), nil
+}
+
+func decodeElementPrefix(v []byte) (Element, []byte, error) {
+	d := decoder{buf: v}
+	e := Element{
+		Timestamp: d.varint(),
+		TTL:       time.Duration(d.varint()),
+		Deleted:   d.byte() == 1,
+	}
+	return e, d.buf, d.err
+}
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"container/heap"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/RussellLuo/goku/internal/worker"
+)
+
+// expiry is the deadline of a volatile element.
+type expiry struct {
+	key      string
+	deadline int64
+}
+
+// expiryQueue is a min-heap of expiries ordered by their deadlines, which
+// holds at most one expiry per key. The zero value is an empty queue.
+type expiryQueue struct {
+	items []expiry
+	index map[string]int // The positions of the expiries by their keys
+}
+
+func (q *expiryQueue) Len() int           { return len(q.items) }
+func (q *expiryQueue) Less(i, j int) bool { return q.items[i].deadline < q.items[j].deadline }
+func (q *expiryQueue) Swap(i, j int) {
+	q.items[i], q.items[j] = q.items[j], q.items[i]
+	q.index[q.items[i].key] = i
+	q.index[q.items[j].key] = j
+}
+func (q *expiryQueue) Push(x interface{}) {
+	item := x.(expiry)
+	q.index[item.key] = len(q.items)
+	q.items = append(q.items, item)
+}
+func (q *expiryQueue) Pop() interface{} {
+	n := len(q.items)
+	x := q.items[n-1]
+	q.items = q.items[:n-1]
+	delete(q.index, x.key)
+	return x
+}
+
+// push adds the deadline of the element e stored at key, which replaces the
+// previous deadline of key, if any.
+func (q *expiryQueue) push(key string, e Element) {
+	deadline := e.Timestamp + e.TTL.Nanoseconds()
+	if i, ok := q.index[key]; ok {
+		q.items[i].deadline = deadline
+		heap.Fix(q, i)
+		return
+	}
+	if q.index == nil {
+		q.index = make(map[string]int)
+	}
+	heap.Push(q, expiry{key: key, deadline: deadline})
+}
+
+// remove removes the deadline of key, if any.
+func (q *expiryQueue) remove(key string) {
+	if i, ok := q.index[key]; ok {
+		heap.Remove(q, i)
+	}
+}
+
+// popDue removes and returns the earliest expiry, if it is due at the
+// given timestamp.
+func (q *expiryQueue) popDue(timestamp int64) (expiry, bool) {
+	if q.Len() == 0 || q.items[0].deadline > timestamp {
+		return expiry{}, false
+	}
+	return heap.Pop(q).(expiry), true
+}
+
+// Expirer is a background worker that actively reclaims the expired
+// elements, which may otherwise never be removed if they are not selected
+// again.
+//
+// The deadlines of the volatile elements are kept in a per-slot min-heap,
+// and the expirer only holds the lock of a slot for reclaiming at most
+// batchSize elements at a time. Note that the heap is kept in memory for
+// every store, which takes memory in proportion to the number of the
+// volatile elements.
+type Expirer struct {
+	*worker.Ticker
+
+	server    *Server
+	batchSize int
+
+	expired uint64 // The total number of reclaimed elements (atomic)
+}
+
+// NewExpirer creates an Expirer, which reclaims the expired elements from
+// server every interval, at most batchSize (at least 1) elements per lock
+// acquisition.
+func NewExpirer(server *Server, interval time.Duration, batchSize int) *Expirer {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	e := &Expirer{
+		server:    server,
+		batchSize: batchSize,
+	}
+	e.Ticker = worker.NewTicker(interval, e.tick)
+	return e
+}
+
+// Expired returns the total number of elements reclaimed so far.
+func (e *Expirer) Expired() uint64 {
+	return atomic.LoadUint64(&e.expired)
+}
+
+func (e *Expirer) tick() {
+	e.Expire(time.Now().UnixNano())
+}
+
+// Expire reclaims all the elements, which are expired at the given
+// timestamp, slot by slot. It returns the number of reclaimed elements.
+func (e *Expirer) Expire(timestamp int64) int {
+	n := 0
+	for slotID, slot := range e.server.Slots() {
+		for {
+			reclaimed, more, err := e.expireSlot(slot, timestamp)
+			n += reclaimed
+			if err != nil {
+				log.Printf("failed to expire elements in slot %d: %v", slotID, err)
+			}
+			if !more || err != nil {
+				break
+			}
+		}
+	}
+
+	atomic.AddUint64(&e.expired, uint64(n))
+	return n
+}
+
+// expireSlot reclaims at most batchSize expired elements from slot. It also
+// reports whether there may be more expired elements.
+func (e *Expirer) expireSlot(slot *Slot, timestamp int64) (n int, more bool, err error) {
+	slot.Mu.Lock()
+	defer slot.Mu.Unlock()
+
+	for i := 0; i < e.batchSize; i++ {
+		x, ok := slot.expires.popDue(timestamp)
+		if !ok {
+			return n, false, nil
+		}
+
+		el, ok, err := slot.Store.Get(x.key)
+		if err != nil {
+			return n, false, err
+		}
+		// The element may have been updated or deleted after the
+		// expiry was added.
+		if !ok || el.Deleted || el.Timestamp+el.TTL.Nanoseconds() != x.deadline {
+			continue
+		}
+
+		if err := slot.remove(x.key, el); err != nil {
+			return n, false, err
+		}
+		n++
+	}
+
+	return n, true, nil
+}
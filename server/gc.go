@@ -0,0 +1,117 @@
+package server
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/RussellLuo/goku/internal/worker"
+)
+
+// Collector is a background worker that purges the tombstones, which are
+// older than a grace period, from the server.
+//
+// The grace period must be longer than the maximum expected replication
+// lag, otherwise a delayed insertion may bring back a deleted member
+// after its tombstone has been purged.
+type Collector struct {
+	*worker.Ticker
+
+	server *Server
+	grace  time.Duration
+
+	mu     sync.Mutex
+	paused bool
+
+	reclaimed uint64 // The total number of purged tombstones (atomic)
+}
+
+// NewCollector creates a Collector, which purges the tombstones older than
+// grace from server every interval.
+func NewCollector(server *Server, grace, interval time.Duration) *Collector {
+	c := &Collector{
+		server: server,
+		grace:  grace,
+	}
+	c.Ticker = worker.NewTicker(interval, c.tick)
+	return c
+}
+
+// Pause pauses the collector, until Resume is called.
+func (c *Collector) Pause() {
+	c.mu.Lock()
+	c.paused = true
+	c.mu.Unlock()
+}
+
+// Resume resumes the collector paused by Pause.
+func (c *Collector) Resume() {
+	c.mu.Lock()
+	c.paused = false
+	c.mu.Unlock()
+}
+
+// Paused reports whether the collector is paused.
+func (c *Collector) Paused() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.paused
+}
+
+// Reclaimed returns the total number of tombstones purged so far.
+func (c *Collector) Reclaimed() uint64 {
+	return atomic.LoadUint64(&c.reclaimed)
+}
+
+func (c *Collector) tick() {
+	if !c.Paused() {
+		c.Collect(time.Now().UnixNano())
+	}
+}
+
+// Collect purges the tombstones, which are older than the grace period at
+// the given timestamp, slot by slot. It returns the number of purged
+// tombstones.
+func (c *Collector) Collect(timestamp int64) int {
+	deadline := timestamp - c.grace.Nanoseconds()
+
+	n := 0
+	for slotID, slot := range c.server.Slots() {
+		reclaimed, err := c.collectSlot(slot, deadline)
+		if err != nil {
+			log.Printf("failed to collect tombstones in slot %d: %v", slotID, err)
+		}
+		n += reclaimed
+	}
+
+	atomic.AddUint64(&c.reclaimed, uint64(n))
+	return n
+}
+
+func (c *Collector) collectSlot(slot *Slot, deadline int64) (int, error) {
+	slot.Mu.Lock()
+	defer slot.Mu.Unlock()
+
+	type tombstone struct {
+		k string
+		e Element
+	}
+	var tombstones []tombstone
+	err := slot.Store.Walk(func(k string, e Element) bool {
+		if e.Deleted && e.Timestamp < deadline {
+			tombstones = append(tombstones, tombstone{k: k, e: e})
+		}
+		return false
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for i, t := range tombstones {
+		if err := slot.remove(t.k, t.e); err != nil {
+			return i, err
+		}
+	}
+	return len(tombstones), nil
+}
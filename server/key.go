@@ -0,0 +1,61 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The layout of the store keys within a slot is:
+//
+//	escape(key) + keyTerminator + member
+//
+// where escape replaces every 0x00 in key with 0x00 0xFF. Since an escaped
+// key never contains the terminator 0x00 0x01, the first terminator in a
+// store key always marks the end of the set key, which makes the layout
+// collision-free. It also ensures that the store keys of a set share the
+// prefix escape(key) + keyTerminator, which no other set has.
+//
+// The slot id is not encoded, since every slot has its own store.
+const (
+	keyEscape     = "\x00"
+	keyEscaped    = "\x00\xff"
+	keyTerminator = "\x00\x01"
+)
+
+// encodeKey returns the prefix shared by the store keys of the set at key.
+func encodeKey(key string) string {
+	return strings.Replace(key, keyEscape, keyEscaped, -1) + keyTerminator
+}
+
+// encodeKeyEnd returns the smallest store key that is greater than all the
+// store keys of the set at key.
+func encodeKeyEnd(key string) string {
+	prefix := encodeKey(key)
+	// Increment the last byte of the terminator.
+	return prefix[:len(prefix)-1] + string([]byte{keyTerminator[1] + 1})
+}
+
+// encodeKeyMember returns the store key of member in the set at key.
+func encodeKeyMember(key, member string) string {
+	return encodeKey(key) + member
+}
+
+// decodeKeyMember splits the store key k into the set key and the member.
+func decodeKeyMember(k string) (key, member string, err error) {
+	i := strings.Index(k, keyTerminator)
+	if i < 0 {
+		return "", "", fmt.Errorf("malformed store key: %q", k)
+	}
+	return strings.Replace(k[:i], keyEscaped, keyEscape, -1), k[i+len(keyTerminator):], nil
+}
+
+// decodeLegacyKey splits the store key k of member, which is in the legacy
+// layout fmt.Sprintf("%02d%s%s", slotID, key, member), into the set key and
+// the member. Since the legacy layout is ambiguous, the member must be known.
+func decodeLegacyKey(slotID int, k, member string) (key string, err error) {
+	prefix := fmt.Sprintf("%02d", slotID)
+	if !strings.HasPrefix(k, prefix) || !strings.HasSuffix(k, member) || len(k) < len(prefix)+len(member) {
+		return "", fmt.Errorf("malformed legacy store key: %q", k)
+	}
+	return k[len(prefix) : len(k)-len(member)], nil
+}
@@ -1,35 +1,235 @@
 package server
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"log"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
-	"github.com/armon/go-radix"
-
 	"github.com/RussellLuo/goku/common"
 )
 
+// Element is the value stored for a member of a set. It records the
+// timestamp of the last operation (insertion or deletion) applied to the
+// member, which makes the set a last-write-wins element set (LWW-element-set).
 type Element struct {
 	Timestamp int64
 	TTL       time.Duration
+	// Deleted indicates that the element is a tombstone, which is kept to
+	// prevent any older insertion from bringing back the deleted member.
+	Deleted bool
+}
+
+// expired reports whether the element is expired at the given timestamp.
+func (e Element) expired(timestamp int64) bool {
+	return e.TTL > 0 && e.Timestamp+e.TTL.Nanoseconds() <= timestamp
 }
 
 type Slot struct {
 	Mu    sync.RWMutex
-	Store *radix.Tree
+	Store Store
+
+	// The deadlines of the volatile elements, guarded by Mu.
+	expires expiryQueue
+	// The hash tree of the elements, guarded by Mu. The elements must
+	// be changed by put and remove to keep it up to date.
+	tree hashTree
+}
+
+// newSlot creates a slot backed by store, which may have stored some
+// elements already. It walks all the stored elements to track the volatile
+// ones and to build the hash tree, which takes time in proportion to the
+// size of the slot for a disk-bound store.
+func newSlot(store Store) *Slot {
+	slot := &Slot{Store: store}
+
+	// Track the deadlines of the existing volatile elements, and build
+	// the hash tree.
+	err := store.Walk(func(k string, e Element) bool {
+		if !e.Deleted && e.TTL > 0 {
+			slot.expires.push(k, e)
+		}
+		slot.tree.update(k, nil, &e)
+		return false
+	})
+	if err != nil {
+		log.Printf("failed to load the slot: %v", err)
+	}
+
+	return slot
 }
 
 type Server struct {
-	mu    sync.RWMutex
-	slots map[int]*Slot
+	mu       sync.RWMutex
+	slots    map[int]*Slot
+	newStore NewStore
+
+	workers []Worker
+
+	// The persistence, which is only enabled if the server is opened
+	// by Open.
+	dir    string
+	wal    *wal
+	snapMu sync.Mutex
+
+	// The BoltDB database, if the server is opened by OpenWithBolt.
+	db *BoltDB
 }
 
+// NewServer creates an in-memory server.
 func NewServer() *Server {
-	return &Server{slots: make(map[int]*Slot)}
+	return NewServerWithStore(NewRadixStore)
+}
+
+// NewServerWithStore creates a server, whose slots are stored in the stores
+// created by newStore.
+func NewServerWithStore(newStore NewStore) *Server {
+	return &Server{
+		slots:    make(map[int]*Slot),
+		newStore: newStore,
+	}
+}
+
+// Open creates a durable server, whose data is persisted in dir by using
+// a write-ahead log and per-slot snapshots. The existing data in dir, if
+// any, is recovered first.
+func Open(dir string, policy SyncPolicy) (*Server, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	s := NewServer()
+	if err := s.recover(dir); err != nil {
+		return nil, err
+	}
+
+	l, err := openWAL(dir, policy)
+	if err != nil {
+		return nil, err
+	}
+	s.dir = dir
+	s.wal = l
+
+	return s, nil
+}
+
+// recover loads the snapshots in dir, and then replays the write-ahead
+// log on top of them. Since the insertions and deletions of an LWW set
+// are idempotent and commutative, the records in the log that have
+// already been included in the snapshots do no harm.
+func (s *Server) recover(dir string) error {
+	apply := func(r *record) (err error) {
+		switch r.Op {
+		case opInsert:
+			_, err = s.Insert(r.SlotID, r.Key, r.Member, r.Timestamp, r.TTL)
+		case opDelete:
+			_, err = s.Delete(r.SlotID, r.Key, r.Member, r.Timestamp)
+		case opPurge:
+			_, err = s.Purge(r.SlotID, r.Key, []common.Element{{Member: r.Member, Timestamp: r.Timestamp}})
+		case opDrop:
+			err = s.DropSlot(r.SlotID)
+		default:
+			err = fmt.Errorf("unrecognized record op: %d", r.Op)
+		}
+		return err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, snapshotPrefix+"*"+snapshotSuffix))
+	if err != nil {
+		return err
+	}
+	for _, p := range paths {
+		if err := loadSnapshot(p, apply); err != nil {
+			return err
+		}
+	}
+
+	segments, err := walSegments(dir)
+	if err != nil {
+		return err
+	}
+	for _, seg := range segments {
+		f, err := os.Open(seg.path)
+		if err != nil {
+			return err
+		}
+		err = replay(seg.path, bufio.NewReader(f), apply)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Snapshot writes the snapshots of all the slots, and then removes the
+// write-ahead log covered by the snapshots.
+func (s *Server) Snapshot() error {
+	if s.wal == nil {
+		return nil
+	}
+
+	s.snapMu.Lock()
+	defer s.snapMu.Unlock()
+
+	// Every record in the closed segments has been applied before the
+	// rotation, and thus will be included in the snapshots.
+	seq, err := s.wal.Rotate()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UnixNano()
+	for slotID, slot := range s.Slots() {
+		if err := writeSnapshot(s.dir, slotID, slot, now); err != nil {
+			return err
+		}
+	}
+
+	return s.wal.Truncate(seq)
+}
+
+// Close stops all the background workers, and closes the write-ahead log
+// or the BoltDB database if the server is durable.
+func (s *Server) Close() error {
+	s.StopX()
+	if s.db != nil {
+		return s.db.Close()
+	}
+	if s.wal == nil {
+		return nil
+	}
+	return s.wal.Close()
+}
+
+// StartX starts the given background workers, which will be stopped
+// when StopX is called.
+func (s *Server) StartX(workers ...Worker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, w := range workers {
+		w.Start()
+		s.workers = append(s.workers, w)
+	}
 }
 
-func (s *Server) StartX() {}
+// StopX stops all the background workers started by StartX.
+func (s *Server) StopX() {
+	s.mu.Lock()
+	workers := s.workers
+	s.workers = nil
+	s.mu.Unlock()
+
+	for _, w := range workers {
+		w.Stop()
+	}
+}
 
 func (s *Server) Slot(slotID int) *Slot {
 	s.mu.RLock()
@@ -39,7 +239,7 @@ func (s *Server) Slot(slotID int) *Slot {
 		s.mu.Lock()
 		slot, ok = s.slots[slotID]
 		if !ok {
-			slot = &Slot{Store: radix.New()}
+			slot = newSlot(s.newStore(slotID))
 			s.slots[slotID] = slot
 		}
 		s.mu.Unlock()
@@ -47,61 +247,302 @@ func (s *Server) Slot(slotID int) *Slot {
 	return slot
 }
 
-func (s *Server) Key(slotID int, key string) string {
-	return fmt.Sprintf("%02d%s", slotID, key)
+// Slots returns a copy of all the existing slots.
+func (s *Server) Slots() map[int]*Slot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	slots := make(map[int]*Slot, len(s.slots))
+	for id, slot := range s.slots {
+		slots[id] = slot
+	}
+	return slots
 }
 
-func (s *Server) KeyMember(slotID int, key, member string) string {
-	return fmt.Sprintf("%02d%s%s", slotID, key, member)
+// log appends the record to the write-ahead log, if the server is durable.
+func (s *Server) log(r *record) error {
+	if s.wal == nil {
+		return nil
+	}
+	return s.wal.Append(r)
 }
 
+// Insert adds member into the set at key, if the given timestamp is not
+// older than that of the last operation on member. The tombstone wins if
+// an insertion and a deletion have the same timestamp.
+//
+// Insert returns true if an existing member has been updated, or false if
+// member is newly added or the insertion is stale.
 func (s *Server) Insert(slotID int, key, member string, timestamp int64, ttl time.Duration) (bool, error) {
 	slot := s.Slot(slotID)
-	k := s.KeyMember(slotID, key, member)
+	k := encodeKeyMember(key, member)
 
 	slot.Mu.Lock()
-	_, updated := slot.Store.Insert(k, Element{Timestamp: timestamp, TTL: ttl})
-	slot.Mu.Unlock()
+	defer slot.Mu.Unlock()
+
+	old, ok, err := slot.Store.Get(k)
+	if err != nil {
+		return false, err
+	}
+
+	var (
+		updated bool
+		prev    *Element
+	)
+	if ok {
+		if timestamp < old.Timestamp || (timestamp == old.Timestamp && old.Deleted) {
+			return false, nil
+		}
+		updated = !old.Deleted
+		prev = &old
+	}
+
+	r := &record{Op: opInsert, SlotID: slotID, Key: key, Member: member, Timestamp: timestamp, TTL: ttl}
+	if err := s.log(r); err != nil {
+		return false, err
+	}
 
+	e := Element{Timestamp: timestamp, TTL: ttl}
+	if err := slot.put(k, prev, e); err != nil {
+		return false, err
+	}
+	if ttl > 0 {
+		slot.expires.push(k, e)
+	} else {
+		slot.expires.remove(k)
+	}
 	return updated, nil
 }
 
+// Delete removes member from the set at key, if the given timestamp is not
+// older than that of the last operation on member. A tombstone is always
+// left in place of the member, even if the member does not exist yet.
+//
+// Delete returns true if an existing member has been removed, or false if
+// there is no such member or the deletion is stale.
 func (s *Server) Delete(slotID int, key, member string, timestamp int64) (bool, error) {
 	slot := s.Slot(slotID)
-	k := s.KeyMember(slotID, key, member)
+	k := encodeKeyMember(key, member)
 
 	slot.Mu.Lock()
-	_, deleted := slot.Store.Delete(k)
-	slot.Mu.Unlock()
+	defer slot.Mu.Unlock()
+
+	old, ok, err := slot.Store.Get(k)
+	if err != nil {
+		return false, err
+	}
+
+	var (
+		deleted bool
+		prev    *Element
+	)
+	if ok {
+		if timestamp < old.Timestamp {
+			return false, nil
+		}
+		deleted = !old.Deleted
+		prev = &old
+	}
 
+	r := &record{Op: opDelete, SlotID: slotID, Key: key, Member: member, Timestamp: timestamp}
+	if err := s.log(r); err != nil {
+		return false, err
+	}
+
+	if err := slot.put(k, prev, Element{Timestamp: timestamp, Deleted: true}); err != nil {
+		return false, err
+	}
+	slot.expires.remove(k)
 	return deleted, nil
 }
 
+// Select returns all the alive members of the set at key.
 func (s *Server) Select(slotID int, key string, timestamp int64) ([]common.Element, error) {
 	slot := s.Slot(slotID)
-	k := s.Key(slotID, key)
+	k := encodeKey(key)
 
-	var all []common.Element
+	var (
+		alive   []common.Element
+		expired []string
+	)
 	slot.Mu.RLock()
-	slot.Store.WalkPrefix(k, func(s string, v interface{}) bool {
-		member := s[len(k):]
-		e := v.(Element)
-		all = append(all, common.Element{Member: member, Timestamp: e.Timestamp, TTL: e.TTL})
+	err := slot.Store.WalkPrefix(k, func(s string, e Element) bool {
+		switch {
+		case e.Deleted:
+		case e.expired(timestamp):
+			expired = append(expired, s)
+		default:
+			alive = append(alive, common.Element{Member: s[len(k):], Timestamp: e.Timestamp, TTL: e.TTL})
+		}
 		return false
 	})
 	slot.Mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
 
-	var alive []common.Element
-	for _, e := range all {
-		if e.Timestamp+e.TTL.Nanoseconds() <= timestamp {
-			// If the element is expired, remove it from the set.
-			slot.Mu.Lock()
-			slot.Store.Delete(k + e.Member)
-			slot.Mu.Unlock()
-		} else {
-			alive = append(alive, e)
+	if len(expired) > 0 {
+		// If the elements are expired, remove them from the set.
+		slot.Mu.Lock()
+		defer slot.Mu.Unlock()
+		for _, s := range expired {
+			// The element may have been updated in the meantime.
+			e, ok, err := slot.Store.Get(s)
+			if err != nil {
+				return nil, err
+			}
+			if ok && e.expired(timestamp) {
+				if err := slot.remove(s, e); err != nil {
+					return nil, err
+				}
+			}
 		}
 	}
 
 	return alive, nil
 }
+
+// Dump returns all the elements of the set at key, including the tombstones
+// but excluding the expired elements.
+func (s *Server) Dump(slotID int, key string, timestamp int64) ([]common.Element, error) {
+	slot := s.Slot(slotID)
+	k := encodeKey(key)
+
+	var elements []common.Element
+	slot.Mu.RLock()
+	defer slot.Mu.RUnlock()
+	err := slot.Store.WalkPrefix(k, func(s string, e Element) bool {
+		if !e.expired(timestamp) {
+			elements = append(elements, common.Element{
+				Member:    s[len(k):],
+				Timestamp: e.Timestamp,
+				TTL:       e.TTL,
+				Deleted:   e.Deleted,
+			})
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	return elements, nil
+}
+
+// Purge physically removes the given elements of the set at key, if they
+// are not newer than the stored ones. No tombstone is left, so Purge must
+// only be used for the sets that have been moved elsewhere.
+//
+// Purge returns the number of the removed elements.
+func (s *Server) Purge(slotID int, key string, elements []common.Element) (int, error) {
+	slot := s.Slot(slotID)
+
+	slot.Mu.Lock()
+	defer slot.Mu.Unlock()
+
+	n := 0
+	for _, e := range elements {
+		k := encodeKeyMember(key, e.Member)
+		old, ok, err := slot.Store.Get(k)
+		if err != nil {
+			return n, err
+		}
+		if !ok || old.Timestamp > e.Timestamp {
+			// Missing, or changed since it was dumped.
+			continue
+		}
+
+		r := &record{Op: opPurge, SlotID: slotID, Key: key, Member: e.Member, Timestamp: e.Timestamp}
+		if err := s.log(r); err != nil {
+			return n, err
+		}
+		if err := slot.remove(k, old); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// ScanKeys returns at most count distinct keys in the slot identified by
+// slotID, which are located from cursor in lexicographical order. It also
+// returns the cursor to continue with, which is empty if the scan is done.
+// The cursor is opaque, and the scan starts from the beginning if the
+// cursor is empty.
+//
+// The keys of the sets that only have tombstones are also returned.
+func (s *Server) ScanKeys(slotID int, cursor string, count int) (keys []string, next string, err error) {
+	if count <= 0 {
+		return nil, "", fmt.Errorf("count %d is not positive", count)
+	}
+
+	slot := s.Slot(slotID)
+
+	var (
+		more      bool
+		decodeErr error
+	)
+	slot.Mu.RLock()
+	err = slot.Store.WalkFrom(cursor, func(k string, e Element) bool {
+		key, _, err := decodeKeyMember(k)
+		if err != nil {
+			decodeErr = err
+			return true
+		}
+		if n := len(keys); n > 0 && keys[n-1] == key {
+			return false
+		}
+		if len(keys) == count {
+			more = true
+			return true
+		}
+		keys = append(keys, key)
+		return false
+	})
+	slot.Mu.RUnlock()
+	if err == nil {
+		err = decodeErr
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	if more {
+		next = encodeKeyEnd(keys[len(keys)-1])
+	}
+	return keys, next, nil
+}
+
+// Keys implements common.Scanner. It sends the distinct keys in the slot
+// identified by slotID in batches, and only holds the lock of the slot for
+// scanning each batch.
+func (s *Server) Keys(ctx context.Context, slotID, batchSize int) (<-chan []string, func() error) {
+	c := make(chan []string)
+	var err error
+	go func() {
+		defer close(c)
+
+		cursor := ""
+		for {
+			var keys []string
+			var next string
+			keys, next, err = s.ScanKeys(slotID, cursor, batchSize)
+			if err != nil {
+				err = fmt.Errorf("failed to scan keys in slot %d: %v", slotID, err)
+				return
+			}
+			if len(keys) > 0 {
+				select {
+				case c <- keys:
+				case <-ctx.Done():
+					err = ctx.Err()
+					return
+				}
+			}
+			if next == "" {
+				return
+			}
+			cursor = next
+		}
+	}()
+	return c, func() error { return err }
+}
@@ -1,10 +1,18 @@
 package server_test
 
 import (
+	"context"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
+	"sort"
 	"testing"
 	"time"
 
+	"github.com/boltdb/bolt"
+
 	"github.com/RussellLuo/goku/common"
 	"github.com/RussellLuo/goku/server"
 )
@@ -238,3 +246,621 @@ func TestServer_Select(t *testing.T) {
 		})
 	}
 }
+
+func TestServer_LastWriteWins(t *testing.T) {
+	ts := time.Now().UnixNano()
+
+	type op struct {
+		delete    bool
+		member    string
+		timestamp int64
+		want      bool
+	}
+
+	cases := []struct {
+		name string
+		ops  []op
+		want []common.Element
+	}{
+		{
+			name: "stale insert",
+			ops: []op{
+				{member: "member1", timestamp: ts + 1, want: false},
+				{member: "member1", timestamp: ts, want: false},
+			},
+			want: []common.Element{
+				{Member: "member1", Timestamp: ts + 1},
+			},
+		},
+		{
+			name: "stale delete",
+			ops: []op{
+				{member: "member1", timestamp: ts + 1, want: false},
+				{delete: true, member: "member1", timestamp: ts, want: false},
+			},
+			want: []common.Element{
+				{Member: "member1", Timestamp: ts + 1},
+			},
+		},
+		{
+			name: "delayed insert after delete",
+			ops: []op{
+				{delete: true, member: "member1", timestamp: ts + 1, want: false},
+				{member: "member1", timestamp: ts, want: false},
+			},
+			want: nil,
+		},
+		{
+			name: "delete wins on ties",
+			ops: []op{
+				{member: "member1", timestamp: ts, want: false},
+				{delete: true, member: "member1", timestamp: ts, want: true},
+				{member: "member1", timestamp: ts, want: false},
+			},
+			want: nil,
+		},
+		{
+			name: "insert after delete",
+			ops: []op{
+				{delete: true, member: "member1", timestamp: ts, want: false},
+				{member: "member1", timestamp: ts + 1, want: false},
+				{member: "member1", timestamp: ts + 2, want: true},
+			},
+			want: []common.Element{
+				{Member: "member1", Timestamp: ts + 2},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := server.NewServer()
+			for _, o := range c.ops {
+				var (
+					got bool
+					err error
+				)
+				if o.delete {
+					got, err = s.Delete(0, "key1", o.member, o.timestamp)
+				} else {
+					got, err = s.Insert(0, "key1", o.member, o.timestamp, 0)
+				}
+				if err != nil {
+					t.Fatalf("err: %+v", err)
+				}
+				if got != o.want {
+					t.Errorf("op(%+v): got(%+v) != want(%+v)", o, got, o.want)
+				}
+			}
+
+			elements, err := s.Select(0, "key1", ts+10)
+			if !reflect.DeepEqual(elements, c.want) {
+				t.Errorf("elements: got(%+v) != want(%+v)", elements, c.want)
+			}
+			if err != nil {
+				t.Errorf("err: got(%+v) != want(nil)", err)
+			}
+		})
+	}
+}
+
+func TestCollector_Collect(t *testing.T) {
+	s := server.NewServer()
+	ts := time.Now().UnixNano()
+	grace := time.Second
+
+	s.Insert(0, "key1", "member1", ts, 0)
+	s.Delete(0, "key1", "member2", ts)
+	s.Delete(1, "key1", "member3", ts+int64(grace))
+
+	c := server.NewCollector(s, grace, time.Minute)
+	if n := c.Collect(ts + int64(grace)); n != 0 {
+		t.Errorf("reclaimed: got(%d) != want(0)", n)
+	}
+	if n := c.Collect(ts + int64(grace) + 1); n != 1 {
+		t.Errorf("reclaimed: got(%d) != want(1)", n)
+	}
+	if n := c.Reclaimed(); n != 1 {
+		t.Errorf("total reclaimed: got(%d) != want(1)", n)
+	}
+
+	// The member is gone together with its tombstone, so an older
+	// insertion is no longer rejected.
+	if _, err := s.Insert(0, "key1", "member2", ts-1, 0); err != nil {
+		t.Fatalf("err: %+v", err)
+	}
+	elements, _ := s.Select(0, "key1", ts)
+	want := []common.Element{
+		{Member: "member1", Timestamp: ts},
+		{Member: "member2", Timestamp: ts - 1},
+	}
+	if !reflect.DeepEqual(elements, want) {
+		t.Errorf("elements: got(%+v) != want(%+v)", elements, want)
+	}
+}
+
+func TestExpirer_Expire(t *testing.T) {
+	s := server.NewServer()
+	ts := time.Now().UnixNano()
+
+	s.Insert(0, "key1", "member1", ts, 0)
+	s.Insert(0, "key1", "member2", ts, 10*time.Nanosecond)
+	s.Insert(0, "key2", "member1", ts, 10*time.Nanosecond)
+	s.Insert(1, "key1", "member1", ts, 20*time.Nanosecond)
+	s.Insert(1, "key1", "member2", ts, 10*time.Nanosecond)
+	// Updated and deleted elements must not be expired by their old TTLs.
+	s.Insert(1, "key1", "member2", ts+1, 20*time.Nanosecond)
+	s.Insert(1, "key2", "member1", ts, 10*time.Nanosecond)
+	s.Delete(1, "key2", "member1", ts+1)
+
+	e := server.NewExpirer(s, time.Minute, 1)
+	if n := e.Expire(ts + 10); n != 2 {
+		t.Errorf("expired: got(%d) != want(2)", n)
+	}
+	if n := e.Expire(ts + 21); n != 2 {
+		t.Errorf("expired: got(%d) != want(2)", n)
+	}
+	if n := e.Expired(); n != 4 {
+		t.Errorf("total expired: got(%d) != want(4)", n)
+	}
+
+	// The refreshed elements are expired once by their latest TTLs.
+	for i := int64(0); i < 3; i++ {
+		s.Insert(2, "key1", "member1", ts+i, 10*time.Nanosecond)
+	}
+	if n := e.Expire(ts + 11); n != 0 {
+		t.Errorf("expired: got(%d) != want(0)", n)
+	}
+	// The non-positive batch size expires one element at a time.
+	if n := server.NewExpirer(s, time.Minute, 0).Expire(ts + 12); n != 1 {
+		t.Errorf("expired: got(%d) != want(1)", n)
+	}
+
+	elements, _ := s.Select(0, "key1", ts)
+	want := []common.Element{
+		{Member: "member1", Timestamp: ts},
+	}
+	if !reflect.DeepEqual(elements, want) {
+		t.Errorf("elements: got(%+v) != want(%+v)", elements, want)
+	}
+}
+
+func TestOpen_Recover(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goku-server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ts := time.Now().UnixNano()
+	open := func() *server.Server {
+		s, err := server.Open(dir, server.SyncAlways)
+		if err != nil {
+			t.Fatalf("failed to open: %+v", err)
+		}
+		return s
+	}
+	validate := func(s *server.Server, want []common.Element) {
+		elements, _ := s.Select(0, "key1", ts)
+		if !reflect.DeepEqual(elements, want) {
+			t.Errorf("elements: got(%+v) != want(%+v)", elements, want)
+		}
+	}
+
+	// Recover from the write-ahead log only.
+	s := open()
+	s.Insert(0, "key1", "member1", ts, 0)
+	s.Insert(0, "key1", "member2", ts, 2*time.Second)
+	s.Delete(0, "key1", "member3", ts)
+	s.Close()
+
+	s = open()
+	validate(s, []common.Element{
+		{Member: "member1", Timestamp: ts},
+		{Member: "member2", Timestamp: ts, TTL: 2 * time.Second},
+	})
+
+	// Recover from the snapshots and the write-ahead log.
+	if err := s.Snapshot(); err != nil {
+		t.Fatalf("failed to snapshot: %+v", err)
+	}
+	s.Delete(0, "key1", "member1", ts+1)
+	s.Close()
+
+	s = open()
+	validate(s, []common.Element{
+		{Member: "member2", Timestamp: ts, TTL: 2 * time.Second},
+	})
+	// The tombstone has been recovered from the snapshot.
+	if _, err := s.Insert(0, "key1", "member3", ts-1, 0); err != nil {
+		t.Fatalf("err: %+v", err)
+	}
+	s.Close()
+
+	// Ignore the torn record at the tail of the write-ahead log.
+	matches, _ := filepath.Glob(filepath.Join(dir, "wal-*.log"))
+	f, err := os.OpenFile(matches[len(matches)-1], os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{0, 0, 0, 42, 1, 2})
+	f.Close()
+
+	s = open()
+	validate(s, []common.Element{
+		{Member: "member2", Timestamp: ts, TTL: 2 * time.Second},
+	})
+	s.Close()
+}
+
+func TestServer_BoltStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goku-server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "goku.db")
+	ts := time.Now().UnixNano()
+
+	db, err := server.OpenBolt(path)
+	if err != nil {
+		t.Fatalf("failed to open bolt: %+v", err)
+	}
+	s := server.NewServerWithStore(db.NewStore)
+	s.Insert(0, "key1", "member1", ts, 0)
+	s.Insert(0, "key1", "member2", ts, 2*time.Second)
+	s.Insert(0, "key2", "member1", ts, 0)
+	s.Delete(0, "key1", "member3", ts)
+	s.Insert(1, "key1", "member1", ts, 0)
+	db.Close()
+
+	db, err = server.OpenBolt(path)
+	if err != nil {
+		t.Fatalf("failed to reopen bolt: %+v", err)
+	}
+	defer db.Close()
+
+	ids, err := db.SlotIDs()
+	if !reflect.DeepEqual(ids, []int{0, 1}) {
+		t.Errorf("slot ids: got(%+v) != want(%+v)", ids, []int{0, 1})
+	}
+
+	s = server.NewServerWithStore(db.NewStore)
+	elements, err := s.Select(0, "key1", ts)
+	want := []common.Element{
+		{Member: "member1", Timestamp: ts},
+		{Member: "member2", Timestamp: ts, TTL: 2 * time.Second},
+	}
+	if !reflect.DeepEqual(elements, want) {
+		t.Errorf("elements: got(%+v) != want(%+v)", elements, want)
+	}
+	if err != nil {
+		t.Errorf("err: got(%+v) != want(nil)", err)
+	}
+
+	// The tombstone has been persisted.
+	if _, err := s.Insert(0, "key1", "member3", ts-1, 0); err != nil {
+		t.Fatalf("err: %+v", err)
+	}
+	if n, _ := s.Slot(0).Store.Len(); n != 4 {
+		t.Errorf("len: got(%d) != want(4)", n)
+	}
+}
+
+func TestOpenWithBolt(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goku-server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "goku.db")
+	ts := time.Now().UnixNano()
+
+	s, err := server.OpenWithBolt(path)
+	if err != nil {
+		t.Fatalf("failed to open server: %+v", err)
+	}
+	s.Insert(0, "key1", "member1", ts, 0)
+	s.Insert(1, "key1", "member1", ts, 0)
+	if err := s.Close(); err != nil {
+		t.Fatalf("err: got(%+v) != want(nil)", err)
+	}
+
+	// The database could not be reopened if it were not closed.
+	s, err = server.OpenWithBolt(path)
+	if err != nil {
+		t.Fatalf("failed to reopen server: %+v", err)
+	}
+	defer s.Close()
+
+	// The existing slots have been loaded.
+	var ids []int
+	for id := range s.Slots() {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	if !reflect.DeepEqual(ids, []int{0, 1}) {
+		t.Errorf("slot ids: got(%+v) != want(%+v)", ids, []int{0, 1})
+	}
+}
+
+func TestServer_KeyCollision(t *testing.T) {
+	s := server.NewServer()
+	ts := time.Now().UnixNano()
+
+	s.Insert(1, "0a", "member1", ts, 0)
+	s.Insert(10, "a", "member2", ts, 0)
+	s.Insert(0, "ab", "c", ts, 0)
+	s.Insert(0, "a", "bc", ts, 0)
+	s.Insert(0, "a\x00", "\x01b", ts, 0)
+	s.Insert(0, "a\x00\x01", "b", ts, 0)
+
+	cases := []struct {
+		slotID int
+		key    string
+		want   []common.Element
+	}{
+		{slotID: 1, key: "0a", want: []common.Element{{Member: "member1", Timestamp: ts}}},
+		{slotID: 10, key: "a", want: []common.Element{{Member: "member2", Timestamp: ts}}},
+		{slotID: 0, key: "ab", want: []common.Element{{Member: "c", Timestamp: ts}}},
+		{slotID: 0, key: "a", want: []common.Element{{Member: "bc", Timestamp: ts}}},
+		{slotID: 0, key: "a\x00", want: []common.Element{{Member: "\x01b", Timestamp: ts}}},
+		{slotID: 0, key: "a\x00\x01", want: []common.Element{{Member: "b", Timestamp: ts}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.key, func(t *testing.T) {
+			elements, _ := s.Select(c.slotID, c.key, ts)
+			if !reflect.DeepEqual(elements, c.want) {
+				t.Errorf("elements: got(%+v) != want(%+v)", elements, c.want)
+			}
+		})
+	}
+}
+
+func TestOpenBolt_MigrateLegacyLayout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goku-server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "goku.db")
+	ts := time.Now().UnixNano()
+
+	// Write the elements in the legacy layout, where the element is
+	// encoded as (timestamp, ttl, deleted, member).
+	legacy := func(ttl time.Duration, deleted byte, member string) []byte {
+		buf := make([]byte, 2*binary.MaxVarintLen64)
+		n := binary.PutVarint(buf, ts)
+		n += binary.PutVarint(buf[n:], int64(ttl))
+		return append(append(buf[:n], deleted), member...)
+	}
+	db, err := bolt.Open(path, 0644, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		b, _ := tx.CreateBucket([]byte("10"))
+		b.Put([]byte("10abc"), legacy(0, 0, "c"))
+		b.Put([]byte("10abc2"), legacy(2*time.Second, 0, "bc2"))
+		b.Put([]byte("10ab3"), legacy(0, 1, "b3"))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	bdb, err := server.OpenBolt(path)
+	if err != nil {
+		t.Fatalf("failed to open bolt: %+v", err)
+	}
+	defer bdb.Close()
+
+	s := server.NewServerWithStore(bdb.NewStore)
+	validate := func(key string, want []common.Element) {
+		elements, _ := s.Select(10, key, ts)
+		if !reflect.DeepEqual(elements, want) {
+			t.Errorf("elements of %s: got(%+v) != want(%+v)", key, elements, want)
+		}
+	}
+	validate("ab", []common.Element{{Member: "c", Timestamp: ts}})
+	validate("a", []common.Element{{Member: "bc2", Timestamp: ts, TTL: 2 * time.Second}})
+
+	// The tombstone has been migrated.
+	s.Insert(10, "a", "b3", ts-1, 0)
+	validate("a", []common.Element{{Member: "bc2", Timestamp: ts, TTL: 2 * time.Second}})
+}
+
+func TestServer_Keys(t *testing.T) {
+	ts := time.Now().UnixNano()
+
+	newServers := func(t *testing.T) (map[string]*server.Server, func()) {
+		dir, err := ioutil.TempDir("", "goku-server")
+		if err != nil {
+			t.Fatal(err)
+		}
+		db, err := server.OpenBolt(filepath.Join(dir, "goku.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		servers := map[string]*server.Server{
+			"radix": server.NewServer(),
+			"bolt":  server.NewServerWithStore(db.NewStore),
+		}
+		return servers, func() {
+			db.Close()
+			os.RemoveAll(dir)
+		}
+	}
+
+	servers, cleanup := newServers(t)
+	defer cleanup()
+
+	for name, s := range servers {
+		t.Run(name, func(t *testing.T) {
+			for _, key := range []string{"b", "a", "ab", "a\x00", "c"} {
+				s.Insert(0, key, "member1", ts, 0)
+				s.Insert(0, key, "member2", ts, 0)
+			}
+			// Keys with only tombstones are also returned.
+			s.Delete(0, "d", "member1", ts)
+			s.Insert(1, "e", "member1", ts, 0)
+
+			var batches [][]string
+			c, errFn := s.Keys(context.Background(), 0, 2)
+			for keys := range c {
+				batches = append(batches, keys)
+			}
+			want := [][]string{{"a", "a\x00"}, {"ab", "b"}, {"c", "d"}}
+			if !reflect.DeepEqual(batches, want) {
+				t.Errorf("batches: got(%q) != want(%q)", batches, want)
+			}
+			if err := errFn(); err != nil {
+				t.Errorf("err: got(%v) != want(<nil>)", err)
+			}
+
+			// The non-positive batch size is rejected.
+			if _, _, err := s.ScanKeys(0, "", 0); err == nil {
+				t.Errorf("err(%v) != wantErr(true)", err)
+			}
+			// A failed scan is reported instead of looking finished.
+			c, errFn = s.Keys(context.Background(), 0, 0)
+			if keys, ok := <-c; ok {
+				t.Errorf("keys: got(%q) != want(<closed>)", keys)
+			}
+			if err := errFn(); err == nil {
+				t.Errorf("err(%v) != wantErr(true)", err)
+			}
+
+			// The scan stops once the consumer cancels it.
+			ctx, cancel := context.WithCancel(context.Background())
+			c, errFn = s.Keys(ctx, 0, 1)
+			<-c
+			cancel()
+			time.Sleep(100 * time.Millisecond)
+			if keys, ok := <-c; ok {
+				t.Errorf("keys: got(%q) != want(<closed>)", keys)
+			}
+			if err := errFn(); err != context.Canceled {
+				t.Errorf("err: got(%v) != want(%v)", err, context.Canceled)
+			}
+		})
+	}
+}
+
+func TestServer_ExportSlot(t *testing.T) {
+	ts := time.Now().UnixNano()
+
+	dir, err := ioutil.TempDir("", "goku-server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src, err := server.Open(dir, server.SyncAlways)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src.Insert(0, "a", "member1", ts, 0)
+	src.Insert(0, "a\x00", "member1", ts, time.Hour)
+	src.Delete(0, "b", "member1", ts)
+	// Expired elements are not exported.
+	src.Insert(0, "c", "member1", ts-int64(2*time.Second), time.Second)
+	src.Insert(1, "d", "member1", ts, 0)
+
+	var (
+		got    []server.Entry
+		cursor string
+	)
+	for {
+		entries, next, err := src.ExportSlot(0, cursor, 2, ts)
+		if err != nil {
+			t.Fatalf("err: got(%+v) != want(<nil>)", err)
+		}
+		got = append(got, entries...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	want := []server.Entry{
+		{Key: "a", Element: common.Element{Member: "member1", Timestamp: ts}},
+		{Key: "a\x00", Element: common.Element{Member: "member1", Timestamp: ts, TTL: time.Hour}},
+		{Key: "b", Element: common.Element{Member: "member1", Timestamp: ts, Deleted: true}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("entries: got(%+v) != want(%+v)", got, want)
+	}
+
+	// Import into another server, which has a newer deletion.
+	dst := server.NewServer()
+	dst.Delete(0, "a", "member1", ts+1)
+	if err := dst.ImportSlot(0, got); err != nil {
+		t.Fatalf("err: got(%+v) != want(<nil>)", err)
+	}
+	elements, _ := dst.Dump(0, "a", ts)
+	wantElements := []common.Element{{Member: "member1", Timestamp: ts + 1, Deleted: true}}
+	if !reflect.DeepEqual(elements, wantElements) {
+		t.Errorf("elements: got(%+v) != want(%+v)", elements, wantElements)
+	}
+
+	// The dropped slot stays empty after recovery.
+	if err := src.DropSlot(0); err != nil {
+		t.Fatalf("err: got(%+v) != want(<nil>)", err)
+	}
+	src.Close()
+	src, err = server.Open(dir, server.SyncAlways)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	for slotID, want := range map[int]int{0: 0, 1: 1} {
+		entries, _, _ := src.ExportSlot(slotID, "", 10, ts)
+		if len(entries) != want {
+			t.Errorf("entries in slot %d: got(%+v) != want(%+v)", slotID, len(entries), want)
+		}
+	}
+}
+
+func TestServer_Tree(t *testing.T) {
+	ts := time.Now().UnixNano()
+	root := []int{1}
+
+	s1 := server.NewServer()
+	s2 := server.NewServer()
+	if got := s1.Tree(0, root); !reflect.DeepEqual(got, []uint64{0}) {
+		t.Errorf("empty root: got(%+v) != want(%+v)", got, []uint64{0})
+	}
+
+	// The order of the writes does not matter.
+	s1.Insert(0, "a", "member1", ts, 0)
+	s1.Insert(0, "b", "member1", ts, time.Hour)
+	s2.Insert(0, "b", "member1", ts, time.Hour)
+	s2.Insert(0, "a", "member1", ts, 0)
+	if got, want := s1.Tree(0, root), s2.Tree(0, root); !reflect.DeepEqual(got, want) {
+		t.Errorf("root: got(%+v) != want(%+v)", got, want)
+	}
+
+	// A tombstone makes a difference, which is found in the leaf of its set.
+	s2.Delete(0, "a", "member1", ts+1)
+	leaf := []int{server.TreeLeaf("a")}
+	if got, want := s1.Tree(0, leaf), s2.Tree(0, leaf); reflect.DeepEqual(got, want) {
+		t.Errorf("leaf: got(%+v) == want(%+v)", got, want)
+	}
+	other := []int{server.TreeLeaf("b")}
+	if got, want := s1.Tree(0, other), s2.Tree(0, other); !reflect.DeepEqual(got, want) {
+		t.Errorf("leaf: got(%+v) != want(%+v)", got, want)
+	}
+
+	// The tree of an emptied slot is empty again.
+	s2.DropSlot(0)
+	s2.Insert(0, "a", "member1", ts, 0)
+	s2.Purge(0, "a", []common.Element{{Member: "member1", Timestamp: ts}})
+	if got := s2.Tree(0, root); !reflect.DeepEqual(got, []uint64{0}) {
+		t.Errorf("emptied root: got(%+v) != want(%+v)", got, []uint64{0})
+	}
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/RussellLuo/goku/internal/worker"
+)
+
+const (
+	snapshotPrefix  = "slot-"
+	snapshotSuffix  = ".snap"
+	snapshotMagic   = "GOKUSNAP"
+	snapshotVersion = 1
+)
+
+func snapshotPath(dir string, slotID int) string {
+	return filepath.Join(dir, fmt.Sprintf("%s%04d%s", snapshotPrefix, slotID, snapshotSuffix))
+}
+
+// writeSnapshot writes all the elements (including the tombstones) of slot
+// into its snapshot file. The elements are copied under the read lock of
+// the slot, and then written without holding the lock.
+//
+// The snapshot consists of records of (key, member) pairs rather than the
+// store keys, so it does not depend on the layout of the store keys, and
+// can always be loaded into a server with a different layout.
+func writeSnapshot(dir string, slotID int, slot *Slot, timestamp int64) error {
+	var (
+		buf bytes.Buffer
+		rec []byte
+	)
+	buf.WriteString(snapshotMagic)
+	binary.Write(&buf, binary.BigEndian, uint32(snapshotVersion))
+
+	slot.Mu.RLock()
+	var decodeErr error
+	err := slot.Store.Walk(func(k string, e Element) bool {
+		if e.expired(timestamp) {
+			return false
+		}
+		key, member, err := decodeKeyMember(k)
+		if err != nil {
+			decodeErr = err
+			return true
+		}
+		r := &record{
+			Op:        opInsert,
+			SlotID:    slotID,
+			Key:       key,
+			Member:    member,
+			Timestamp: e.Timestamp,
+			TTL:       e.TTL,
+		}
+		if e.Deleted {
+			r.Op = opDelete
+		}
+		rec = encodeRecord(rec, r)
+		buf.Write(rec)
+		return false
+	})
+	slot.Mu.RUnlock()
+	if err == nil {
+		err = decodeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, and then rename it, to ensure that
+	// the snapshot file is either the old one or the new one.
+	path := snapshotPath(dir, slotID)
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := buf.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// loadSnapshot applies all the records in the snapshot file at path by
+// calling fn.
+func loadSnapshot(path string, fn func(*record) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	header := make([]byte, len(snapshotMagic)+4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return fmt.Errorf("snapshot %s: %s", path, err)
+	}
+	if string(header[:len(snapshotMagic)]) != snapshotMagic {
+		return fmt.Errorf("snapshot %s: bad magic", path)
+	}
+	if v := binary.BigEndian.Uint32(header[len(snapshotMagic):]); v != snapshotVersion {
+		return fmt.Errorf("snapshot %s: unsupported version %d", path, v)
+	}
+
+	return replay(path, r, fn)
+}
+
+// Snapshotter is a background worker that periodically snapshots the
+// server, which keeps the write-ahead log short.
+type Snapshotter struct {
+	*worker.Ticker
+}
+
+// NewSnapshotter creates a Snapshotter, which snapshots server every
+// interval.
+func NewSnapshotter(server *Server, interval time.Duration) *Snapshotter {
+	return &Snapshotter{
+		Ticker: worker.NewTicker(interval, func() {
+			if err := server.Snapshot(); err != nil {
+				log.Printf("failed to snapshot: %v", err)
+			}
+		}),
+	}
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"github.com/armon/go-radix"
+)
+
+// WalkFn is called for each element visited by a walk. The walk stops
+// if it returns true.
+type WalkFn func(k string, e Element) bool
+
+// Store is the storage backend of a slot, which maps the store keys to the
+// elements in lexicographical order.
+//
+// A Store is not required to be safe for concurrent use, since the accesses
+// to it are guarded by the lock of its slot.
+type Store interface {
+	// Get returns the element at k, and reports whether it exists.
+	Get(k string) (Element, bool, error)
+	// Insert puts the element e at k, which overwrites the existing one.
+	Insert(k string, e Element) error
+	// Delete removes the element at k, if any.
+	Delete(k string) error
+	// WalkPrefix visits all the elements whose keys start with prefix,
+	// in lexicographical order.
+	WalkPrefix(prefix string, fn WalkFn) error
+	// WalkFrom visits all the elements whose keys are not less than start,
+	// in lexicographical order.
+	WalkFrom(start string, fn WalkFn) error
+	// Walk visits all the elements in lexicographical order.
+	Walk(fn WalkFn) error
+	// Len returns the number of elements.
+	Len() (int, error)
+}
+
+// NewStore represents a store constructor, which creates the store for
+// the slot identified by slotID.
+type NewStore func(slotID int) Store
+
+// radixStore is a memory-bound Store, which is built on a radix tree.
+type radixStore struct {
+	tree *radix.Tree
+}
+
+// NewRadixStore creates an in-memory store for a slot.
+func NewRadixStore(slotID int) Store {
+	return &radixStore{tree: radix.New()}
+}
+
+func (s *radixStore) Get(k string) (Element, bool, error) {
+	v, ok := s.tree.Get(k)
+	if !ok {
+		return Element{}, false, nil
+	}
+	return v.(Element), true, nil
+}
+
+func (s *radixStore) Insert(k string, e Element) error {
+	s.tree.Insert(k, e)
+	return nil
+}
+
+func (s *radixStore) Delete(k string) error {
+	s.tree.Delete(k)
+	return nil
+}
+
+func (s *radixStore) WalkPrefix(prefix string, fn WalkFn) error {
+	s.tree.WalkPrefix(prefix, func(k string, v interface{}) bool {
+		return fn(k, v.(Element))
+	})
+	return nil
+}
+
+// WalkFrom is implemented by walking prefixes, since the radix tree does not
+// support seeking. A key k is greater than start if either k starts with
+// start, or k[:i] == start[:i] and k[i] > start[i] for some i.
+func (s *radixStore) WalkFrom(start string, fn WalkFn) error {
+	stopped := false
+	walk := func(prefix string) {
+		s.tree.WalkPrefix(prefix, func(k string, v interface{}) bool {
+			stopped = fn(k, v.(Element))
+			return stopped
+		})
+	}
+
+	walk(start)
+	for i := len(start) - 1; i >= 0 && !stopped; i-- {
+		for c := int(start[i]) + 1; c <= 0xff && !stopped; c++ {
+			walk(start[:i] + string([]byte{byte(c)}))
+		}
+	}
+	return nil
+}
+
+func (s *radixStore) Walk(fn WalkFn) error {
+	s.tree.Walk(func(k string, v interface{}) bool {
+		return fn(k, v.(Element))
+	})
+	return nil
+}
+
+func (s *radixStore) Len() (int, error) {
+	return s.tree.Len(), nil
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"github.com/RussellLuo/goku/common"
+)
+
+// Entry is an element along with the key of its set, which is the unit of
+// transferring a slot between servers.
+type Entry struct {
+	Key     string
+	Element common.Element
+}
+
+// ExportSlot returns at most count entries in the slot identified by
+// slotID, including the tombstones but excluding the expired elements,
+// which are located from cursor in lexicographical order. It also returns
+// the cursor to continue with, which is empty if the export is done. The
+// cursor is opaque, and the export starts from the beginning if the cursor
+// is empty.
+//
+// The lock of the slot is only held for exporting each batch, so the slot
+// stays writable during a long transfer.
+func (s *Server) ExportSlot(slotID int, cursor string, count int, timestamp int64) (entries []Entry, next string, err error) {
+	slot := s.Slot(slotID)
+
+	var (
+		last      string
+		more      bool
+		decodeErr error
+	)
+	slot.Mu.RLock()
+	err = slot.Store.WalkFrom(cursor, func(k string, e Element) bool {
+		if len(entries) == count {
+			more = true
+			return true
+		}
+		last = k
+		if e.expired(timestamp) {
+			return false
+		}
+		key, member, err := decodeKeyMember(k)
+		if err != nil {
+			decodeErr = err
+			return true
+		}
+		entries = append(entries, Entry{
+			Key: key,
+			Element: common.Element{
+				Member:    member,
+				Timestamp: e.Timestamp,
+				TTL:       e.TTL,
+				Deleted:   e.Deleted,
+			},
+		})
+		return false
+	})
+	slot.Mu.RUnlock()
+	if err == nil {
+		err = decodeErr
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	if more {
+		// The smallest store key that is greater than last.
+		next = last + "\x00"
+	}
+	return entries, next, nil
+}
+
+// ImportSlot applies the entries exported from another server to the slot
+// identified by slotID. Since the entries are applied as ordinary LWW
+// operations, importing the same entries repeatedly is harmless, and the
+// entries never override any newer write.
+func (s *Server) ImportSlot(slotID int, entries []Entry) error {
+	for _, en := range entries {
+		e := en.Element
+		var err error
+		if e.Deleted {
+			_, err = s.Delete(slotID, en.Key, e.Member, e.Timestamp)
+		} else {
+			_, err = s.Insert(slotID, en.Key, e.Member, e.Timestamp, e.TTL)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DropSlot physically removes all the elements, including the tombstones,
+// in the slot identified by slotID. It must only be used for the slots that
+// have been moved elsewhere.
+func (s *Server) DropSlot(slotID int) error {
+	slot := s.Slot(slotID)
+
+	slot.Mu.Lock()
+	defer slot.Mu.Unlock()
+
+	if err := s.log(&record{Op: opDrop, SlotID: slotID}); err != nil {
+		return err
+	}
+
+	var keys []string
+	err := slot.Store.Walk(func(k string, e Element) bool {
+		keys = append(keys, k)
+		return false
+	})
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if err := slot.Store.Delete(k); err != nil {
+			return err
+		}
+	}
+	slot.expires = expiryQueue{}
+	slot.tree = hashTree{}
+	return nil
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"strings"
+)
+
+// TreeLeaves is the number of the leaves in the hash tree of a slot.
+//
+// The hash tree (a.k.a. Merkle tree) of a slot is a complete binary tree,
+// whose nodes are numbered from 1 (the root) in breadth-first order, i.e.
+// the children of node i are 2i and 2i+1, and the leaves are numbered from
+// TreeLeaves to 2*TreeLeaves-1. The sets in the slot are distributed among
+// the leaves by their keys (see TreeLeaf), and the hash of a leaf combines
+// the hashes of all the elements (including the tombstones) of its sets.
+// The hash of an internal node is the hash of those of its children.
+//
+// Two replicas of a slot hold the same elements if their roots have the
+// same hash, otherwise the differing sets can be found by descending into
+// the differing children.
+const TreeLeaves = 256
+
+type hashTree struct {
+	nodes [2 * TreeLeaves]uint64
+}
+
+// TreeLeaf returns the leaf that the set at key belongs to, which is
+// numbered as a tree node.
+func TreeLeaf(key string) int {
+	return treeLeaf(encodeKey(key))
+}
+
+// treeLeaf returns the leaf that the store key k belongs to.
+func treeLeaf(k string) int {
+	if i := strings.Index(k, keyTerminator); i >= 0 {
+		k = k[:i]
+	}
+	h := fnv.New32a()
+	h.Write([]byte(k))
+	return TreeLeaves + int(h.Sum32()%TreeLeaves)
+}
+
+func hashElement(k string, e Element) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(k))
+	h.Write(encodeElement(e))
+	return h.Sum64()
+}
+
+// update replaces the element old (if any) with the element e (if any) at
+// the store key k.
+func (t *hashTree) update(k string, old, e *Element) {
+	i := treeLeaf(k)
+	// The elements are combined by XOR, which is independent of the
+	// order in which they are added.
+	if old != nil {
+		t.nodes[i] ^= hashElement(k, *old)
+	}
+	if e != nil {
+		t.nodes[i] ^= hashElement(k, *e)
+	}
+
+	for i /= 2; i >= 1; i /= 2 {
+		t.nodes[i] = hashChildren(t.nodes[2*i], t.nodes[2*i+1])
+	}
+}
+
+// hashChildren returns the hash of a node from those of its children. The
+// hash of an empty subtree is always zero, no matter whether the subtree
+// has ever had any element.
+func hashChildren(left, right uint64) uint64 {
+	if left == 0 && right == 0 {
+		return 0
+	}
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[:8], left)
+	binary.BigEndian.PutUint64(buf[8:], right)
+	h := fnv.New64a()
+	h.Write(buf[:])
+	return h.Sum64()
+}
+
+// put stores the element e at the store key k, in place of old (if any),
+// and keeps the hash tree up to date. The caller must hold s.Mu.
+func (s *Slot) put(k string, old *Element, e Element) error {
+	if err := s.Store.Insert(k, e); err != nil {
+		return err
+	}
+	s.tree.update(k, old, &e)
+	return nil
+}
+
+// remove removes the element old at the store key k, and keeps the hash
+// tree up to date. The caller must hold s.Mu.
+func (s *Slot) remove(k string, old Element) error {
+	if err := s.Store.Delete(k); err != nil {
+		return err
+	}
+	s.tree.update(k, &old, nil)
+	return nil
+}
+
+// Tree returns the hashes of the given nodes in the hash tree of the slot
+// identified by slotID. The invalid nodes have zero hashes.
+func (s *Server) Tree(slotID int, nodes []int) []uint64 {
+	slot := s.Slot(slotID)
+
+	slot.Mu.RLock()
+	defer slot.Mu.RUnlock()
+
+	hashes := make([]uint64, len(nodes))
+	for i, n := range nodes {
+		if n >= 1 && n < len(slot.tree.nodes) {
+			hashes[i] = slot.tree.nodes[n]
+		}
+	}
+	return hashes
+}
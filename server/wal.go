@@ -0,0 +1,397 @@
+package server
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/RussellLuo/goku/internal/worker"
+)
+
+// SyncPolicy decides when the write-ahead log is flushed to the disk.
+type SyncPolicy int
+
+const (
+	// SyncAlways flushes the log after every write, which is the safest.
+	SyncAlways SyncPolicy = iota
+	// SyncEverySecond flushes the log every second, at the risk of losing
+	// the writes of the last second.
+	SyncEverySecond
+	// SyncNever leaves the flushing to the operating system.
+	SyncNever
+)
+
+func (p SyncPolicy) String() string {
+	switch p {
+	case SyncAlways:
+		return "always"
+	case SyncEverySecond:
+		return "everysec"
+	case SyncNever:
+		return "never"
+	default:
+		return fmt.Sprintf("policy(%d)", p)
+	}
+}
+
+// ParseSyncPolicy parses a sync policy from its string representation.
+func ParseSyncPolicy(s string) (SyncPolicy, error) {
+	for _, p := range []SyncPolicy{SyncAlways, SyncEverySecond, SyncNever} {
+		if p.String() == s {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("unrecognized sync policy: %s", s)
+}
+
+const (
+	opInsert byte = iota + 1
+	opDelete
+	opPurge
+	opDrop
+)
+
+// record is an operation (e.g. an insertion or a deletion) applied to the
+// server, which is the unit of both the write-ahead log and the snapshots.
+type record struct {
+	Op        byte
+	SlotID    int
+	Key       string
+	Member    string
+	Timestamp int64
+	TTL       time.Duration
+}
+
+var errCorruptRecord = errors.New("corrupt record")
+
+// The header of an encoded record, which consists of the payload length
+// and the CRC-32 checksum of the payload.
+const recordHeaderSize = 8
+
+func encodeRecord(buf []byte, r *record) []byte {
+	buf = append(buf[:0], make([]byte, recordHeaderSize)...)
+	buf = append(buf, r.Op)
+	buf = appendUvarint(buf, uint64(r.SlotID))
+	buf = appendString(buf, r.Key)
+	buf = appendString(buf, r.Member)
+	buf = appendVarint(buf, r.Timestamp)
+	buf = appendVarint(buf, int64(r.TTL))
+
+	payload := buf[recordHeaderSize:]
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(buf[4:8], crc32.ChecksumIEEE(payload))
+	return buf
+}
+
+func appendUvarint(buf []byte, x uint64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], x)
+	return append(buf, b[:n]...)
+}
+
+func appendVarint(buf []byte, x int64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(b[:], x)
+	return append(buf, b[:n]...)
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = appendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// readRecord reads a record from r. It returns io.EOF if there are no more
+// records, or errCorruptRecord if the record is torn or damaged.
+func readRecord(r *bufio.Reader) (*record, error) {
+	var header [recordHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, errCorruptRecord
+		}
+		return nil, err
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint32(header[0:4]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, errCorruptRecord
+	}
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, errCorruptRecord
+	}
+
+	return decodeRecord(payload)
+}
+
+func decodeRecord(payload []byte) (rec *record, err error) {
+	d := decoder{buf: payload}
+	rec = &record{
+		Op:        d.byte(),
+		SlotID:    int(d.uvarint()),
+		Key:       d.string(),
+		Member:    d.string(),
+		Timestamp: d.varint(),
+		TTL:       time.Duration(d.varint()),
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return rec, nil
+}
+
+// decoder decodes the fields of a record payload, and remembers the first
+// error it encounters.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) byte() byte {
+	if d.err != nil || len(d.buf) < 1 {
+		d.err = errCorruptRecord
+		return 0
+	}
+	b := d.buf[0]
+	d.buf = d.buf[1:]
+	return b
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	x, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		d.err = errCorruptRecord
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return x
+}
+
+func (d *decoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	x, n := binary.Varint(d.buf)
+	if n <= 0 {
+		d.err = errCorruptRecord
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return x
+}
+
+func (d *decoder) string() string {
+	n := d.uvarint()
+	if d.err != nil || uint64(len(d.buf)) < n {
+		d.err = errCorruptRecord
+		return ""
+	}
+	s := string(d.buf[:n])
+	d.buf = d.buf[n:]
+	return s
+}
+
+const (
+	walPrefix = "wal-"
+	walSuffix = ".log"
+)
+
+// wal is an append-only write-ahead log, which is split into segments.
+// A new segment is started whenever the log is rotated, and the old
+// segments can be removed once all the slots have been snapshotted.
+type wal struct {
+	dir    string
+	policy SyncPolicy
+	syncer *worker.Ticker
+
+	mu  sync.Mutex
+	seq uint64
+	f   *os.File
+	w   *bufio.Writer
+	buf []byte
+}
+
+// openWAL opens the write-ahead log in dir, by starting a new segment
+// after the existing ones.
+func openWAL(dir string, policy SyncPolicy) (*wal, error) {
+	segments, err := walSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var seq uint64
+	if n := len(segments); n > 0 {
+		seq = segments[n-1].seq
+	}
+
+	l := &wal{dir: dir, policy: policy, seq: seq}
+	if err := l.startSegment(); err != nil {
+		return nil, err
+	}
+
+	if policy == SyncEverySecond {
+		l.syncer = worker.NewTicker(time.Second, func() { l.Sync() })
+		l.syncer.Start()
+	}
+	return l, nil
+}
+
+// startSegment starts a new segment. The caller must hold l.mu.
+func (l *wal) startSegment() error {
+	l.seq++
+	f, err := os.OpenFile(segmentPath(l.dir, l.seq), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	l.f = f
+	l.w = bufio.NewWriter(f)
+	return nil
+}
+
+// Append appends rec to the log.
+func (l *wal) Append(rec *record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.buf = encodeRecord(l.buf, rec)
+	if _, err := l.w.Write(l.buf); err != nil {
+		return err
+	}
+
+	switch l.policy {
+	case SyncAlways:
+		return l.sync()
+	case SyncNever:
+		return l.w.Flush()
+	}
+	return nil
+}
+
+// Sync flushes the log to the disk.
+func (l *wal) Sync() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.sync()
+}
+
+func (l *wal) sync() error {
+	if err := l.w.Flush(); err != nil {
+		return err
+	}
+	return l.f.Sync()
+}
+
+// Rotate closes the current segment and starts a new one. It returns the
+// sequence number of the last closed segment.
+func (l *wal) Rotate() (uint64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.sync(); err != nil {
+		return 0, err
+	}
+	if err := l.f.Close(); err != nil {
+		return 0, err
+	}
+
+	seq := l.seq
+	return seq, l.startSegment()
+}
+
+// Truncate removes all the segments up to and including seq.
+func (l *wal) Truncate(seq uint64) error {
+	segments, err := walSegments(l.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range segments {
+		if s.seq > seq {
+			break
+		}
+		if err := os.Remove(s.path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close flushes and closes the log.
+func (l *wal) Close() error {
+	if l.syncer != nil {
+		l.syncer.Stop()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.sync(); err != nil {
+		return err
+	}
+	return l.f.Close()
+}
+
+type segment struct {
+	seq  uint64
+	path string
+}
+
+func segmentPath(dir string, seq uint64) string {
+	return filepath.Join(dir, fmt.Sprintf("%s%016d%s", walPrefix, seq, walSuffix))
+}
+
+// walSegments returns the existing segments in dir, ordered by their
+// sequence numbers.
+func walSegments(dir string) ([]segment, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, walPrefix+"*"+walSuffix))
+	if err != nil {
+		return nil, err
+	}
+
+	var segments []segment
+	for _, p := range paths {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(p), walPrefix), walSuffix)
+		var seq uint64
+		if _, err := fmt.Sscanf(name, "%d", &seq); err != nil {
+			continue
+		}
+		segments = append(segments, segment{seq: seq, path: p})
+	}
+
+	sort.Slice(segments, func(i, j int) bool { return segments[i].seq < segments[j].seq })
+	return segments, nil
+}
+
+// replay applies all the records read from r by calling fn. A torn or
+// damaged record, which is typically caused by a crash in the middle of
+// a write, ends the replay.
+func replay(name string, r *bufio.Reader, fn func(*record) error) error {
+	for {
+		rec, err := readRecord(r)
+		switch err {
+		case nil:
+		case io.EOF:
+			return nil
+		case errCorruptRecord:
+			log.Printf("ignoring the corrupt tail of %s", name)
+			return nil
+		default:
+			return err
+		}
+
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+}
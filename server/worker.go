@@ -0,0 +1,7 @@
+package server
+
+// Worker is a background task of the server.
+type Worker interface {
+	Start()
+	Stop()
+}